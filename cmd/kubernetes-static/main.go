@@ -117,6 +117,7 @@ func main() {
 			component.Queries,
 			logger,
 			controlPlaneComponentPods[component.Name],
+			component.GroupsByLabel,
 		)
 		jobs = append(
 			jobs,
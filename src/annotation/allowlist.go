@@ -0,0 +1,155 @@
+package annotation
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+)
+
+// RawMetricName is the raw metric of the kubelet groups holding the
+// annotations of the objects, as a map.
+const RawMetricName = "annotations"
+
+// ksmLabelPrefix prefixes the annotations in the labels of the KSM
+// kube_<object>_annotations metrics.
+const ksmLabelPrefix = "annotation_"
+
+// invalidLabelChars matches the characters that KSM replaces by an
+// underscore when converting annotation keys into Prometheus labels.
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Allowlist selects the annotations reported as annotation.* attributes, by
+// exact key or prefix. The zero value allows none. Values longer than
+// MaxLength characters are truncated, unless MaxLength is zero.
+type Allowlist struct {
+	Keys      []string
+	Prefixes  []string
+	MaxLength int
+}
+
+// IsSet returns whether any annotation is allowed.
+func (a Allowlist) IsSet() bool {
+	return len(a.Keys) > 0 || len(a.Prefixes) > 0
+}
+
+// Allowed returns whether the annotation with the given key is reported.
+func (a Allowlist) Allowed(key string) bool {
+	for _, k := range a.Keys {
+		if key == k {
+			return true
+		}
+	}
+	for _, p := range a.Prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedLabel returns whether the annotation with the given key, as
+// sanitized by KSM, is reported.
+func (a Allowlist) allowedLabel(key string) bool {
+	for _, k := range a.Keys {
+		if key == sanitize(k) {
+			return true
+		}
+	}
+	for _, p := range a.Prefixes {
+		if strings.HasPrefix(key, sanitize(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitize(key string) string {
+	return invalidLabelChars.ReplaceAllString(key, "_")
+}
+
+// AttributeName returns the name of the attribute reporting the annotation
+// with the given key. The key is sanitized like KSM does, so the annotations
+// read from the kubelet and from KSM have the same attribute, e.g.
+// annotation.example_com_owner for example.com/owner.
+func AttributeName(key string) string {
+	return "annotation." + sanitize(key)
+}
+
+// Truncate truncates the value to MaxLength characters.
+func (a Allowlist) Truncate(value string) string {
+	if a.MaxLength <= 0 || utf8.RuneCountInString(value) <= a.MaxLength {
+		return value
+	}
+	return string([]rune(value)[:a.MaxLength])
+}
+
+// FilterGroups returns the given groups with only the allowed annotations,
+// truncated, in the annotations raw metric of the kubelet groups and in the
+// labels of the kube_<object>_annotations metrics of the KSM groups. The
+// given groups are not modified, as they may be shared with other jobs.
+func (a Allowlist) FilterGroups(groups definition.RawGroups) definition.RawGroups {
+	filtered := make(definition.RawGroups, len(groups))
+	for groupLabel, entities := range groups {
+		filtered[groupLabel] = make(map[string]definition.RawMetrics, len(entities))
+		for rawEntityID, metrics := range entities {
+			filtered[groupLabel][rawEntityID] = a.filterMetrics(metrics)
+		}
+	}
+	return filtered
+}
+
+func (a Allowlist) filterMetrics(metrics definition.RawMetrics) definition.RawMetrics {
+	var copied definition.RawMetrics
+	set := func(name string, value definition.RawValue) {
+		if copied == nil {
+			copied = make(definition.RawMetrics, len(metrics))
+			for k, v := range metrics {
+				copied[k] = v
+			}
+		}
+		if value == nil {
+			delete(copied, name)
+			return
+		}
+		copied[name] = value
+	}
+
+	for name, value := range metrics {
+		if annotations, ok := value.(map[string]string); ok && name == RawMetricName {
+			allowed := make(map[string]string)
+			for k, v := range annotations {
+				if a.Allowed(k) {
+					allowed[k] = a.Truncate(v)
+				}
+			}
+			if len(allowed) == 0 {
+				set(name, nil)
+				continue
+			}
+			set(name, allowed)
+			continue
+		}
+
+		if m, ok := value.(prometheus.Metric); ok && strings.HasSuffix(name, "_annotations") {
+			labels := make(prometheus.Labels, len(m.Labels))
+			for k, v := range m.Labels {
+				if !strings.HasPrefix(k, ksmLabelPrefix) {
+					labels[k] = v
+					continue
+				}
+				if a.allowedLabel(strings.TrimPrefix(k, ksmLabelPrefix)) {
+					labels[k] = a.Truncate(v)
+				}
+			}
+			set(name, prometheus.Metric{Labels: labels, Value: m.Value})
+		}
+	}
+
+	if copied == nil {
+		return metrics
+	}
+	return copied
+}
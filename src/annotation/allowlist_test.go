@@ -0,0 +1,137 @@
+package annotation
+
+import (
+	"testing"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowlist_Allowed(t *testing.T) {
+	a := Allowlist{Keys: []string{"owner"}, Prefixes: []string{"example.com/"}}
+
+	assert.True(t, a.Allowed("owner"))
+	assert.True(t, a.Allowed("example.com/cost-center"))
+	assert.False(t, a.Allowed("owner-email"))
+	assert.False(t, a.Allowed("kubernetes.io/config.seen"))
+	assert.False(t, Allowlist{}.Allowed("owner"))
+}
+
+func TestAllowlist_Truncate(t *testing.T) {
+	assert.Equal(t, "plat", Allowlist{MaxLength: 4}.Truncate("platform"))
+	assert.Equal(t, "ñand", Allowlist{MaxLength: 4}.Truncate("ñandú"))
+	assert.Equal(t, "platform", Allowlist{MaxLength: 8}.Truncate("platform"))
+	assert.Equal(t, "platform", Allowlist{}.Truncate("platform"))
+}
+
+func TestAllowlist_FilterGroups(t *testing.T) {
+	groups := definition.RawGroups{
+		"pod": {
+			"default_nginx": {
+				"podName": "nginx",
+				"annotations": map[string]string{
+					"example.com/cost-center":   "cc-1234567",
+					"kubernetes.io/config.seen": "2019-03-13T08:03:01.880958599Z",
+				},
+			},
+			"default_redis": {
+				"podName": "redis",
+				"annotations": map[string]string{
+					"kubernetes.io/config.seen": "2019-03-13T08:03:01.880958599Z",
+				},
+			},
+		},
+		"deployment": {
+			"default_nginx": {
+				"kube_deployment_annotations": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{
+						"namespace":                          "default",
+						"deployment":                         "nginx",
+						"annotation_example_com_cost_center": "cc-1234567",
+						"annotation_deployment_kubernetes_io_revision": "3",
+					},
+				},
+				"kube_deployment_labels": prometheus.Metric{
+					Value:  prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{"label_app": "nginx"},
+				},
+			},
+		},
+	}
+
+	a := Allowlist{Prefixes: []string{"example.com/"}, MaxLength: 5}
+	filtered := a.FilterGroups(groups)
+
+	assert.Equal(t, definition.RawGroups{
+		"pod": {
+			"default_nginx": {
+				"podName":     "nginx",
+				"annotations": map[string]string{"example.com/cost-center": "cc-12"},
+			},
+			"default_redis": {
+				"podName": "redis",
+			},
+		},
+		"deployment": {
+			"default_nginx": {
+				"kube_deployment_annotations": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{
+						"namespace":                          "default",
+						"deployment":                         "nginx",
+						"annotation_example_com_cost_center": "cc-12",
+					},
+				},
+				"kube_deployment_labels": prometheus.Metric{
+					Value:  prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{"label_app": "nginx"},
+				},
+			},
+		},
+	}, filtered)
+
+	// The given groups are left untouched.
+	assert.Len(t, groups["pod"]["default_nginx"]["annotations"], 2)
+}
+
+func TestAllowlist_FilterGroupsEmpty(t *testing.T) {
+	groups := definition.RawGroups{
+		"pod": {
+			"default_nginx": {
+				"podName": "nginx",
+				"annotations": map[string]string{
+					"kubernetes.io/config.seen":                        "2019-03-13T08:03:01.880958599Z",
+					"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"Pod"}`,
+				},
+			},
+		},
+		"deployment": {
+			"default_nginx": {
+				"kube_deployment_annotations": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{
+						"deployment":                         "nginx",
+						"annotation_example_com_cost_center": "cc-1234567",
+					},
+				},
+			},
+		},
+	}
+
+	// The zero value, as configured by default, allows no annotation.
+	assert.Equal(t, definition.RawGroups{
+		"pod": {
+			"default_nginx": {"podName": "nginx"},
+		},
+		"deployment": {
+			"default_nginx": {
+				"kube_deployment_annotations": prometheus.Metric{
+					Value:  prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{"deployment": "nginx"},
+				},
+			},
+		},
+	}, Allowlist{MaxLength: 256}.FilterGroups(groups))
+}
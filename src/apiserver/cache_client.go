@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"time"
 
@@ -119,3 +120,36 @@ func (f *fileCacheClient) GetServerVersion() (*version.Info, error) {
 
 	return k8sVersion, f.store(k8sVersion, key)
 }
+
+func (f *fileCacheClient) GetNamespaceNames(labelSelector string) (NamespaceNames, error) {
+	// Label selectors may contain slashes, which are not valid in the file name of the cache.
+	key := url.QueryEscape(labelSelector)
+	names := NamespaceNames{}
+
+	if f.load(&names, key) {
+		return names, nil
+	}
+
+	names, err := f.client.GetNamespaceNames(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, f.store(&names, key)
+}
+
+func (f *fileCacheClient) GetOwnerReference(kind, namespace, name string) (*OwnerReference, error) {
+	key := fmt.Sprintf("%s_%s_%s", kind, namespace, name)
+	owner := &OwnerReference{}
+
+	if f.load(owner, key) {
+		return owner, nil
+	}
+
+	owner, err := f.client.GetOwnerReference(kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return owner, f.store(owner, key)
+}
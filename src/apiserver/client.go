@@ -1,9 +1,12 @@
 package apiserver
 
 import (
+	"fmt"
+
 	"github.com/newrelic/nri-kubernetes/src/client"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 )
 
@@ -11,6 +14,8 @@ import (
 type Client interface {
 	GetNodeInfo(nodeName string) (*NodeInfo, error)
 	GetServerVersion() (*version.Info, error)
+	GetNamespaceNames(labelSelector string) (NamespaceNames, error)
+	GetOwnerReference(kind, namespace, name string) (*OwnerReference, error)
 }
 
 // NewClient creates a new API Server client
@@ -29,6 +34,45 @@ func (c clientImpl) GetServerVersion() (*version.Info, error) {
 	return c.k8sClient.ServerVersion()
 }
 
+// GetNamespaceNames returns the names of the namespaces matching the given label selector.
+func (c clientImpl) GetNamespaceNames(labelSelector string) (NamespaceNames, error) {
+	namespaces, err := c.k8sClient.FindNamespacesByLabel(labelSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list the namespaces for labelSelector='%s'", labelSelector)
+	}
+
+	names := make(NamespaceNames, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		names = append(names, ns.Name)
+	}
+	return names, nil
+}
+
+// GetOwnerReference returns the controller of the object of the given kind,
+// namespace and name. Only ReplicaSets and Jobs are supported.
+func (c clientImpl) GetOwnerReference(kind, namespace, name string) (*OwnerReference, error) {
+	var object metav1.Object
+	var err error
+	switch kind {
+	case "ReplicaSet":
+		object, err = c.k8sClient.FindReplicaSet(name, namespace)
+	case "Job":
+		object, err = c.k8sClient.FindJob(name, namespace)
+	default:
+		return nil, fmt.Errorf("owner lookup not supported for kind %s", kind)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find %s %s/%s", kind, namespace, name)
+	}
+
+	owner := &OwnerReference{}
+	if ref := metav1.GetControllerOf(object); ref != nil {
+		owner.Kind = ref.Kind
+		owner.Name = ref.Name
+	}
+	return owner, nil
+}
+
 // GetNodeInfo queries the API server for information about the given node
 func (c clientImpl) GetNodeInfo(nodeName string) (*NodeInfo, error) {
 
@@ -46,6 +90,16 @@ func (c clientImpl) GetNodeInfo(nodeName string) (*NodeInfo, error) {
 	}, nil
 }
 
+// NamespaceNames are the names of a list of namespaces
+type NamespaceNames []string
+
+// OwnerReference is the kind and name of the controller of an object. They are
+// empty when the object has no controller.
+type OwnerReference struct {
+	Kind string
+	Name string
+}
+
 // NodeInfo contains information about a specific node
 type NodeInfo struct {
 	NodeName    string
@@ -6,11 +6,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/newrelic/nri-kubernetes/src/client"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func getTempDir(t *testing.T) (string, func()) {
@@ -140,6 +144,83 @@ func TestFileCacheReadCacheAndExpiry(t *testing.T) {
 
 }
 
+// TestFileCacheNamespaceNames tests whether the fileCache caches the namespaces of label selectors with slashes
+func TestFileCacheNamespaceNames(t *testing.T) {
+	dir, cleanup := getTempDir(t)
+	defer cleanup()
+
+	const selector = "app.kubernetes.io/part-of=payments"
+	client := TestAPIServer{Namespaces: map[string]NamespaceNames{
+		selector: {"payments", "payments-ci-42"},
+	}}
+	timeProvider := &manualTimeProvider{time.Now()}
+	cacheWrapper := NewFileCacheClientWrapper(client, dir, time.Hour, WithTimeProvider(timeProvider))
+
+	names, err := cacheWrapper.GetNamespaceNames(selector)
+	require.NoError(t, err)
+	assert.Equal(t, NamespaceNames{"payments", "payments-ci-42"}, names)
+
+	client.Namespaces[selector] = NamespaceNames{"payments"}
+
+	names, err = cacheWrapper.GetNamespaceNames(selector)
+	require.NoError(t, err)
+	assert.Equal(t, NamespaceNames{"payments", "payments-ci-42"}, names)
+
+	timeProvider.time = time.Now().Add(time.Hour * 2)
+
+	names, err = cacheWrapper.GetNamespaceNames(selector)
+	require.NoError(t, err)
+	assert.Equal(t, NamespaceNames{"payments"}, names)
+}
+
+// TestFileCacheOwnerReference tests whether the fileCache caches the owners of the objects
+func TestFileCacheOwnerReference(t *testing.T) {
+	dir, cleanup := getTempDir(t)
+	defer cleanup()
+
+	client := TestAPIServer{Owners: map[string]*OwnerReference{
+		"Job/default/backup-1600000000": {Kind: "CronJob", Name: "backup"},
+	}}
+	cacheWrapper := NewFileCacheClientWrapper(client, dir, time.Hour)
+
+	owner, err := cacheWrapper.GetOwnerReference("Job", "default", "backup-1600000000")
+	require.NoError(t, err)
+	assert.Equal(t, &OwnerReference{Kind: "CronJob", Name: "backup"}, owner)
+
+	delete(client.Owners, "Job/default/backup-1600000000")
+
+	owner, err = cacheWrapper.GetOwnerReference("Job", "default", "backup-1600000000")
+	require.NoError(t, err)
+	assert.Equal(t, &OwnerReference{Kind: "CronJob", Name: "backup"}, owner)
+
+	_, err = cacheWrapper.GetOwnerReference("Job", "default", "backup-1600003600")
+	assert.Error(t, err)
+}
+
+func TestGetOwnerReference(t *testing.T) {
+	isController := true
+	k8s := &client.MockedKubernetes{}
+	k8s.On("FindReplicaSet", "nginx-5c689d88bb", "default").Return(&appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+			{Kind: "Rollout", Name: "nginx", Controller: &isController},
+		}},
+	}, nil)
+	k8s.On("FindJob", "backup-1600000000", "default").Return(&batchv1.Job{}, nil)
+
+	c := NewClient(k8s)
+
+	owner, err := c.GetOwnerReference("ReplicaSet", "default", "nginx-5c689d88bb")
+	require.NoError(t, err)
+	assert.Equal(t, &OwnerReference{Kind: "Rollout", Name: "nginx"}, owner)
+
+	owner, err = c.GetOwnerReference("Job", "default", "backup-1600000000")
+	require.NoError(t, err)
+	assert.Equal(t, &OwnerReference{}, owner)
+
+	_, err = c.GetOwnerReference("StatefulSet", "default", "redis")
+	assert.Error(t, err)
+}
+
 type manualTimeProvider struct {
 	time time.Time
 }
@@ -8,7 +8,10 @@ import (
 
 // TestAPIServer is for testing purposes. It implements the apiserver.Client interface with an in-memory list of objects
 type TestAPIServer struct {
-	Mem map[string]*NodeInfo
+	Mem        map[string]*NodeInfo
+	Namespaces map[string]NamespaceNames
+	// Owners are indexed by kind, namespace and name, e.g. ReplicaSet/default/nginx-5c689d88bb.
+	Owners map[string]*OwnerReference
 }
 
 func (t TestAPIServer) GetNodeInfo(nodeName string) (*NodeInfo, error) {
@@ -23,3 +26,22 @@ func (t TestAPIServer) GetNodeInfo(nodeName string) (*NodeInfo, error) {
 func (t TestAPIServer) GetServerVersion() (*version.Info, error) {
 	return &version.Info{}, nil
 }
+
+func (t TestAPIServer) GetNamespaceNames(labelSelector string) (NamespaceNames, error) {
+	names, ok := t.Namespaces[labelSelector]
+	if !ok {
+		return nil, fmt.Errorf("could not find namespaces for: %s", labelSelector)
+	}
+
+	return names, nil
+}
+
+func (t TestAPIServer) GetOwnerReference(kind, namespace, name string) (*OwnerReference, error) {
+	key := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+	owner, ok := t.Owners[key]
+	if !ok {
+		return nil, fmt.Errorf("could not find owner of: %s", key)
+	}
+
+	return owner, nil
+}
@@ -0,0 +1,232 @@
+package capacity
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/data"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
+)
+
+// GroupLabel is the label of the raw group returned by the grouper, reported
+// as K8sClusterCapacitySample.
+const GroupLabel = "cluster-capacity"
+
+// clusterRawEntityID is the raw entity ID of the capacity of the whole
+// cluster. The ones of the node groups are the values of the node label.
+const clusterRawEntityID = "cluster"
+
+// noneNodeGroup is the node group of the nodes without the node label.
+const noneNodeGroup = "<none>"
+
+type grouper struct {
+	k8sClient   client.Kubernetes
+	logger      *logrus.Logger
+	clusterName string
+	nodeLabel   string
+	filter      *namespace.Filter
+}
+
+// NewGrouper returns a grouper of the capacity of the nodes of the cluster,
+// listed in the API server, and of the resources requested by the pods
+// scheduled on them. The capacity of the whole cluster is always grouped,
+// and also the one of each value of nodeLabel if it is set, e.g. the node
+// pool or the instance type. The pods of the namespaces not matched by the
+// filter are left out, unless it is nil.
+func NewGrouper(
+	k8sClient client.Kubernetes,
+	logger *logrus.Logger,
+	clusterName string,
+	nodeLabel string,
+	filter *namespace.Filter,
+) data.Grouper {
+	return &grouper{
+		k8sClient:   k8sClient,
+		logger:      logger,
+		clusterName: clusterName,
+		nodeLabel:   nodeLabel,
+		filter:      filter,
+	}
+}
+
+// resources are the allocatable or requested resources of a node, with the
+// CPU in millicores.
+type resources struct {
+	milliCPU    int64
+	memoryBytes int64
+	pods        int64
+}
+
+func (r *resources) add(o resources) {
+	r.milliCPU += o.milliCPU
+	r.memoryBytes += o.memoryBytes
+	r.pods += o.pods
+}
+
+// headroom returns the resources left to schedule pods, which are never
+// negative.
+func (r resources) headroom(requested resources) resources {
+	return resources{
+		milliCPU:    nonNegative(r.milliCPU - requested.milliCPU),
+		memoryBytes: nonNegative(r.memoryBytes - requested.memoryBytes),
+		pods:        nonNegative(r.pods - requested.pods),
+	}
+}
+
+func nonNegative(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// summary is the capacity of a group of nodes.
+type summary struct {
+	nodes, notReady, cordoned        int
+	allocatable, requested, headroom resources
+}
+
+func (r *grouper) Group(definition.SpecGroups) (definition.RawGroups, *data.ErrorGroup) {
+	nodes, err := r.k8sClient.ListNodes()
+	if err != nil {
+		return nil, &data.ErrorGroup{
+			Recoverable: false,
+			Errors:      []error{fmt.Errorf("error listing the nodes: %s", err)},
+		}
+	}
+
+	pods, err := r.k8sClient.ListScheduledPods()
+	if err != nil {
+		return nil, &data.ErrorGroup{
+			Recoverable: false,
+			Errors:      []error{fmt.Errorf("error listing the pods: %s", err)},
+		}
+	}
+
+	requested := make(map[string]resources)
+	for _, pod := range pods.Items {
+		if r.filter != nil && !r.filter.Match(pod.Namespace) {
+			continue
+		}
+		// The field selector of the list already leaves out these pods, except in tests.
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		nodeRequested := requested[pod.Spec.NodeName]
+		nodeRequested.add(podRequests(pod))
+		requested[pod.Spec.NodeName] = nodeRequested
+	}
+
+	summaries := map[string]*summary{clusterRawEntityID: {}}
+	for _, node := range nodes.Items {
+		allocatable := resources{
+			milliCPU:    node.Status.Allocatable.Cpu().MilliValue(),
+			memoryBytes: node.Status.Allocatable.Memory().Value(),
+			pods:        node.Status.Allocatable.Pods().Value(),
+		}
+		schedulable := !node.Spec.Unschedulable && isReady(node)
+
+		rawEntityIDs := []string{clusterRawEntityID}
+		if r.nodeLabel != "" {
+			nodeGroup, ok := node.Labels[r.nodeLabel]
+			if !ok || nodeGroup == "" {
+				nodeGroup = noneNodeGroup
+			}
+			rawEntityIDs = append(rawEntityIDs, nodeGroup)
+		}
+
+		for _, rawEntityID := range rawEntityIDs {
+			s, ok := summaries[rawEntityID]
+			if !ok {
+				s = &summary{}
+				summaries[rawEntityID] = s
+			}
+
+			s.nodes++
+			if !isReady(node) {
+				s.notReady++
+			}
+			if node.Spec.Unschedulable {
+				s.cordoned++
+			}
+			s.allocatable.add(allocatable)
+			s.requested.add(requested[node.Name])
+			if schedulable {
+				s.headroom.add(allocatable.headroom(requested[node.Name]))
+			}
+		}
+	}
+
+	entities := make(map[string]definition.RawMetrics, len(summaries))
+	for rawEntityID, s := range summaries {
+		metrics := definition.RawMetrics{
+			"clusterName":            r.clusterName,
+			"nodeCount":              s.nodes,
+			"notReadyNodeCount":      s.notReady,
+			"cordonedNodeCount":      s.cordoned,
+			"allocatableMilliCpu":    s.allocatable.milliCPU,
+			"allocatableMemoryBytes": s.allocatable.memoryBytes,
+			"allocatablePods":        s.allocatable.pods,
+			"requestedMilliCpu":      s.requested.milliCPU,
+			"requestedMemoryBytes":   s.requested.memoryBytes,
+			"requestedPods":          s.requested.pods,
+			"schedulableMilliCpu":    s.headroom.milliCPU,
+			"schedulableMemoryBytes": s.headroom.memoryBytes,
+			"schedulablePods":        s.headroom.pods,
+		}
+		if rawEntityID != clusterRawEntityID {
+			metrics["nodeGroupLabel"] = r.nodeLabel
+			metrics["nodeGroup"] = rawEntityID
+		}
+		entities[rawEntityID] = metrics
+	}
+	r.logger.Debugf("Grouped the capacity of %d nodes in %d node groups", len(nodes.Items), len(entities)-1)
+
+	return definition.RawGroups{GroupLabel: entities}, nil
+}
+
+// podRequests returns the resources requested by a pod, as computed by the
+// scheduler: the init containers run one after the other before the
+// containers, so the pod requests the max of the sum of the requests of its
+// containers and of the requests of each init container.
+func podRequests(pod v1.Pod) resources {
+	var containers resources
+	for _, c := range pod.Spec.Containers {
+		containers.add(resources{
+			milliCPU:    c.Resources.Requests.Cpu().MilliValue(),
+			memoryBytes: c.Resources.Requests.Memory().Value(),
+		})
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		if cpu := c.Resources.Requests.Cpu().MilliValue(); cpu > containers.milliCPU {
+			containers.milliCPU = cpu
+		}
+		if memory := c.Resources.Requests.Memory().Value(); memory > containers.memoryBytes {
+			containers.memoryBytes = memory
+		}
+	}
+
+	containers.pods = 1
+	return containers
+}
+
+func isReady(node v1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// EntityTypeGenerator generates the type of the entity of the capacity
+// samples, which is the one of the cluster, so they are reported along the
+// K8sClusterSample.
+func EntityTypeGenerator(_ string, _ string, _ definition.RawGroups, _ string) (string, error) {
+	return "k8s:cluster", nil
+}
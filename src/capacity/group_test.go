@@ -0,0 +1,137 @@
+package capacity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
+)
+
+func node(name, pool string, ready, unschedulable bool) v1.Node {
+	status := v1.ConditionTrue
+	if !ready {
+		status = v1.ConditionFalse
+	}
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+		Spec:       v1.NodeSpec{Unschedulable: unschedulable},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func container(cpu, memory string) v1.Container {
+	return v1.Container{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}}}
+}
+
+func pod(ns, nodeName string, initContainers []v1.Container, containers ...v1.Container) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns},
+		Spec:       v1.PodSpec{NodeName: nodeName, InitContainers: initContainers, Containers: containers},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func newMockedClient() *client.MockedKubernetes {
+	c := new(client.MockedKubernetes)
+	c.On("ListNodes").Return(&v1.NodeList{Items: []v1.Node{
+		node("worker-1", "default", true, false),
+		node("worker-2", "default", true, true),
+		node("worker-3", "gpu", false, false),
+	}}, nil)
+	c.On("ListScheduledPods").Return(&v1.PodList{Items: []v1.Pod{
+		pod("default", "worker-1", nil, container("500m", "1Gi"), container("250m", "512Mi")),
+		// The init container requests more memory than the containers.
+		pod("default", "worker-1", []v1.Container{container("100m", "2Gi")}, container("250m", "512Mi")),
+		pod("ci-42", "worker-1", nil, container("1", "1Gi")),
+		pod("default", "worker-3", nil, container("500m", "1Gi")),
+	}}, nil)
+	return c
+}
+
+func TestGroup(t *testing.T) {
+	groups, errs := NewGrouper(newMockedClient(), logrus.New(), "test-cluster", "", nil).Group(nil)
+	assert.Nil(t, errs)
+
+	const gi = 1024 * 1024 * 1024
+	assert.Equal(t, definition.RawGroups{
+		GroupLabel: {
+			"cluster": {
+				"clusterName":            "test-cluster",
+				"nodeCount":              3,
+				"notReadyNodeCount":      1,
+				"cordonedNodeCount":      1,
+				"allocatableMilliCpu":    int64(6000),
+				"allocatableMemoryBytes": int64(12 * gi),
+				"allocatablePods":        int64(330),
+				"requestedMilliCpu":      int64(2500),
+				"requestedMemoryBytes":   int64(5.5 * gi),
+				"requestedPods":          int64(4),
+				// Only worker-1 is ready and schedulable.
+				"schedulableMilliCpu":    int64(0),
+				"schedulableMemoryBytes": int64(0),
+				"schedulablePods":        int64(107),
+			},
+		},
+	}, groups)
+}
+
+func TestGroup_NodeLabel(t *testing.T) {
+	groups, errs := NewGrouper(newMockedClient(), logrus.New(), "test-cluster", "pool", nil).Group(nil)
+	assert.Nil(t, errs)
+
+	assert.Len(t, groups[GroupLabel], 3)
+	assert.Equal(t, 3, groups[GroupLabel]["cluster"]["nodeCount"])
+	assert.NotContains(t, groups[GroupLabel]["cluster"], "nodeGroup")
+
+	gpu := groups[GroupLabel]["gpu"]
+	assert.Equal(t, "pool", gpu["nodeGroupLabel"])
+	assert.Equal(t, "gpu", gpu["nodeGroup"])
+	assert.Equal(t, 1, gpu["nodeCount"])
+	assert.Equal(t, 1, gpu["notReadyNodeCount"])
+	assert.Equal(t, int64(500), gpu["requestedMilliCpu"])
+	assert.Equal(t, int64(0), gpu["schedulablePods"])
+
+	assert.Equal(t, 2, groups[GroupLabel]["default"]["nodeCount"])
+	assert.Equal(t, 1, groups[GroupLabel]["default"]["cordonedNodeCount"])
+}
+
+func TestGroup_NamespaceFilter(t *testing.T) {
+	filter, err := namespace.NewFilter(namespace.Config{ExcludeRegex: "^ci-"}, nil)
+	assert.NoError(t, err)
+
+	groups, errs := NewGrouper(newMockedClient(), logrus.New(), "test-cluster", "", filter).Group(nil)
+	assert.Nil(t, errs)
+
+	cluster := groups[GroupLabel]["cluster"]
+	assert.Equal(t, int64(1500), cluster["requestedMilliCpu"])
+	assert.Equal(t, int64(3), cluster["requestedPods"])
+	assert.Equal(t, int64(1000), cluster["schedulableMilliCpu"])
+}
+
+func TestGroup_Error(t *testing.T) {
+	c := new(client.MockedKubernetes)
+	c.On("ListNodes").Return(&v1.NodeList{}, errors.New("forbidden"))
+
+	groups, errs := NewGrouper(c, logrus.New(), "test-cluster", "", nil).Group(nil)
+	assert.Nil(t, groups)
+	assert.False(t, errs.Recoverable)
+	assert.Len(t, errs.Errors, 1)
+}
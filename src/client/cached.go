@@ -114,6 +114,14 @@ func (c *cacheAwareClient) NodeIP() string {
 	return c.client.NodeIP()
 }
 
+// Certificates returns the certificates recorded by the wrapped client, if it records them.
+func (c *cacheAwareClient) Certificates() *CertificateRecorder {
+	if reporter, ok := c.client.(CertificateReporter); ok {
+		return reporter.Certificates()
+	}
+	return nil
+}
+
 // WrappedClient is only aimed for testing. It allows extracting the wrapped client of a given cacheAwareClient.
 func WrappedClient(caClient HTTPClient) HTTPClient {
 	return caClient.(*cacheAwareClient).client
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+)
+
+// CertificateReporter is implemented by the HTTPClients that keep track of the
+// certificates used in the TLS connections to their endpoint.
+type CertificateReporter interface {
+	Certificates() *CertificateRecorder
+}
+
+// CertificateInfo holds the expiration date and issuer of a certificate.
+type CertificateInfo struct {
+	NotAfter time.Time
+	Issuer   string
+}
+
+// ExpiresIn returns the time left until the certificate expires. It is
+// negative for expired certificates.
+func (c CertificateInfo) ExpiresIn(now time.Time) time.Duration {
+	return c.NotAfter.Sub(now)
+}
+
+// CertificateRecorder records the certificate presented by the server and the
+// client certificate used when querying an endpoint over TLS. Its zero value
+// is ready to use.
+type CertificateRecorder struct {
+	lock   sync.Mutex
+	peer   *CertificateInfo
+	client *CertificateInfo
+}
+
+// RecordResponse records the certificate of the peer chain which expires the
+// soonest. Responses not received over TLS are ignored.
+func (r *CertificateRecorder) RecordResponse(resp *http.Response) {
+	if resp == nil || resp.TLS == nil {
+		return
+	}
+
+	info, ok := earliestExpiration(resp.TLS.PeerCertificates)
+	if !ok {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.peer = &info
+}
+
+// RecordClientCertificate records the leaf of the given client certificate.
+func (r *CertificateRecorder) RecordClientCertificate(cert *tls.Certificate) {
+	if cert == nil || len(cert.Certificate) == 0 {
+		return
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.client = &CertificateInfo{NotAfter: leaf.NotAfter, Issuer: leaf.Issuer.String()}
+}
+
+// Peer returns the recorded peer certificate, if any.
+func (r *CertificateRecorder) Peer() (CertificateInfo, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.peer == nil {
+		return CertificateInfo{}, false
+	}
+	return *r.peer, true
+}
+
+// Client returns the recorded client certificate, if any.
+func (r *CertificateRecorder) Client() (CertificateInfo, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.client == nil {
+		return CertificateInfo{}, false
+	}
+	return *r.client, true
+}
+
+// CertificateMetrics returns the raw metrics describing the certificates
+// recorded by the given client, using the given prefix for the metric names:
+// "certExpiresInSeconds" and "certIssuer" for the peer certificate, and
+// "clientCertExpiresInSeconds" for the client certificate. Clients not
+// implementing CertificateReporter return no metrics.
+func CertificateMetrics(c HTTPClient, prefix string, now time.Time) definition.RawMetrics {
+	metrics := definition.RawMetrics{}
+
+	reporter, ok := c.(CertificateReporter)
+	if !ok || reporter.Certificates() == nil {
+		return metrics
+	}
+
+	if peer, ok := reporter.Certificates().Peer(); ok {
+		metrics[prefixedName(prefix, "certExpiresInSeconds")] = peer.ExpiresIn(now).Seconds()
+		metrics[prefixedName(prefix, "certIssuer")] = peer.Issuer
+	}
+
+	if cl, ok := reporter.Certificates().Client(); ok {
+		metrics[prefixedName(prefix, "clientCertExpiresInSeconds")] = cl.ExpiresIn(now).Seconds()
+	}
+
+	return metrics
+}
+
+func prefixedName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + strings.ToUpper(name[:1]) + name[1:]
+}
+
+func earliestExpiration(chain []*x509.Certificate) (CertificateInfo, bool) {
+	var earliest *x509.Certificate
+	for _, cert := range chain {
+		if earliest == nil || cert.NotAfter.Before(earliest.NotAfter) {
+			earliest = cert
+		}
+	}
+
+	if earliest == nil {
+		return CertificateInfo{}, false
+	}
+	return CertificateInfo{NotAfter: earliest.NotAfter, Issuer: earliest.Issuer.String()}, true
+}
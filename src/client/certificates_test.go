@@ -0,0 +1,89 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingClient struct {
+	certificates CertificateRecorder
+}
+
+func (r *recordingClient) Do(method, path string) (*http.Response, error) { return nil, nil }
+func (r *recordingClient) NodeIP() string                                 { return "1.2.3.4" }
+func (r *recordingClient) Certificates() *CertificateRecorder             { return &r.certificates }
+
+type nonRecordingClient struct{}
+
+func (nonRecordingClient) Do(method, path string) (*http.Response, error) { return nil, nil }
+func (nonRecordingClient) NodeIP() string                                 { return "1.2.3.4" }
+
+func TestCertificateRecorder_RecordResponse(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+
+	var recorder CertificateRecorder
+	recorder.RecordResponse(resp)
+
+	peer, ok := recorder.Peer()
+	require.True(t, ok)
+	assert.Equal(t, server.Certificate().NotAfter, peer.NotAfter)
+	assert.Equal(t, server.Certificate().Issuer.String(), peer.Issuer)
+
+	_, ok = recorder.Client()
+	assert.False(t, ok)
+}
+
+func TestCertificateRecorder_IgnoresPlainHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+
+	var recorder CertificateRecorder
+	recorder.RecordResponse(resp)
+	recorder.RecordResponse(nil)
+
+	_, ok := recorder.Peer()
+	assert.False(t, ok)
+}
+
+func TestCertificateMetrics(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+
+	c := &recordingClient{}
+	c.certificates.RecordResponse(resp)
+	c.certificates.RecordClientCertificate(&tls.Certificate{Certificate: [][]byte{server.Certificate().Raw}})
+
+	now := server.Certificate().NotAfter.Add(-time.Hour)
+	metrics := CertificateMetrics(c, "", now)
+	assert.Equal(t, float64(3600), metrics["certExpiresInSeconds"])
+	assert.Equal(t, server.Certificate().Issuer.String(), metrics["certIssuer"])
+	assert.Equal(t, float64(3600), metrics["clientCertExpiresInSeconds"])
+
+	prefixed := CertificateMetrics(c, "ksm", now)
+	assert.Equal(t, float64(3600), prefixed["ksmCertExpiresInSeconds"])
+	assert.Contains(t, prefixed, "ksmCertIssuer")
+}
+
+func TestCertificateMetrics_ClientNotRecordingCertificates(t *testing.T) {
+	assert.Empty(t, CertificateMetrics(nonRecordingClient{}, "", time.Now()))
+	assert.Empty(t, CertificateMetrics(&recordingClient{}, "", time.Now()))
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
@@ -22,6 +24,14 @@ import (
 type Kubernetes interface {
 	// FindNode returns a Node reference containing the pod named as the argument, if any
 	FindNode(name string) (*v1.Node, error)
+	// ListNodes returns a NodeList containing all the nodes
+	ListNodes() (*v1.NodeList, error)
+	// ListScheduledPods returns a PodList containing the pods bound to a node which are not terminated
+	ListScheduledPods() (*v1.PodList, error)
+	// ListUnscheduledPods returns a PodList containing the pending pods not bound to a node yet
+	ListUnscheduledPods() (*v1.PodList, error)
+	// ListEvents returns an EventList containing the events of all the namespaces
+	ListEvents() (*v1.EventList, error)
 	// FindPodsByLabel returns a PodList reference containing the pods matching the provided name/value label pair
 	FindPodsByLabel(name, value string) (*v1.PodList, error)
 	// FindPodByName returns a PodList reference that should contain the pod whose name matches with the name argument
@@ -33,12 +43,18 @@ type Kubernetes interface {
 	FindServicesByLabel(name, value string) (*v1.ServiceList, error)
 	// ListServices returns a ServiceList containing all the services.
 	ListServices() (*v1.ServiceList, error)
+	// FindNamespacesByLabel returns a NamespaceList containing the namespaces matching the provided label selector
+	FindNamespacesByLabel(selector string) (*v1.NamespaceList, error)
 	// Config returns a config of API client
 	Config() *rest.Config
 	// SecureHTTPClient returns http.Client configured with timeout and CA Cert
 	SecureHTTPClient(time.Duration) (*http.Client, error)
 	// FindSecret returns the secret with the given name, if any
 	FindSecret(name, namespace string) (*v1.Secret, error)
+	// FindReplicaSet returns the ReplicaSet with the given name, if any
+	FindReplicaSet(name, namespace string) (*appsv1.ReplicaSet, error)
+	// FindJob returns the Job with the given name, if any
+	FindJob(name, namespace string) (*batchv1.Job, error)
 	// ServerVersion returns the kubernetes server version.
 	ServerVersion() (*version.Info, error)
 }
@@ -60,6 +76,26 @@ func (ka *goClientImpl) FindNode(name string) (*v1.Node, error) {
 	return ka.client.CoreV1().Nodes().Get(name, metav1.GetOptions{})
 }
 
+func (ka *goClientImpl) ListNodes() (*v1.NodeList, error) {
+	return ka.client.CoreV1().Nodes().List(metav1.ListOptions{})
+}
+
+func (ka *goClientImpl) ListScheduledPods() (*v1.PodList, error) {
+	return ka.client.CoreV1().Pods("").List(metav1.ListOptions{
+		FieldSelector: "spec.nodeName!=,status.phase!=Succeeded,status.phase!=Failed",
+	})
+}
+
+func (ka *goClientImpl) ListUnscheduledPods() (*v1.PodList, error) {
+	return ka.client.CoreV1().Pods("").List(metav1.ListOptions{
+		FieldSelector: "spec.nodeName=,status.phase=Pending",
+	})
+}
+
+func (ka *goClientImpl) ListEvents() (*v1.EventList, error) {
+	return ka.client.CoreV1().Events("").List(metav1.ListOptions{})
+}
+
 func (ka *goClientImpl) FindPodsByLabel(name, value string) (*v1.PodList, error) {
 	return ka.client.CoreV1().Pods("").List(metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s", name, value),
@@ -88,6 +124,12 @@ func (ka *goClientImpl) ListServices() (*v1.ServiceList, error) {
 	return ka.client.CoreV1().Services("").List(metav1.ListOptions{})
 }
 
+func (ka *goClientImpl) FindNamespacesByLabel(selector string) (*v1.NamespaceList, error) {
+	return ka.client.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+}
+
 func (ka *goClientImpl) SecureHTTPClient(t time.Duration) (*http.Client, error) {
 	c, ok := ka.client.RESTClient().(*rest.RESTClient)
 	if !ok {
@@ -100,6 +142,14 @@ func (ka *goClientImpl) FindSecret(name, namespace string) (*v1.Secret, error) {
 	return ka.client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
 }
 
+func (ka *goClientImpl) FindReplicaSet(name, namespace string) (*appsv1.ReplicaSet, error) {
+	return ka.client.AppsV1().ReplicaSets(namespace).Get(name, metav1.GetOptions{})
+}
+
+func (ka *goClientImpl) FindJob(name, namespace string) (*batchv1.Job, error) {
+	return ka.client.BatchV1().Jobs(namespace).Get(name, metav1.GetOptions{})
+}
+
 // BasicHTTPClient returns http.Client configured with timeout
 func BasicHTTPClient(t time.Duration) *http.Client {
 	return &http.Client{
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/rest"
@@ -39,6 +41,30 @@ func (m *MockedKubernetes) FindNode(name string) (*v1.Node, error) {
 	return args.Get(0).(*v1.Node), args.Error(1)
 }
 
+// ListNodes mocks Kubernetes ListNodes
+func (m *MockedKubernetes) ListNodes() (*v1.NodeList, error) {
+	args := m.Called()
+	return args.Get(0).(*v1.NodeList), args.Error(1)
+}
+
+// ListScheduledPods mocks Kubernetes ListScheduledPods
+func (m *MockedKubernetes) ListScheduledPods() (*v1.PodList, error) {
+	args := m.Called()
+	return args.Get(0).(*v1.PodList), args.Error(1)
+}
+
+// ListUnscheduledPods mocks Kubernetes ListUnscheduledPods
+func (m *MockedKubernetes) ListUnscheduledPods() (*v1.PodList, error) {
+	args := m.Called()
+	return args.Get(0).(*v1.PodList), args.Error(1)
+}
+
+// ListEvents mocks Kubernetes ListEvents
+func (m *MockedKubernetes) ListEvents() (*v1.EventList, error) {
+	args := m.Called()
+	return args.Get(0).(*v1.EventList), args.Error(1)
+}
+
 // FindPodsByLabel mocks Kubernetes FindPodsByLabel
 func (m *MockedKubernetes) FindPodsByLabel(name, value string) (*v1.PodList, error) {
 	args := m.Called(name)
@@ -69,8 +95,26 @@ func (m *MockedKubernetes) FindSecret(name, namespace string) (*v1.Secret, error
 	return args.Get(0).(*v1.Secret), args.Error(1)
 }
 
+// FindReplicaSet mocks Kubernetes FindReplicaSet
+func (m *MockedKubernetes) FindReplicaSet(name, namespace string) (*appsv1.ReplicaSet, error) {
+	args := m.Called(name, namespace)
+	return args.Get(0).(*appsv1.ReplicaSet), args.Error(1)
+}
+
+// FindJob mocks Kubernetes FindJob
+func (m *MockedKubernetes) FindJob(name, namespace string) (*batchv1.Job, error) {
+	args := m.Called(name, namespace)
+	return args.Get(0).(*batchv1.Job), args.Error(1)
+}
+
 // ListServices mocks Kubernetes ListServices
 func (m *MockedKubernetes) ListServices() (*v1.ServiceList, error) {
 	args := m.Called()
 	return args.Get(0).(*v1.ServiceList), args.Error(1)
 }
+
+// FindNamespacesByLabel mocks Kubernetes FindNamespacesByLabel
+func (m *MockedKubernetes) FindNamespacesByLabel(selector string) (*v1.NamespaceList, error) {
+	args := m.Called(selector)
+	return args.Get(0).(*v1.NamespaceList), args.Error(1)
+}
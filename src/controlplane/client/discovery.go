@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
@@ -37,6 +38,7 @@ type authenticationMethod string
 const (
 	none           authenticationMethod = "None (http)"
 	mTLS           authenticationMethod = "Mutual TLS"
+	bearerToken    authenticationMethod = "Bearer token file"
 	serviceAccount authenticationMethod = "Service account (Bearer token)"
 )
 
@@ -44,8 +46,10 @@ const (
 type ControlPlaneComponentClient struct {
 	authenticationMethod     authenticationMethod
 	httpClient               *http.Client
-	tlsSecretName            string
-	tlsSecretNamespace       string
+	auth                     controlplane.AuthConfig
+	clientCertificate        *certificateReloader
+	caCert                   *caReloader
+	certificates             client.CertificateRecorder
 	logger                   *logrus.Logger
 	IsComponentRunningOnNode bool
 	k8sClient                client.Kubernetes
@@ -77,6 +81,7 @@ func (c *ControlPlaneComponentClient) Do(method, urlPath string) (*http.Response
 	c.logger.Debugf("Calling endpoint: %s, authentication method: %s", r.URL.String(), string(c.authenticationMethod))
 
 	resp, err := c.httpClient.Do(r)
+	c.certificates.RecordResponse(resp)
 
 	// If there is an error, we're using the secure endpoint and insecure fallback is on, we retry using the insecure
 	// endpoint.
@@ -88,12 +93,18 @@ func (c *ControlPlaneComponentClient) Do(method, urlPath string) (*http.Response
 		if err != nil {
 			return nil, err
 		}
-		return c.httpClient.Do(r)
+		resp, err = c.httpClient.Do(r)
+		c.certificates.RecordResponse(resp)
 	}
 
 	return resp, err
 }
 
+// Certificates returns the certificates recorded while calling the component.
+func (c *ControlPlaneComponentClient) Certificates() *client.CertificateRecorder {
+	return &c.certificates
+}
+
 func (c *ControlPlaneComponentClient) buildPrometheusRequest(method string, e url.URL, urlPath string) (*http.Request, error) {
 	e.Path = path.Join(e.Path, urlPath)
 	r, err := prometheus.NewRequest(method, e.String())
@@ -106,10 +117,11 @@ func (c *ControlPlaneComponentClient) buildPrometheusRequest(method string, e ur
 func (c *ControlPlaneComponentClient) configureAuthentication() error {
 
 	if c.authenticationMethod == mTLS {
-		tlsConfig, err := c.getTLSConfigFromSecret()
+		tlsConfig, err := c.getClientTLSConfig()
 		if err != nil {
 			return errors.Wrap(err, "could not load TLS configuration")
 		}
+		c.recordClientCertificate(tlsConfig)
 
 		c.httpClient.Transport = &http.Transport{
 			TLSClientConfig: tlsConfig,
@@ -117,20 +129,24 @@ func (c *ControlPlaneComponentClient) configureAuthentication() error {
 		return nil
 	}
 
-	if c.authenticationMethod == serviceAccount {
+	if c.authenticationMethod == bearerToken || c.authenticationMethod == serviceAccount {
+		token, err := c.getBearerToken()
+		if err != nil {
+			return err
+		}
 
-		config, err := rest.InClusterConfig()
+		tlsConfig, err := c.getServerTLSConfig()
 		if err != nil {
-			return errors.Wrapf(err, "could not create in cluster Kubernetes configuration to query pod: %s", c.PodName)
+			return errors.Wrap(err, "could not load TLS configuration")
 		}
 
 		// Here we're using the default http.Transport configuration, but with a modified TLS config.
 		// For some reason the DefaultTransport is casted to an http.RoundTripper interface, so we need to convert it back.
 		t := http.DefaultTransport.(*http.Transport).Clone()
-		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		t.TLSClientConfig = tlsConfig
 
 		// Use the default kubernetes Bearer token authentication RoundTripper
-		c.httpClient.Transport = transport.NewBearerAuthRoundTripper(config.BearerToken, t)
+		c.httpClient.Transport = transport.NewBearerAuthRoundTripper(token, t)
 		return nil
 	}
 
@@ -138,18 +154,74 @@ func (c *ControlPlaneComponentClient) configureAuthentication() error {
 	return nil
 }
 
+// recordClientCertificate records the client certificate of the given TLS configuration, so its expiration
+// can be reported.
+func (c *ControlPlaneComponentClient) recordClientCertificate(tlsConfig *tls.Config) {
+	if len(tlsConfig.Certificates) > 0 {
+		c.certificates.RecordClientCertificate(&tlsConfig.Certificates[0])
+		return
+	}
+
+	if tlsConfig.GetClientCertificate != nil {
+		cert, err := tlsConfig.GetClientCertificate(nil)
+		if err != nil {
+			c.logger.Debugf("Could not load client certificate to record its expiration: %v", err)
+			return
+		}
+		c.certificates.RecordClientCertificate(cert)
+	}
+}
+
+// getBearerToken returns the token from the configured bearer token file or,
+// if none is configured, the Service Account token.
+func (c *ControlPlaneComponentClient) getBearerToken() (string, error) {
+	if c.authenticationMethod == bearerToken {
+		token, err := ioutil.ReadFile(c.auth.BearerTokenFile)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not read bearer token file %s", c.auth.BearerTokenFile)
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return "", errors.Wrapf(err, "could not create in cluster Kubernetes configuration to query pod: %s", c.PodName)
+	}
+	return config.BearerToken, nil
+}
+
+// getServerTLSConfig returns the TLS configuration used when the component is
+// not queried with a client certificate. The server certificate is only
+// verified when a CA bundle is configured.
+func (c *ControlPlaneComponentClient) getServerTLSConfig() (*tls.Config, error) {
+	if c.auth.CACertFile == "" {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+
+	return c.verifyWithCACertFile(&tls.Config{})
+}
+
+// getClientTLSConfig returns the TLS configuration holding the client
+// certificate, loaded either from a secret or from files.
+func (c *ControlPlaneComponentClient) getClientTLSConfig() (*tls.Config, error) {
+	if c.auth.TLSSecretName != "" {
+		return c.getTLSConfigFromSecret()
+	}
+	return c.getTLSConfigFromFiles()
+}
+
 func (c *ControlPlaneComponentClient) getTLSConfigFromSecret() (*tls.Config, error) {
 
-	namespace := c.tlsSecretNamespace
+	namespace := c.auth.TLSSecretNamespace
 	if namespace == "" {
 		c.logger.Debug("TLS Secret name configured, but not TLS Secret namespace. Defaulting to `default` namespace.")
 		namespace = "default"
 	}
 
-	secret, err := c.k8sClient.FindSecret(c.tlsSecretName, namespace)
+	secret, err := c.k8sClient.FindSecret(c.auth.TLSSecretName, namespace)
 
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not find secret %s containing TLS configuration", c.tlsSecretName)
+		return nil, errors.Wrapf(err, "could not find secret %s containing TLS configuration", c.auth.TLSSecretName)
 	}
 
 	var cert, key, cacert []byte
@@ -157,27 +229,36 @@ func (c *ControlPlaneComponentClient) getTLSConfigFromSecret() (*tls.Config, err
 	var ok bool
 	if cert, ok = secret.Data["cert"]; !ok {
 		return nil, invalidTLSConfig{
-			message: fmt.Sprintf("could not find TLS certificate in `cert` field in secret %s", c.tlsSecretName),
+			message: fmt.Sprintf("could not find TLS certificate in `cert` field in secret %s", c.auth.TLSSecretName),
 		}
 	}
 
 	if key, ok = secret.Data["key"]; !ok {
 		return nil, invalidTLSConfig{
-			message: fmt.Sprintf("could not find TLS key in `key` field in secret %s", c.tlsSecretName),
+			message: fmt.Sprintf("could not find TLS key in `key` field in secret %s", c.auth.TLSSecretName),
 		}
 	}
 
 	cacert, hasCACert := secret.Data["cacert"]
 	insecureSkipVerifyRaw, hasInsecureSkipVerify := secret.Data["insecureSkipVerify"]
 
-	if !hasCACert && !hasInsecureSkipVerify {
+	// The CA bundle and insecureSkipVerify of the component's configuration are used
+	// when they are not present in the secret.
+	if !hasCACert && c.auth.CACertFile != "" {
+		if cacert, err = c.readCACertFile(); err != nil {
+			return nil, err
+		}
+		hasCACert = true
+	}
+
+	if !hasCACert && !hasInsecureSkipVerify && !c.auth.InsecureSkipVerify {
 		return nil, invalidTLSConfig{
 			message: "both cacert and insecureSkipVerify are not set. One of them need to be set to be able to call ETCD metrics",
 		}
 	}
 
 	// insecureSkipVerify is set to false by default, and can be overridden with the insecureSkipVerify field
-	insecureSkipVerify := false
+	insecureSkipVerify := c.auth.InsecureSkipVerify
 	if hasInsecureSkipVerify {
 		insecureSkipVerify = strings.ToLower(string(insecureSkipVerifyRaw)) == "true"
 	}
@@ -185,6 +266,75 @@ func (c *ControlPlaneComponentClient) getTLSConfigFromSecret() (*tls.Config, err
 	return parseTLSConfig(cert, key, cacert, insecureSkipVerify)
 }
 
+// getTLSConfigFromFiles returns a TLS configuration whose client certificate
+// is reloaded from disk whenever the certificate or key files change.
+func (c *ControlPlaneComponentClient) getTLSConfigFromFiles() (*tls.Config, error) {
+
+	if c.auth.CACertFile == "" && !c.auth.InsecureSkipVerify {
+		return nil, invalidTLSConfig{
+			message: "both CA certificate file and insecureSkipVerify are not set. One of them need to be set to be able to verify the server certificate",
+		}
+	}
+
+	if c.clientCertificate == nil {
+		c.clientCertificate = newCertificateReloader(c.auth.CertFile, c.auth.KeyFile)
+	}
+
+	// Load the certificate upfront, so an invalid configuration is reported before calling the component.
+	if _, err := c.clientCertificate.load(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		GetClientCertificate: c.clientCertificate.GetClientCertificate,
+	}
+	if c.auth.CACertFile == "" {
+		tlsConfig.InsecureSkipVerify = true
+		return tlsConfig, nil
+	}
+	return c.verifyWithCACertFile(tlsConfig)
+}
+
+// verifyWithCACertFile sets up the given TLS configuration to verify the
+// server certificate against the configured CA bundle, which is reloaded from
+// disk on every TLS handshake when it changes, unless InsecureSkipVerify is
+// configured.
+func (c *ControlPlaneComponentClient) verifyWithCACertFile(tlsConfig *tls.Config) (*tls.Config, error) {
+	// Load the CA bundle upfront, so an invalid configuration is reported before calling the component.
+	if _, err := c.caCertReloader().load(); err != nil {
+		return nil, err
+	}
+
+	// The default verification is replaced by one against the reloaded CA bundle.
+	tlsConfig.InsecureSkipVerify = true
+	if !c.auth.InsecureSkipVerify {
+		tlsConfig.VerifyPeerCertificate = c.caCert.VerifyPeerCertificate(c.serverName())
+	}
+	return tlsConfig, nil
+}
+
+// readCACertFile returns the content of the configured CA bundle, which is
+// only read again from disk when it changes.
+func (c *ControlPlaneComponentClient) readCACertFile() ([]byte, error) {
+	return c.caCertReloader().read()
+}
+
+func (c *ControlPlaneComponentClient) caCertReloader() *caReloader {
+	if c.caCert == nil || c.caCert.file.path != c.auth.CACertFile {
+		c.caCert = newCAReloader(c.auth.CACertFile)
+	}
+	return c.caCert
+}
+
+// serverName returns the name expected in the certificate of the component,
+// which is the host of the endpoint queried.
+func (c *ControlPlaneComponentClient) serverName() string {
+	if c.secureEndpoint.String() != "" {
+		return c.secureEndpoint.Hostname()
+	}
+	return c.endpoint.Hostname()
+}
+
 func parseTLSConfig(certPEMBlock, keyPEMBlock, cacertPEMBlock []byte, insecureSkipVerify bool) (*tls.Config, error) {
 
 	cert, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
@@ -232,10 +382,12 @@ func (sd *discoverer) Discover(timeout time.Duration) (client.HTTPClient, error)
 
 	var authMethod authenticationMethod
 
-	// Let mTLS take precedence over service account
+	// Let mTLS take precedence over the bearer token file, and the latter over service account
 	switch {
-	case sd.component.UseMTLSAuthentication:
+	case sd.component.Auth.UseMTLS():
 		authMethod = mTLS
+	case sd.component.Auth.UseBearerToken():
+		authMethod = bearerToken
 	case sd.component.UseServiceAccountAuthentication:
 		authMethod = serviceAccount
 	default:
@@ -245,8 +397,7 @@ func (sd *discoverer) Discover(timeout time.Duration) (client.HTTPClient, error)
 	return &ControlPlaneComponentClient{
 		endpoint:                 sd.component.Endpoint,
 		secureEndpoint:           sd.component.SecureEndpoint,
-		tlsSecretName:            sd.component.TLSSecretName,
-		tlsSecretNamespace:       sd.component.TLSSecretNamespace,
+		auth:                     sd.component.Auth,
 		InsecureFallback:         sd.component.InsecureFallback,
 		IsComponentRunningOnNode: isComponentRunningOnNode,
 		PodName:                  podName,
@@ -128,7 +128,7 @@ func TestDiscover_ShouldSetNoAuth_WhenBothAuthFalse(t *testing.T) {
 
 	component := controlplane.BuildComponentList()[0]
 	component.UseServiceAccountAuthentication = false
-	component.UseMTLSAuthentication = false
+	component.Auth = controlplane.AuthConfig{}
 	podName := "scheduler"
 
 	var podsFetcher data.FetchFunc = func() (definition.RawGroups, error) {
@@ -218,7 +218,7 @@ func TestDiscover_ShouldSetSAAuth_WhenUseSAAuthIsTrue(t *testing.T) {
 func TestDiscover_ShouldSetMTLSAuth_WhenUseMTLSAuthIsTrue(t *testing.T) {
 
 	component := controlplane.BuildComponentList()[0]
-	component.UseMTLSAuthentication = true
+	component.Auth.TLSSecretName = "my-secret"
 	podName := "scheduler"
 
 	var podsFetcher data.FetchFunc = func() (definition.RawGroups, error) {
@@ -259,3 +259,50 @@ func TestDiscover_ShouldSetMTLSAuth_WhenUseMTLSAuthIsTrue(t *testing.T) {
 	cpC := cl.(*ControlPlaneComponentClient)
 	assert.Equal(t, mTLS, cpC.authenticationMethod)
 }
+
+func TestDiscover_ShouldSetBearerTokenAuth_WhenBearerTokenFileIsSet(t *testing.T) {
+
+	component := controlplane.BuildComponentList()[0]
+	component.UseServiceAccountAuthentication = true
+	component.Auth.BearerTokenFile = "/var/run/secrets/token"
+	podName := "scheduler"
+
+	var podsFetcher data.FetchFunc = func() (definition.RawGroups, error) {
+		return definition.RawGroups{
+			podEntityType: map[string]definition.RawMetrics{
+				"kube-system_kube-scheduler-minikube": {
+					"namespace": "kube-system",
+					"podName":   podName,
+					"nodeName":  "minikube",
+					"nodeIP":    "10.0.2.15",
+					"startTime": time.Now(),
+					"labels": map[string]string{
+						"k8s-app":     "kube-scheduler",
+						"extra-label": "iluvetests",
+						"tier":        "control-plane",
+					},
+				},
+			},
+		}, nil
+	}
+
+	// Given a client
+	nodeIP := "6.7.8.9"
+
+	// And a Discoverer implementation
+	d := discoverer{
+		logger:      logger,
+		nodeIP:      nodeIP,
+		component:   component,
+		podsFetcher: podsFetcher,
+	}
+
+	// When retrieving the KSM client
+	cl, err := d.Discover(0)
+
+	assert.Nil(t, err)
+
+	cpC := cl.(*ControlPlaneComponentClient)
+	assert.Equal(t, bearerToken, cpC.authenticationMethod)
+	assert.Equal(t, component.Auth, cpC.auth)
+}
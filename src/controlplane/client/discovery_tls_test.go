@@ -3,15 +3,21 @@ package client
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/newrelic/infra-integrations-sdk/log"
 	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/controlplane"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -113,7 +119,7 @@ func createClientComponent(endpoint string, cacert, key, cert []byte, insecureSk
 
 	return &ControlPlaneComponentClient{
 		httpClient:               &http.Client{},
-		tlsSecretName:            secretName,
+		auth:                     controlplane.AuthConfig{TLSSecretName: secretName},
 		authenticationMethod:     mTLS,
 		logger:                   log.New(true),
 		IsComponentRunningOnNode: true,
@@ -167,6 +173,150 @@ func startMTLSServer() string {
 	return endpoint
 }
 
+func writeTempFile(t *testing.T, dir, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+	return path
+}
+
+func TestTLSConfigFromFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := &ControlPlaneComponentClient{
+		authenticationMethod: mTLS,
+		logger:               log.New(true),
+		auth: controlplane.AuthConfig{
+			CertFile:   writeTempFile(t, dir, "client.crt", clientCert),
+			KeyFile:    writeTempFile(t, dir, "client.key", clientKey),
+			CACertFile: writeTempFile(t, dir, "ca.crt", serverCACert),
+		},
+	}
+
+	tlsConfig, err := c.getClientTLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.GetClientCertificate)
+	cert, err := tlsConfig.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+	// The server certificate is verified against the CA bundle reloaded on every handshake.
+	assert.NotNil(t, tlsConfig.VerifyPeerCertificate)
+}
+
+func TestTLSConfigFromFiles_InvalidCACert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := &ControlPlaneComponentClient{
+		authenticationMethod: mTLS,
+		logger:               log.New(true),
+		auth: controlplane.AuthConfig{
+			CertFile:   writeTempFile(t, dir, "client.crt", clientCert),
+			KeyFile:    writeTempFile(t, dir, "client.key", clientKey),
+			CACertFile: writeTempFile(t, dir, "ca.crt", []byte("not a certificate")),
+		},
+	}
+
+	_, err = c.getClientTLSConfig()
+	assert.IsType(t, invalidTLSConfig{}, err)
+}
+
+func TestCACertFileReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, testString)
+	}))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// The CA bundle doesn't contain the CA of the server yet.
+	caCertFile := writeTempFile(t, dir, "ca.crt", clientCACert)
+	c := &ControlPlaneComponentClient{
+		httpClient:           &http.Client{},
+		authenticationMethod: bearerToken,
+		logger:               log.New(true),
+		auth: controlplane.AuthConfig{
+			BearerTokenFile: writeTempFile(t, dir, "token", []byte("my-token")),
+			CACertFile:      caCertFile,
+		},
+		endpoint: *endpoint,
+	}
+
+	_, err = c.Do("GET", "/test")
+	require.Error(t, err)
+
+	// The rotated CA bundle is trusted without creating a new client.
+	writeTempFile(t, dir, "ca.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(caCertFile, future, future))
+
+	resp, err := c.Do("GET", "/test")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestTLSConfigFromFiles_RequiresCACertOrInsecureSkipVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := &ControlPlaneComponentClient{
+		authenticationMethod: mTLS,
+		logger:               log.New(true),
+		auth: controlplane.AuthConfig{
+			CertFile: writeTempFile(t, dir, "client.crt", clientCert),
+			KeyFile:  writeTempFile(t, dir, "client.key", clientKey),
+		},
+	}
+
+	_, err = c.getClientTLSConfig()
+	assert.IsType(t, invalidTLSConfig{}, err)
+
+	c.auth.InsecureSkipVerify = true
+	tlsConfig, err := c.getClientTLSConfig()
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+}
+
+func TestBearerTokenFileCalls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, testString)
+	}))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	c := &ControlPlaneComponentClient{
+		httpClient:           &http.Client{},
+		authenticationMethod: bearerToken,
+		logger:               log.New(true),
+		auth: controlplane.AuthConfig{
+			BearerTokenFile: writeTempFile(t, dir, "token", []byte("my-token\n")),
+		},
+		endpoint: *endpoint,
+	}
+
+	resp, err := c.Do("GET", "/test")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
 // These certificates are taking from the etcd TLS example
 var (
 	clientCACert = []byte(`
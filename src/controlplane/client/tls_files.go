@@ -0,0 +1,201 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// watchedFile reads a file from disk and keeps its content until the file
+// changes. Changes are detected by comparing the modification time and size,
+// which also covers symlink swaps done by projected volumes and Secret mounts.
+type watchedFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+	content []byte
+}
+
+// read returns the content of the file, and true if it was reloaded from disk
+// since the last call.
+func (f *watchedFile) read() ([]byte, bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if f.content != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.content, false, nil
+	}
+
+	content, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	f.content = content
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return content, true, nil
+}
+
+// certificateReloader loads a client certificate and key from files and
+// reloads them whenever they change on disk, so rotated certificates are
+// picked up without restarting the integration.
+type certificateReloader struct {
+	lock        sync.Mutex
+	certFile    watchedFile
+	keyFile     watchedFile
+	certificate *tls.Certificate
+}
+
+func newCertificateReloader(certFile, keyFile string) *certificateReloader {
+	return &certificateReloader{
+		certFile: watchedFile{path: certFile},
+		keyFile:  watchedFile{path: keyFile},
+	}
+}
+
+// GetClientCertificate satisfies the signature of tls.Config.GetClientCertificate,
+// so the certificate is checked for changes on every TLS handshake.
+func (r *certificateReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.load()
+}
+
+func (r *certificateReloader) load() (*tls.Certificate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cert, certChanged, err := r.certFile.read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read TLS certificate file %s", r.certFile.path)
+	}
+
+	key, keyChanged, err := r.keyFile.read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read TLS key file %s", r.keyFile.path)
+	}
+
+	if r.certificate != nil && !certChanged && !keyChanged {
+		return r.certificate, nil
+	}
+
+	certificate, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		// The files might be in the middle of a rotation, so they are read
+		// again on the next call even if they don't change anymore.
+		r.certFile.content = nil
+		r.keyFile.content = nil
+		return nil, errors.Wrapf(err, "could not parse TLS key pair from %s and %s", r.certFile.path, r.keyFile.path)
+	}
+
+	r.certificate = &certificate
+	return r.certificate, nil
+}
+
+// caReloader verifies the certificates of the servers against a CA bundle
+// loaded from a file, and reloads it whenever it changes on disk, so rotated
+// CAs are trusted without restarting the integration.
+type caReloader struct {
+	lock sync.Mutex
+	file watchedFile
+	pool *x509.CertPool
+	// poolModTime and poolSize are the ones of the file the pool was parsed
+	// from. They are kept apart from the ones of the file, which are also
+	// advanced by read, so the pool is rebuilt until a change is parsed.
+	poolModTime time.Time
+	poolSize    int64
+}
+
+func newCAReloader(caCertFile string) *caReloader {
+	return &caReloader{file: watchedFile{path: caCertFile}}
+}
+
+// read returns the content of the CA bundle.
+func (r *caReloader) read() ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cacert, _, err := r.file.read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read CA certificate file %s", r.file.path)
+	}
+	return cacert, nil
+}
+
+// load returns the pool of the certificates of the CA bundle. It fails when
+// the file doesn't contain any PEM encoded certificate.
+func (r *caReloader) load() (*x509.CertPool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cacert, _, err := r.file.read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read CA certificate file %s", r.file.path)
+	}
+
+	if r.pool != nil && r.file.modTime.Equal(r.poolModTime) && r.file.size == r.poolSize {
+		return r.pool, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(cacert) {
+		// The file might be in the middle of a rotation, so it is read again
+		// on the next call even if it doesn't change anymore.
+		r.file.content = nil
+		return nil, invalidTLSConfig{
+			message: fmt.Sprintf("could not find any PEM encoded certificate in CA certificate file %s", r.file.path),
+		}
+	}
+
+	r.pool = pool
+	r.poolModTime = r.file.modTime
+	r.poolSize = r.file.size
+	return r.pool, nil
+}
+
+// VerifyPeerCertificate returns a function satisfying the signature of
+// tls.Config.VerifyPeerCertificate, which verifies the certificate chain
+// presented by the server against the CA bundle as it is on disk during the
+// TLS handshake, and the name of the server unless it is empty. It must be
+// used with InsecureSkipVerify, which disables the default verification
+// against a fixed pool.
+func (r *caReloader) VerifyPeerCertificate(serverName string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		roots, err := r.load()
+		if err != nil {
+			return err
+		}
+
+		if len(rawCerts) == 0 {
+			return errors.New("the server didn't present any certificate")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return errors.Wrap(err, "could not parse the certificate of the server")
+			}
+			certs = append(certs, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err = certs[0].Verify(x509.VerifyOptions{
+			DNSName:       serverName,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchedFile_ReloadsOnChange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f := watchedFile{path: writeTempFile(t, dir, "ca.crt", []byte("first"))}
+
+	content, changed, err := f.read()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "first", string(content))
+
+	content, changed, err = f.read()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "first", string(content))
+
+	writeTempFile(t, dir, "ca.crt", []byte("second"))
+
+	content, changed, err = f.read()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "second", string(content))
+}
+
+func TestCertificateReloader_ReloadsRotatedCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile := writeTempFile(t, dir, "client.crt", clientCert)
+	keyFile := writeTempFile(t, dir, "client.key", clientKey)
+	r := newCertificateReloader(certFile, keyFile)
+
+	first, err := r.GetClientCertificate(nil)
+	require.NoError(t, err)
+
+	again, err := r.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.True(t, first == again, "certificate should not be parsed again if files did not change")
+
+	// Rotate the certificate, making sure the modification time changes.
+	writeTempFile(t, dir, "client.crt", serverCert)
+	writeTempFile(t, dir, "client.key", serverKey)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	rotated, err := r.GetClientCertificate(nil)
+	require.NoError(t, err)
+
+	firstLeaf, err := x509.ParseCertificate(first.Certificate[0])
+	require.NoError(t, err)
+	rotatedLeaf, err := x509.ParseCertificate(rotated.Certificate[0])
+	require.NoError(t, err)
+	assert.NotEqual(t, firstLeaf.SerialNumber, rotatedLeaf.SerialNumber)
+}
+
+func TestCAReloader_RetriesFailedRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "controlplane-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	caFile := writeTempFile(t, dir, "ca.crt", clientCACert)
+	r := newCAReloader(caFile)
+
+	first, err := r.load()
+	require.NoError(t, err)
+
+	// The rotation is caught in the middle, while the bundle is being written.
+	writeTempFile(t, dir, "ca.crt", []byte("-----BEGIN CERTIFICATE-----\nMIID3jCC"))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(caFile, future, future))
+
+	_, err = r.load()
+	assert.Error(t, err)
+
+	// Once the bundle is written, it is read before the next handshake,
+	// which must still rebuild the pool.
+	writeTempFile(t, dir, "ca.crt", append(append([]byte{}, clientCACert...), serverCert...))
+	future = future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(caFile, future, future))
+
+	_, err = r.read()
+	require.NoError(t, err)
+
+	rotated, err := r.load()
+	require.NoError(t, err)
+	assert.False(t, first == rotated, "pool should be parsed again after a failed rotation")
+
+	again, err := r.load()
+	require.NoError(t, err)
+	assert.True(t, rotated == again, "pool should not be parsed again if the file did not change")
+}
+
+func TestCertificateReloader_MissingFiles(t *testing.T) {
+	r := newCertificateReloader("/non/existing/client.crt", "/non/existing/client.key")
+
+	_, err := r.GetClientCertificate(nil)
+	assert.Error(t, err)
+}
@@ -18,15 +18,14 @@ type Component struct {
 	SkipReason                      string
 	Name                            ComponentName
 	LabelValue                      string
-	TLSSecretName                   string
-	TLSSecretNamespace              string
+	Auth                            AuthConfig
 	Endpoint                        url.URL
 	SecureEndpoint                  url.URL
 	InsecureFallback                bool
 	UseServiceAccountAuthentication bool
-	UseMTLSAuthentication           bool
 	Specs                           definition.SpecGroups
 	Queries                         []prometheus.Query
+	GroupsByLabel                   map[string]string
 	Labels                          []labels
 }
 
@@ -44,6 +43,41 @@ const (
 	APIServer ComponentName = "api-server"
 )
 
+// AuthConfig holds the TLS and authentication settings used to query a
+// control plane component.
+type AuthConfig struct {
+	// TLSSecretName and TLSSecretNamespace point to a secret holding the
+	// client certificate ("cert"), key ("key") and optionally the server
+	// CA ("cacert") and "insecureSkipVerify".
+	TLSSecretName      string
+	TLSSecretNamespace string
+	// CertFile and KeyFile are paths to a PEM encoded client certificate and
+	// key mounted in the container.
+	CertFile string
+	KeyFile  string
+	// CACertFile is a path to a PEM encoded CA bundle used to verify the
+	// component's server certificate.
+	CACertFile string
+	// InsecureSkipVerify disables the verification of the component's server
+	// certificate.
+	InsecureSkipVerify bool
+	// BearerTokenFile is a path to a file holding the bearer token sent to the
+	// component. When empty, the service account token is used if the
+	// component requires it.
+	BearerTokenFile string
+}
+
+// UseMTLS returns true if the configuration holds a client certificate, either
+// from a secret or from files.
+func (a AuthConfig) UseMTLS() bool {
+	return a.TLSSecretName != "" || a.CertFile != ""
+}
+
+// UseBearerToken returns true if the configuration holds a bearer token file.
+func (a AuthConfig) UseBearerToken() bool {
+	return a.BearerTokenFile != ""
+}
+
 // ComponentOption configures the list of components
 type ComponentOption func([]Component)
 
@@ -54,15 +88,23 @@ type ComponentOption func([]Component)
 // "cacert": optional, the cacert of the ETCD server. If omitted, insecureSkipVerify should be set to "true"
 // "insecureSkipVerify": optional, if set to "true", ETCD's server certificate will not be verified
 func WithEtcdTLSConfig(etcdTLSSecretName, etcdTLSSecretNamespace string) ComponentOption {
+	return WithAuthConfig(Etcd, AuthConfig{
+		TLSSecretName:      etcdTLSSecretName,
+		TLSSecretNamespace: etcdTLSSecretNamespace,
+	})
+}
+
+// WithAuthConfig configures the TLS and authentication settings of the given component.
+// A client certificate, either from a secret or from files, takes precedence over a bearer
+// token file, which takes precedence over the Service Account token.
+func WithAuthConfig(name ComponentName, auth AuthConfig) ComponentOption {
 	return func(components []Component) {
-		etcd := findComponentByName(Etcd, components)
-		if etcd == nil {
-			panic(fmt.Sprintf("expected component %s in list of components, but not found", string(Etcd)))
+		component := findComponentByName(name, components)
+		if component == nil {
+			panic(fmt.Sprintf("expected component %s in list of components, but not found", string(name)))
 		}
 
-		etcd.TLSSecretName = etcdTLSSecretName
-		etcd.TLSSecretNamespace = etcdTLSSecretNamespace
-		etcd.UseMTLSAuthentication = true
+		component.Auth = auth
 	}
 }
 
@@ -165,8 +207,9 @@ func BuildComponentList(options ...ComponentOption) []Component {
 				{"app": "kube-controller-manager", "kube-controller-manager": "true"},
 				{"app": "controller-manager", "controller-manager": "true"},
 			},
-			Queries: metric.ControllerManagerQueries,
-			Specs:   metric.ControllerManagerSpecs,
+			Queries:       metric.ControllerManagerQueries,
+			Specs:         metric.ControllerManagerSpecs,
+			GroupsByLabel: metric.ControllerManagerGroupsByLabel,
 			Endpoint: url.URL{
 				Scheme: "http",
 				Host:   "localhost:10252",
@@ -208,8 +251,16 @@ func BuildComponentList(options ...ComponentOption) []Component {
 // validateComponentConfiguration will check if the components are properly configured.
 // If they are not, they will be skipped.
 func validateComponentConfigurations(components []Component) {
+	for i := range components {
+		auth := components[i].Auth
+		if (auth.CertFile == "") != (auth.KeyFile == "") {
+			components[i].Skip = true
+			components[i].SkipReason = "both a client certificate file and a key file are required for TLS authentication"
+		}
+	}
+
 	etcd := findComponentByName(Etcd, components)
-	if etcd.TLSSecretName == "" {
+	if !etcd.Skip && !etcd.Auth.UseMTLS() {
 		etcd.Skip = true
 		etcd.SkipReason = "etcd requires TLS configuration, none given"
 	}
@@ -13,8 +13,8 @@ func TestSetEtcdTLSComponentOption(t *testing.T) {
 	components := BuildComponentList()
 	etcd := findComponentByName(Etcd, components)
 
-	assert.Equal(t, "", etcd.TLSSecretName)
-	assert.Equal(t, "", etcd.TLSSecretNamespace)
+	assert.Equal(t, "", etcd.Auth.TLSSecretName)
+	assert.Equal(t, "", etcd.Auth.TLSSecretNamespace)
 	assert.True(t, etcd.Skip)
 
 	// now set the TLS Configuration, and assert they are properly set
@@ -26,8 +26,8 @@ func TestSetEtcdTLSComponentOption(t *testing.T) {
 	components = BuildComponentList(WithEtcdTLSConfig(tlsSecretName, tlsSecretNamespace))
 	etcd = findComponentByName(Etcd, components)
 
-	assert.Equal(t, tlsSecretName, etcd.TLSSecretName)
-	assert.Equal(t, tlsSecretNamespace, etcd.TLSSecretNamespace)
+	assert.Equal(t, tlsSecretName, etcd.Auth.TLSSecretName)
+	assert.Equal(t, tlsSecretNamespace, etcd.Auth.TLSSecretNamespace)
 	assert.False(t, etcd.Skip)
 
 }
@@ -150,3 +150,51 @@ func TestWithEndpointURL(t *testing.T) {
 		})
 	}
 }
+
+func TestWithAuthConfig(t *testing.T) {
+	auth := AuthConfig{
+		CertFile:        "/etc/kubernetes/pki/scheduler.crt",
+		KeyFile:         "/etc/kubernetes/pki/scheduler.key",
+		CACertFile:      "/etc/kubernetes/pki/ca.crt",
+		BearerTokenFile: "/var/run/secrets/token",
+	}
+
+	components := BuildComponentList(WithAuthConfig(Scheduler, auth))
+	scheduler := findComponentByName(Scheduler, components)
+
+	assert.Equal(t, auth, scheduler.Auth)
+	assert.True(t, scheduler.Auth.UseMTLS())
+	assert.True(t, scheduler.Auth.UseBearerToken())
+	assert.False(t, scheduler.Skip)
+
+	// other components are left untouched
+	controllerManager := findComponentByName(ControllerManager, components)
+	assert.Equal(t, AuthConfig{}, controllerManager.Auth)
+}
+
+func TestWithAuthConfig_EtcdFromFiles(t *testing.T) {
+	components := BuildComponentList(WithAuthConfig(Etcd, AuthConfig{
+		CertFile:           "/etc/kubernetes/pki/etcd/healthcheck-client.crt",
+		KeyFile:            "/etc/kubernetes/pki/etcd/healthcheck-client.key",
+		InsecureSkipVerify: true,
+	}))
+	etcd := findComponentByName(Etcd, components)
+
+	assert.False(t, etcd.Skip)
+}
+
+func TestWithAuthConfig_CertFileWithoutKeyFileIsSkipped(t *testing.T) {
+	components := BuildComponentList(WithAuthConfig(Scheduler, AuthConfig{
+		CertFile: "/etc/kubernetes/pki/scheduler.crt",
+	}))
+	scheduler := findComponentByName(Scheduler, components)
+
+	assert.True(t, scheduler.Skip)
+	assert.NotEmpty(t, scheduler.SkipReason)
+}
+
+func TestWithAuthConfig_PanicsWithUnknownComponent(t *testing.T) {
+	assert.Panics(t, func() {
+		WithAuthConfig("unknown", AuthConfig{})(BuildComponentList())
+	})
+}
@@ -2,6 +2,7 @@ package controlplane
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/newrelic/nri-kubernetes/src/client"
 	"github.com/newrelic/nri-kubernetes/src/data"
@@ -15,10 +16,11 @@ import (
 const prometheusMetricsPath = "/metrics"
 
 type componentGrouper struct {
-	queries []prometheus.Query
-	client  client.HTTPClient
-	logger  *logrus.Logger
-	podName string
+	queries       []prometheus.Query
+	client        client.HTTPClient
+	logger        *logrus.Logger
+	podName       string
+	groupsByLabel map[string]string
 }
 
 func (r *componentGrouper) Group(specGroups definition.SpecGroups) (definition.RawGroups, *data.ErrorGroup) {
@@ -33,6 +35,32 @@ func (r *componentGrouper) Group(specGroups definition.SpecGroups) (definition.R
 	}
 
 	groups, errs := prometheus.GroupEntityMetricsBySpec(specGroups, mFamily, r.podName)
+
+	// These groups report one metric set per label value instead of one for
+	// the whole component, and are left out if no time-series has the label.
+	for groupLabel, label := range r.groupsByLabel {
+		if _, ok := groups[groupLabel]; !ok {
+			continue
+		}
+
+		entities := prometheus.GroupEntityMetricsByLabel(mFamily, r.podName, label)
+		if len(entities) == 0 {
+			delete(groups, groupLabel)
+			continue
+		}
+		groups[groupLabel] = entities
+	}
+
+	// Certificates are only recorded when the component is queried over TLS.
+	certificates := client.CertificateMetrics(r.client, "", time.Now())
+	for _, entities := range groups {
+		if component, ok := entities[r.podName]; ok {
+			for name, value := range certificates {
+				component[name] = value
+			}
+		}
+	}
+
 	if len(errs) > 0 {
 		return groups, &data.ErrorGroup{Recoverable: true, Errors: errs}
 	}
@@ -40,17 +68,20 @@ func (r *componentGrouper) Group(specGroups definition.SpecGroups) (definition.R
 }
 
 // NewComponentGrouper creates a grouper for the given control plane
-// component podName.
+// component podName. The groups in groupsByLabel get one raw entity per
+// value of the given label.
 func NewComponentGrouper(
 	c client.HTTPClient,
 	queries []prometheus.Query,
 	logger *logrus.Logger,
 	podName string,
+	groupsByLabel map[string]string,
 ) data.Grouper {
 	return &componentGrouper{
-		queries: queries,
-		client:  c,
-		logger:  logger,
-		podName: podName,
+		queries:       queries,
+		client:        c,
+		logger:        logger,
+		podName:       podName,
+		groupsByLabel: groupsByLabel,
 	}
 }
@@ -0,0 +1,77 @@
+package controlplane
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/nri-kubernetes/src/metric"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fileClient struct {
+	path string
+}
+
+func (c *fileClient) Do(method, path string) (*http.Response, error) {
+	content, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/plain"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(content)),
+	}, nil
+}
+
+func (c *fileClient) NodeIP() string {
+	return "localhost"
+}
+
+func TestComponentGrouper_GroupsByLabel(t *testing.T) {
+	podName := "kube-controller-manager-minikube"
+	grouper := NewComponentGrouper(
+		&fileClient{path: "../../cmd/kubernetes-static/data/1_18/controlplane/controller-manager/metrics"},
+		metric.ControllerManagerQueries,
+		logrus.New(),
+		podName,
+		metric.ControllerManagerGroupsByLabel,
+	)
+
+	groups, errs := grouper.Group(metric.ControllerManagerSpecs)
+	require.Nil(t, errs)
+
+	// The component metric set doesn't sum the workqueues of the controllers.
+	require.Contains(t, groups["controller-manager"], podName)
+	for _, spec := range metric.ControllerManagerSpecs["controller-manager"].Specs {
+		assert.False(t, strings.HasPrefix(spec.Name, "workqueue"), spec.Name)
+	}
+
+	workqueues := groups["controller-manager-workqueue"]
+	require.Contains(t, workqueues, podName+"_ClusterRoleAggregator")
+	assert.NotContains(t, workqueues, podName)
+
+	raw := workqueues[podName+"_ClusterRoleAggregator"]
+	assert.Equal(t, podName, raw[prometheus.ParentEntityIDKey])
+	assert.Equal(t, "ClusterRoleAggregator", raw["name"])
+	for _, m := range raw["workqueue_queue_duration_seconds"].([]prometheus.Metric) {
+		assert.Equal(t, "ClusterRoleAggregator", m.Labels["name"])
+	}
+
+	specs := metric.ControllerManagerSpecs["controller-manager-workqueue"]
+	id, err := specs.IDGenerator("controller-manager-workqueue", podName+"_ClusterRoleAggregator", groups)
+	assert.NoError(t, err)
+	assert.Equal(t, podName, id)
+
+	for _, spec := range specs.Specs {
+		_, err := spec.ValueFunc("controller-manager-workqueue", podName+"_ClusterRoleAggregator", groups)
+		assert.NoError(t, err, spec.Name)
+	}
+}
@@ -16,6 +16,30 @@ type FetchedValue interface{}
 // FetchedValues is a map of FetchedValue indexed by metric name.
 type FetchedValues map[string]FetchedValue
 
+// DimensionalValue is the value of a metric for a combination of dimensions,
+// like the labels of a Prometheus time-series.
+type DimensionalValue struct {
+	Name       string
+	Dimensions map[string]string
+	Value      FetchedValue
+}
+
+// FetchedDimensionalValues is a map of DimensionalValue indexed by a metric
+// name which encodes the dimensions, e.g. apiserverRequestsDelta_verb_GET.
+// They are populated like FetchedValues, and additionally in one metric set
+// per combination of dimensions when dimensional metrics are enabled.
+type FetchedDimensionalValues map[string]DimensionalValue
+
+// FetchedValues returns the values indexed by the names encoding their
+// dimensions.
+func (v FetchedDimensionalValues) FetchedValues() FetchedValues {
+	values := make(FetchedValues, len(v))
+	for name, dv := range v {
+		values[name] = dv.Value
+	}
+	return values
+}
+
 // FetchFunc fetches values or values from raw metric groups.
 // Return FetchedValues if you want to prototype metrics.
 type FetchFunc func(groupLabel, entityID string, groups RawGroups) (FetchedValue, error)
@@ -0,0 +1,137 @@
+package definition
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// AttributeLimits bounds the metrics populated from the FetchedValues returned
+// by the specs, like label.*, selector.* or the Prometheus metrics suffixed by
+// their labels, whose number and values are not known in advance. A single
+// object with hundreds of labels, or with labels whose values change on every
+// deploy, would otherwise explode the number of attributes reported.
+type AttributeLimits struct {
+	maxAttributes  int
+	deny           *regexp.Regexp
+	maxValueLength int
+
+	hits     map[string]*LimitHits
+	order    []string
+	reported map[string]bool
+}
+
+// LimitHits are the metrics of an entity affected by the AttributeLimits.
+type LimitHits struct {
+	Entity string
+	// Dropped are the metrics over the max number of attributes.
+	Dropped       int
+	MaxAttributes int
+	// Denied are the metrics whose name matches the deny regex.
+	Denied int
+	// Truncated are the values longer than the max length.
+	Truncated int
+}
+
+func (h LimitHits) String() string {
+	var hits []string
+	if h.Dropped > 0 {
+		hits = append(hits, fmt.Sprintf("%d attributes dropped over the max of %d", h.Dropped, h.MaxAttributes))
+	}
+	if h.Denied > 0 {
+		hits = append(hits, fmt.Sprintf("%d denied attributes dropped", h.Denied))
+	}
+	if h.Truncated > 0 {
+		hits = append(hits, fmt.Sprintf("%d values truncated", h.Truncated))
+	}
+	return strings.Join(hits, ", ")
+}
+
+// NewAttributeLimits returns AttributeLimits keeping at most maxAttributes
+// metrics from FetchedValues per metric set, dropping the ones whose name
+// matches denyRegex and truncating their string values to maxValueLength
+// characters. Zero values and an empty regex disable the limits. The metrics
+// over maxAttributes are dropped following the order of the specs of the
+// group, then the order of their names within each spec.
+func NewAttributeLimits(maxAttributes int, denyRegex string, maxValueLength int) (*AttributeLimits, error) {
+	l := &AttributeLimits{
+		maxAttributes:  maxAttributes,
+		maxValueLength: maxValueLength,
+		hits:           make(map[string]*LimitHits),
+		reported:       make(map[string]bool),
+	}
+
+	if denyRegex != "" {
+		deny, err := regexp.Compile(denyRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attribute deny regex %q: %s", denyRegex, err)
+		}
+		l.deny = deny
+	}
+	return l, nil
+}
+
+// limit returns the values to populate in the metric set of the given entity,
+// which already has populated metrics from FetchedValues. The values are
+// kept in the order of their names, so the same ones are reported every time.
+// It is called once per spec, in the order of the specs, so the values of the
+// first specs are kept before those of the following ones.
+func (l *AttributeLimits) limit(entity string, values FetchedValues, populated int) FetchedValues {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	limited := make(FetchedValues, len(values))
+	for _, name := range names {
+		if l.deny != nil && l.deny.MatchString(name) {
+			l.hit(entity).Denied++
+			continue
+		}
+
+		if l.maxAttributes > 0 && populated+len(limited) >= l.maxAttributes {
+			h := l.hit(entity)
+			h.Dropped++
+			h.MaxAttributes = l.maxAttributes
+			continue
+		}
+
+		value := values[name]
+		if s, ok := value.(string); ok && l.maxValueLength > 0 && utf8.RuneCountInString(s) > l.maxValueLength {
+			value = string([]rune(s)[:l.maxValueLength])
+			l.hit(entity).Truncated++
+		}
+		limited[name] = value
+	}
+	return limited
+}
+
+func (l *AttributeLimits) hit(entity string) *LimitHits {
+	h, ok := l.hits[entity]
+	if !ok {
+		h = &LimitHits{Entity: entity}
+		l.hits[entity] = h
+		l.order = append(l.order, entity)
+	}
+	return h
+}
+
+// Flush returns the entities which hit a limit since the last call, in the
+// order they did. Each entity is only returned the first time, so a warning
+// is logged once per entity.
+func (l *AttributeLimits) Flush() []LimitHits {
+	var hits []LimitHits
+	for _, entity := range l.order {
+		if l.reported[entity] {
+			continue
+		}
+		l.reported[entity] = true
+		hits = append(hits, *l.hits[entity])
+	}
+	l.hits = make(map[string]*LimitHits)
+	l.order = nil
+	return hits
+}
@@ -0,0 +1,168 @@
+package definition
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func TestNewIntegrationProtocol2PopulateFunc_WithAttributeLimits(t *testing.T) {
+	specs := SpecGroups{
+		"test": SpecGroup{
+			TypeGenerator: fromGroupEntityTypeGuessFunc,
+			Specs: []Spec{
+				{"metric_1", FromRaw("raw_metric_name_1"), metric.GAUGE, false},
+				{"label", fromMultiple(FetchedValues{
+					"label.app":               "nginx",
+					"label.pod-template-hash": "5c689d88bb",
+					"label.team":              "payments",
+				}), metric.ATTRIBUTE, false},
+				{"selector", fromMultiple(FetchedValues{
+					"selector.app":         "nginx",
+					"selector.description": "a very long description",
+				}), metric.ATTRIBUTE, false},
+			},
+		},
+	}
+	rawGroups := RawGroups{
+		"test": {
+			"entity_id_1": RawMetrics{"raw_metric_name_1": 1},
+		},
+	}
+
+	limits, err := NewAttributeLimits(3, `^label\.pod-template-hash$`, 6)
+	require.NoError(t, err)
+
+	integration, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+	populated, errs := NewIntegrationProtocol2PopulateFunc(
+		integration,
+		defaultNS,
+		&version.Info{GitVersion: "v1.15.42"},
+		fromGroupMetricSetTypeGuessFunc,
+		WithAttributeLimits(limits),
+	)(rawGroups, specs)
+	assert.True(t, populated)
+	assert.Empty(t, errs)
+
+	// The values are kept in the order of their names, up to the max.
+	ms := integration.Data[0].Metrics[0]
+	assert.Equal(t, 1, ms["metric_1"])
+	assert.Equal(t, "nginx", ms["label.app"])
+	assert.Equal(t, "paymen", ms["label.team"])
+	assert.Equal(t, "nginx", ms["selector.app"])
+	assert.NotContains(t, ms, "label.pod-template-hash")
+	assert.NotContains(t, ms, "selector.description")
+
+	assert.Equal(t, []LimitHits{{
+		Entity:        "playground:test:entity_id_1",
+		Dropped:       1,
+		MaxAttributes: 3,
+		Denied:        1,
+		Truncated:     1,
+	}}, limits.Flush())
+}
+
+func TestIntegrationProtocol2DimensionalPopulateFunc_WithAttributeLimits(t *testing.T) {
+	specs := SpecGroups{
+		"test": SpecGroup{
+			TypeGenerator: fromGroupEntityTypeGuessFunc,
+			Specs: []Spec{
+				{"requests", func(groupLabel, entityID string, groups RawGroups) (FetchedValue, error) {
+					return FetchedDimensionalValues{
+						"requests_verb_GET": {Name: "requests", Dimensions: map[string]string{
+							"verb":        "GET",
+							"code":        "200",
+							"resource":    "pods",
+							"description": "a very long description",
+						}, Value: 1},
+					}, nil
+				}, metric.GAUGE, false},
+				{"latency", func(groupLabel, entityID string, groups RawGroups) (FetchedValue, error) {
+					return FetchedDimensionalValues{
+						"latency_verb_GET": {Name: "latency", Dimensions: map[string]string{
+							"verb":        "GET",
+							"code":        "200",
+							"resource":    "pods",
+							"description": "a very long description",
+						}, Value: 2},
+					}, nil
+				}, metric.GAUGE, false},
+			},
+		},
+	}
+	rawGroups := RawGroups{
+		"test": {
+			"entity_id_1": RawMetrics{},
+		},
+	}
+
+	limits, err := NewAttributeLimits(4, `^resource$`, 6)
+	require.NoError(t, err)
+
+	integration, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+	populated, errs := IntegrationProtocol2DimensionalPopulateFunc(
+		integration,
+		defaultNS,
+		fromGroupMetricSetTypeGuessFunc,
+		WithMetricSetManipulators(metricsNamingManipulator),
+		WithAttributeLimits(limits),
+	)(rawGroups, specs)
+	assert.True(t, populated)
+	assert.Empty(t, errs)
+
+	// The dimensions are kept before the metrics, in the order of their names.
+	assert.Equal(t, []metric.MetricSet{{
+		"event_type":  "TestSample",
+		"entityName":  "playground:test:entity_id_1",
+		"displayName": "entity_id_1",
+		"code":        "200",
+		"description": "a very",
+		"verb":        "GET",
+		"latency":     2,
+	}}, integration.Data[0].Metrics)
+
+	assert.Equal(t, []LimitHits{{
+		Entity:        "playground:test:entity_id_1",
+		Dropped:       1,
+		MaxAttributes: 4,
+		Denied:        1,
+		Truncated:     1,
+	}}, limits.Flush())
+}
+
+func TestAttributeLimits_FlushOncePerEntity(t *testing.T) {
+	limits, err := NewAttributeLimits(1, "", 0)
+	require.NoError(t, err)
+
+	values := FetchedValues{"label.app": "nginx", "label.team": "payments"}
+	limits.limit("k8s:playground:default:pod:nginx", values, 0)
+	hits := limits.Flush()
+	require.Len(t, hits, 1)
+	assert.Equal(t, "1 attributes dropped over the max of 1", hits[0].String())
+
+	limits.limit("k8s:playground:default:pod:nginx", values, 0)
+	limits.limit("k8s:playground:default:pod:redis", values, 0)
+	hits = limits.Flush()
+	require.Len(t, hits, 1)
+	assert.Equal(t, "k8s:playground:default:pod:redis", hits[0].Entity)
+}
+
+func TestAttributeLimits_Disabled(t *testing.T) {
+	limits, err := NewAttributeLimits(0, "", 0)
+	require.NoError(t, err)
+
+	values := FetchedValues{"label.app": "nginx", "label.team": "payments"}
+	assert.Equal(t, values, limits.limit("k8s:playground:default:pod:nginx", values, 0))
+	assert.Empty(t, limits.Flush())
+}
+
+func TestNewAttributeLimits_InvalidRegex(t *testing.T) {
+	_, err := NewAttributeLimits(0, "label.(", 0)
+	assert.Error(t, err)
+}
@@ -2,6 +2,9 @@ package definition
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/newrelic/infra-integrations-sdk/metric"
 	"github.com/newrelic/infra-integrations-sdk/sdk"
@@ -34,6 +37,47 @@ func populateCluster(i *sdk.IntegrationProtocol2, clusterName string, k8sVersion
 	return ms.SetMetric("clusterK8sVersion", k8sVersionStr, metric.ATTRIBUTE)
 }
 
+// PopulateOption configures the populate funcs of an integration protocol v2.
+type PopulateOption func(*populateConfig)
+
+type populateConfig struct {
+	sampler        CounterSampler
+	limits         *AttributeLimits
+	msManipulators []MetricSetManipulator
+}
+
+// WithCounterSampler computes the DELTA and RATE metrics with the given
+// sampler and reports them as gauges, instead of leaving them to the SDK.
+func WithCounterSampler(sampler CounterSampler) PopulateOption {
+	return func(c *populateConfig) {
+		c.sampler = sampler
+	}
+}
+
+// WithAttributeLimits bounds the metrics populated from FetchedValues with
+// the given limits.
+func WithAttributeLimits(limits *AttributeLimits) PopulateOption {
+	return func(c *populateConfig) {
+		c.limits = limits
+	}
+}
+
+// WithMetricSetManipulators applies the given manipulators to every metric
+// set, in order.
+func WithMetricSetManipulators(msManipulators ...MetricSetManipulator) PopulateOption {
+	return func(c *populateConfig) {
+		c.msManipulators = append(c.msManipulators, msManipulators...)
+	}
+}
+
+func newPopulateConfig(options []PopulateOption) populateConfig {
+	var c populateConfig
+	for _, opt := range options {
+		opt(&c)
+	}
+	return c
+}
+
 // IntegrationProtocol2PopulateFunc populates an integration protocol v2 with the given metrics and definition.
 func IntegrationProtocol2PopulateFunc(
 	i *sdk.IntegrationProtocol2,
@@ -42,6 +86,19 @@ func IntegrationProtocol2PopulateFunc(
 	msTypeGuesser GuessFunc,
 	msManipulators ...MetricSetManipulator,
 ) PopulateFunc {
+	return NewIntegrationProtocol2PopulateFunc(i, clusterName, k8sVersion, msTypeGuesser, WithMetricSetManipulators(msManipulators...))
+}
+
+// NewIntegrationProtocol2PopulateFunc populates an integration protocol v2
+// with the given metrics and definition, configured with the given options.
+func NewIntegrationProtocol2PopulateFunc(
+	i *sdk.IntegrationProtocol2,
+	clusterName string,
+	k8sVersion fmt.Stringer,
+	msTypeGuesser GuessFunc,
+	options ...PopulateOption,
+) PopulateFunc {
+	config := newPopulateConfig(options)
 	return func(groups RawGroups, specs SpecGroups) (bool, []error) {
 		var populated bool
 		var errs []error
@@ -54,26 +111,7 @@ func IntegrationProtocol2PopulateFunc(
 					continue
 				}
 
-				msEntityID := entityID
-				if generator := specs[groupLabel].IDGenerator; generator != nil {
-					generatedEntityID, err := generator(groupLabel, entityID, groups)
-					if err != nil {
-						errs = append(errs, fmt.Errorf("error generating entity ID for %s: %s", entityID, err))
-						continue
-					}
-					msEntityID = generatedEntityID
-				}
-
-				if generatorType := specs[groupLabel].TypeGenerator; generatorType != nil {
-					generatedEntityType, err := generatorType(groupLabel, entityID, groups, clusterName)
-					if err != nil {
-						errs = append(errs, fmt.Errorf("error generating entity type for %s: %s", entityID, err))
-						continue
-					}
-					msEntityType = generatedEntityType
-				}
-
-				e, err := i.Entity(msEntityID, msEntityType)
+				e, err := entityFor(i, clusterName, groupLabel, entityID, groups, specs, &msEntityType)
 				if err != nil {
 					errs = append(errs, err)
 					continue
@@ -86,7 +124,7 @@ func IntegrationProtocol2PopulateFunc(
 				}
 
 				ms := e.NewMetricSet(msType)
-				for _, m := range msManipulators {
+				for _, m := range config.msManipulators {
 					err = m(ms, e.Entity, clusterName)
 					if err != nil {
 						errs = append(errs, err)
@@ -94,7 +132,7 @@ func IntegrationProtocol2PopulateFunc(
 					}
 				}
 
-				wasPopulated, populateErrs := metricSetPopulateFunc(ms, groupLabel, entityID)(groups, specs)
+				wasPopulated, populateErrs := metricSetPopulateFunc(ms, groupLabel, entityID, config.sampler, config.limits)(groups, specs)
 				if len(populateErrs) != 0 {
 					for _, err := range populateErrs {
 						errs = append(errs, fmt.Errorf("error populating metric for entity ID %s: %s", entityID, err))
@@ -116,8 +154,288 @@ func IntegrationProtocol2PopulateFunc(
 	}
 }
 
-func metricSetPopulateFunc(ms metric.MetricSet, groupLabel, entityID string) PopulateFunc {
+// entityFor returns the entity of the given raw entity, generating its ID and
+// type with the SpecGroup generators. msEntityType is the type of the
+// previous entity, which is kept for the groups without a TypeGenerator, and
+// is updated with the generated one otherwise.
+func entityFor(
+	i *sdk.IntegrationProtocol2,
+	clusterName string,
+	groupLabel string,
+	entityID string,
+	groups RawGroups,
+	specs SpecGroups,
+	msEntityType *string,
+) (*sdk.EntityData, error) {
+	msEntityID := entityID
+	if generator := specs[groupLabel].IDGenerator; generator != nil {
+		generatedEntityID, err := generator(groupLabel, entityID, groups)
+		if err != nil {
+			return nil, fmt.Errorf("error generating entity ID for %s: %s", entityID, err)
+		}
+		msEntityID = generatedEntityID
+	}
+
+	if generatorType := specs[groupLabel].TypeGenerator; generatorType != nil {
+		generatedEntityType, err := generatorType(groupLabel, entityID, groups, clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("error generating entity type for %s: %s", entityID, err)
+		}
+		*msEntityType = generatedEntityType
+	}
+
+	return i.Entity(msEntityID, *msEntityType)
+}
+
+// IntegrationProtocol2DimensionalPopulateFunc populates an integration
+// protocol v2 with one metric set per entity and combination of dimensions
+// of the FetchedDimensionalValues returned by the specs. The dimensions are
+// set as attributes, and the metrics keep their name regardless of them.
+//
+// It complements IntegrationProtocol2PopulateFunc, which populates the same
+// values with the dimensions encoded in the metric names, so msTypeGuesser
+// should guess a different type than the one used there.
+//
+// The DELTA and RATE metrics are always reported as gauges, computed with the
+// SDK cache unless a sampler is given with WithCounterSampler. The limits
+// given with WithAttributeLimits bound the dimensions and then the metrics of
+// each metric set.
+func IntegrationProtocol2DimensionalPopulateFunc(
+	i *sdk.IntegrationProtocol2,
+	clusterName string,
+	msTypeGuesser GuessFunc,
+	options ...PopulateOption,
+) PopulateFunc {
+	config := newPopulateConfig(options)
+	sampler := config.sampler
+	if sampler == nil {
+		sampler = sdkCacheSampler{}
+	}
+
+	return func(groups RawGroups, specs SpecGroups) (bool, []error) {
+		var populated bool
+		var errs []error
+		var msEntityType string
+		for groupLabel, entities := range groups {
+			for entityID := range entities {
+
+				// Only populate specified groups.
+				if _, ok := specs[groupLabel]; !ok {
+					continue
+				}
+
+				var combinations []string
+				dimensions := make(map[string]map[string]string)
+				values := make(map[string][]dimensionalSpecValue)
+				for _, ex := range specs[groupLabel].Specs {
+					val, err := ex.ValueFunc(groupLabel, entityID, groups)
+					if err != nil {
+						// Errors are already reported when populating the entity metric sets.
+						continue
+					}
+
+					multiple, ok := val.(FetchedDimensionalValues)
+					if !ok {
+						continue
+					}
+
+					for _, v := range multiple {
+						// Values without dimensions are only reported in the entity metric set.
+						if len(v.Dimensions) == 0 {
+							continue
+						}
+
+						key := dimensionsKey(v.Dimensions)
+						if _, ok := values[key]; !ok {
+							combinations = append(combinations, key)
+							dimensions[key] = v.Dimensions
+						}
+						values[key] = append(values[key], dimensionalSpecValue{spec: ex, value: v})
+					}
+				}
+
+				if len(combinations) == 0 {
+					continue
+				}
+
+				e, err := entityFor(i, clusterName, groupLabel, entityID, groups, specs, &msEntityType)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+
+				msType, err := msTypeGuesser(clusterName, groupLabel, entityID, groups)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+
+				processStartTime := startTime(groupLabel, entityID, groups, specs)
+				sort.Strings(combinations)
+				for _, key := range combinations {
+					ms := e.NewMetricSet(msType)
+					for _, m := range config.msManipulators {
+						err = m(ms, e.Entity, clusterName)
+						if err != nil {
+							errs = append(errs, err)
+							continue
+						}
+					}
+
+					attributes := make(FetchedValues, len(dimensions[key]))
+					for name, value := range dimensions[key] {
+						// Dimensions never override the attributes identifying the metric set.
+						if _, ok := ms[name]; ok {
+							continue
+						}
+						attributes[name] = value
+					}
+					metrics := make(FetchedValues, len(values[key]))
+					for _, v := range values[key] {
+						metrics[v.value.Name] = v.value.Value
+					}
+					// The dimensions are bounded first, as the metrics are meaningless without them.
+					if config.limits != nil {
+						entity := fmt.Sprintf("%v", ms["entityName"])
+						attributes = config.limits.limit(entity, attributes, 0)
+						metrics = config.limits.limit(entity, metrics, len(attributes))
+					}
+
+					for name, value := range attributes {
+						_ = ms.SetMetric(name, value, metric.ATTRIBUTE)
+					}
+
+					for _, v := range values[key] {
+						value, ok := metrics[v.value.Name]
+						if !ok {
+							continue
+						}
+						err := setSampledMetric(ms, sampler, key, v.value.Name, value, v.spec.Type, processStartTime)
+						if err == ErrNoPreviousSample {
+							continue
+						}
+						if err != nil {
+							if !v.spec.Optional {
+								errs = append(errs, fmt.Errorf("cannot set metric %s with value %v in metric set, %s", v.value.Name, v.value.Value, err))
+							}
+							continue
+						}
+
+						populated = true
+					}
+				}
+			}
+		}
+		return populated, errs
+	}
+}
+
+type dimensionalSpecValue struct {
+	spec  Spec
+	value DimensionalValue
+}
+
+// dimensionsKey returns a key identifying the combination of dimensions,
+// independent of the order of the map.
+func dimensionsKey(dimensions map[string]string) string {
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, dimensions[name]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// setSampledMetric sets the metric in the metric set. DELTA and RATE values
+// are computed with the sampler and set as gauges. Their counters are
+// identified by entity, event type and metric name, plus the dimensions or
+// the counter key of the metric set, if any, which the SDK would share
+// between all the metric sets of an entity.
+func setSampledMetric(
+	ms metric.MetricSet,
+	sampler CounterSampler,
+	dimensions string,
+	name string,
+	value FetchedValue,
+	sourceType metric.SourceType,
+	startTime float64,
+) error {
+	if sourceType != metric.DELTA && sourceType != metric.RATE {
+		return ms.SetMetric(name, value, sourceType)
+	}
+
+	floatValue, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64)
+	if err != nil {
+		return fmt.Errorf("invalid (non-numeric) data type for metric %s", name)
+	}
+
+	key := fmt.Sprintf("%v_%v_%s", ms["entityName"], ms["event_type"], name)
+	if dimensions != "" {
+		key = fmt.Sprintf("%v_%v_%s_%s", ms["entityName"], ms["event_type"], dimensions, name)
+	}
+
+	sampledValue, err := sampler.Sample(key, floatValue, sourceType, startTime)
+	if err != nil {
+		return err
+	}
+
+	return ms.SetMetric(name, sampledValue, metric.GAUGE)
+}
+
+// startTime returns the start time of the process exposing the counters of
+// the entity, or 0 when the group doesn't define it or it can't be fetched.
+func startTime(groupLabel, entityID string, groups RawGroups, specs SpecGroups) float64 {
+	fetch := specs[groupLabel].StartTime
+	if fetch == nil {
+		return 0
+	}
+
+	value, err := fetch(groupLabel, entityID, groups)
+	if err != nil {
+		return 0
+	}
+
+	floatValue, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64)
+	if err != nil {
+		return 0
+	}
+	return floatValue
+}
+
+func metricSetPopulateFunc(ms metric.MetricSet, groupLabel, entityID string, sampler CounterSampler, limits *AttributeLimits) PopulateFunc {
 	return func(groups RawGroups, specs SpecGroups) (populated bool, errs []error) {
+		// Number of metrics populated from FetchedValues, bounded by the limits.
+		var multiplePopulated int
+
+		var counterKey string
+		if fetch := specs[groupLabel].CounterKey; fetch != nil {
+			if v, err := fetch(groupLabel, entityID, groups); err == nil {
+				counterKey = fmt.Sprintf("%v", v)
+			}
+		}
+		s := sampler
+		// The SDK would share the counters between the metric sets of the entity.
+		if s == nil && counterKey != "" {
+			s = sdkCacheSampler{}
+		}
+
+		var processStartTime float64
+		if s != nil {
+			processStartTime = startTime(groupLabel, entityID, groups, specs)
+		}
+
+		setMetric := func(name string, value FetchedValue, sourceType metric.SourceType) error {
+			if s == nil {
+				return ms.SetMetric(name, value, sourceType)
+			}
+			return setSampledMetric(ms, s, counterKey, name, value, sourceType, processStartTime)
+		}
+
 		for _, ex := range specs[groupLabel].Specs {
 			val, err := ex.ValueFunc(groupLabel, entityID, groups)
 			if err != nil {
@@ -127,9 +445,21 @@ func metricSetPopulateFunc(ms metric.MetricSet, groupLabel, entityID string) Pop
 				continue
 			}
 
+			if dimensional, ok := val.(FetchedDimensionalValues); ok {
+				val = dimensional.FetchedValues()
+			}
+
 			if multiple, ok := val.(FetchedValues); ok {
+				if limits != nil {
+					multiple = limits.limit(fmt.Sprintf("%v", ms["entityName"]), multiple, multiplePopulated)
+					multiplePopulated += len(multiple)
+				}
+
 				for k, v := range multiple {
-					err := ms.SetMetric(k, v, ex.Type)
+					err := setMetric(k, v, ex.Type)
+					if err == ErrNoPreviousSample {
+						continue
+					}
 					if err != nil {
 						if !ex.Optional {
 							errs = append(errs, fmt.Errorf("cannot set metric %s with value %v in metric set, %s", k, v, err))
@@ -140,7 +470,10 @@ func metricSetPopulateFunc(ms metric.MetricSet, groupLabel, entityID string) Pop
 					populated = true
 				}
 			} else {
-				err := ms.SetMetric(ex.Name, val, ex.Type)
+				err := setMetric(ex.Name, val, ex.Type)
+				if err == ErrNoPreviousSample {
+					continue
+				}
 				if err != nil {
 					if !ex.Optional {
 						errs = append(errs, fmt.Errorf("cannot set metric %s with value %v in metric set, %s", ex.Name, val, err))
@@ -9,6 +9,7 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/metric"
 	"github.com/newrelic/infra-integrations-sdk/sdk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/version"
 )
 
@@ -382,6 +383,28 @@ func TestIntegrationProtocol2PopulateFunc_EntityIDGenerator(t *testing.T) {
 	assert.Contains(t, integration.Data, &expectedEntityData2)
 }
 
+func TestEntityFor_KeepsThePreviousEntityType(t *testing.T) {
+	integration, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	if err != nil {
+		t.Fatal()
+	}
+	specGroups := SpecGroups{
+		"test":    SpecGroup{TypeGenerator: fromGroupEntityTypeGuessFunc},
+		"untyped": SpecGroup{},
+	}
+
+	var msEntityType string
+	e, err := entityFor(integration, defaultNS, "test", "entity_id_1", rawGroupsSample, specGroups, &msEntityType)
+	require.NoError(t, err)
+	assert.Equal(t, "playground:test", e.Entity.Type)
+	assert.Equal(t, "playground:test", msEntityType)
+
+	// Groups without a TypeGenerator get the type of the previous entity.
+	e, err = entityFor(integration, defaultNS, "untyped", "entity_id_2", rawGroupsSample, specGroups, &msEntityType)
+	require.NoError(t, err)
+	assert.Equal(t, "playground:test", e.Entity.Type)
+}
+
 func TestIntegrationProtocol2PopulateFunc_EntityIDGeneratorFuncWithError(t *testing.T) {
 	generator := func(groupLabel, rawEntityID string, g RawGroups) (string, error) {
 		return "", errors.New("error generating entity ID")
@@ -642,3 +665,216 @@ func TestIntegrationProtocol2PopulateFunc_msTypeGuesserFuncWithError(t *testing.
 	assert.Contains(t, integration.Data, &expectedEntityData1)
 	assert.Contains(t, integration.Data, &expectedEntityData2)
 }
+
+func TestIntegrationProtocol2DimensionalPopulateFunc(t *testing.T) {
+	integration, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	if err != nil {
+		t.Fatal()
+	}
+
+	dimensionalSpecs := SpecGroups{
+		"test": SpecGroup{
+			TypeGenerator: fromGroupEntityTypeGuessFunc,
+			Specs: []Spec{
+				{"metric_1", FromRaw("raw_metric_name_1"), metric.GAUGE, false},
+				{
+					"requests",
+					func(groupLabel, entityID string, groups RawGroups) (FetchedValue, error) {
+						return FetchedDimensionalValues{
+							"requests_verb_GET": {Name: "requests", Dimensions: map[string]string{"verb": "GET"}, Value: 1},
+							"requests_verb_PUT": {Name: "requests", Dimensions: map[string]string{"verb": "PUT"}, Value: 2},
+							"requests":          {Name: "requests", Value: 3},
+						}, nil
+					},
+					metric.GAUGE,
+					false,
+				},
+			},
+		},
+	}
+	rawGroups := RawGroups{
+		"test": {
+			"entity_id_1": RawMetrics{"raw_metric_name_1": 1},
+		},
+	}
+
+	populated, errs := IntegrationProtocol2PopulateFunc(
+		integration,
+		defaultNS,
+		&version.Info{GitVersion: "v1.15.42"},
+		fromGroupMetricSetTypeGuessFunc,
+		metricsNamingManipulator,
+	)(rawGroups, dimensionalSpecs)
+	assert.True(t, populated)
+	assert.Empty(t, errs)
+
+	populated, errs = IntegrationProtocol2DimensionalPopulateFunc(
+		integration,
+		defaultNS,
+		func(_, groupLabel, _ string, _ RawGroups) (string, error) {
+			return fmt.Sprintf("%vDimensionalSample", strings.Title(groupLabel)), nil
+		},
+		WithMetricSetManipulators(metricsNamingManipulator),
+	)(rawGroups, dimensionalSpecs)
+	assert.True(t, populated)
+	assert.Empty(t, errs)
+
+	expectedEntityData, err := sdk.NewEntityData("entity_id_1", "playground:test")
+	if err != nil {
+		t.Fatal()
+	}
+	expectedEntityData.Metrics = []metric.MetricSet{
+		{
+			"event_type":        "TestSample",
+			"metric_1":          1,
+			"requests_verb_GET": 1,
+			"requests_verb_PUT": 2,
+			"requests":          3,
+			"entityName":        "playground:test:entity_id_1",
+			"displayName":       "entity_id_1",
+		},
+		{
+			"event_type":  "TestDimensionalSample",
+			"verb":        "GET",
+			"requests":    1,
+			"entityName":  "playground:test:entity_id_1",
+			"displayName": "entity_id_1",
+		},
+		{
+			"event_type":  "TestDimensionalSample",
+			"verb":        "PUT",
+			"requests":    2,
+			"entityName":  "playground:test:entity_id_1",
+			"displayName": "entity_id_1",
+		},
+	}
+	assert.Equal(t, []*sdk.EntityData{&expectedEntityData}, integration.Data[:1])
+}
+
+func TestDimensionsKey(t *testing.T) {
+	assert.Equal(t, "code=200,verb=GET", dimensionsKey(map[string]string{"verb": "GET", "code": "200"}))
+	assert.Equal(t, "", dimensionsKey(nil))
+}
+
+func TestSetSampledMetric_DeltaIsKeptPerDimensions(t *testing.T) {
+	ms := metric.NewMetricSet("TestDimensionalSample")
+	assert.NoError(t, ms.SetMetric("entityName", "playground:test:delta_entity", metric.ATTRIBUTE))
+
+	assert.NoError(t, setSampledMetric(ms, sdkCacheSampler{}, "verb=GET", "requestsDelta", 10, metric.DELTA, 0))
+	assert.Equal(t, float64(0), ms["requestsDelta"])
+
+	// Another combination of dimensions doesn't share the previous value.
+	assert.NoError(t, setSampledMetric(ms, sdkCacheSampler{}, "verb=PUT", "requestsDelta", 20, metric.DELTA, 0))
+	assert.Equal(t, float64(0), ms["requestsDelta"])
+
+	assert.Error(t, setSampledMetric(ms, sdkCacheSampler{}, "verb=PUT", "requestsDelta", "not a number", metric.DELTA, 0))
+}
+
+type fakeSampler struct {
+	previous   map[string]float64
+	startTimes []float64
+}
+
+func (s *fakeSampler) Sample(key string, value float64, _ metric.SourceType, startTime float64) (float64, error) {
+	s.startTimes = append(s.startTimes, startTime)
+	previous, ok := s.previous[key]
+	s.previous[key] = value
+	if !ok {
+		return 0, ErrNoPreviousSample
+	}
+	return value - previous, nil
+}
+
+func TestNewIntegrationProtocol2PopulateFunc_WithCounterSampler(t *testing.T) {
+	specs := SpecGroups{
+		"test": SpecGroup{
+			TypeGenerator: fromGroupEntityTypeGuessFunc,
+			StartTime:     FromRaw("process_start_time"),
+			Specs: []Spec{
+				{"metric_1", FromRaw("raw_metric_name_1"), metric.GAUGE, false},
+				{"metric_2", FromRaw("raw_metric_name_2"), metric.DELTA, false},
+			},
+		},
+	}
+	sampler := &fakeSampler{previous: make(map[string]float64)}
+
+	populate := func(value int) metric.MetricSet {
+		integration, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+		require.NoError(t, err)
+
+		rawGroups := RawGroups{
+			"test": {
+				"entity_id_1": RawMetrics{"raw_metric_name_1": 1, "raw_metric_name_2": value, "process_start_time": 1600000000},
+			},
+		}
+		populated, errs := NewIntegrationProtocol2PopulateFunc(
+			integration,
+			defaultNS,
+			&version.Info{GitVersion: "v1.15.42"},
+			fromGroupMetricSetTypeGuessFunc,
+			WithCounterSampler(sampler),
+		)(rawGroups, specs)
+		assert.True(t, populated)
+		assert.Empty(t, errs)
+		return integration.Data[0].Metrics[0]
+	}
+
+	// The first time there is no previous value, so the metric is not reported.
+	ms := populate(10)
+	assert.Equal(t, 1, ms["metric_1"])
+	assert.NotContains(t, ms, "metric_2")
+
+	ms = populate(15)
+	assert.Equal(t, float64(5), ms["metric_2"])
+	assert.Equal(t, []float64{1600000000, 1600000000}, sampler.startTimes)
+}
+
+func TestNewIntegrationProtocol2PopulateFunc_CounterKey(t *testing.T) {
+	// One metric set per controller, all of them in the same entity.
+	specs := SpecGroups{
+		"workqueue": SpecGroup{
+			IDGenerator: func(_, _ string, _ RawGroups) (string, error) {
+				return "controller-manager", nil
+			},
+			TypeGenerator: fromGroupEntityTypeGuessFunc,
+			CounterKey:    FromRaw("name"),
+			Specs: []Spec{
+				{"controllerName", FromRaw("name"), metric.ATTRIBUTE, false},
+				{"addsPerSecond", FromRaw("adds_total"), metric.RATE, false},
+			},
+		},
+	}
+	sampler := &fakeSampler{previous: make(map[string]float64)}
+
+	populate := func(deployment, job int) map[interface{}]interface{} {
+		integration, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+		require.NoError(t, err)
+
+		rawGroups := RawGroups{
+			"workqueue": {
+				"controller-manager_deployment": RawMetrics{"name": "deployment", "adds_total": deployment},
+				"controller-manager_job":        RawMetrics{"name": "job", "adds_total": job},
+			},
+		}
+		_, errs := NewIntegrationProtocol2PopulateFunc(
+			integration,
+			defaultNS,
+			&version.Info{GitVersion: "v1.15.42"},
+			fromGroupMetricSetTypeGuessFunc,
+			WithCounterSampler(sampler),
+		)(rawGroups, specs)
+		assert.Empty(t, errs)
+
+		// The second entity is the cluster.
+		require.Len(t, integration.Data, 2)
+		adds := make(map[interface{}]interface{})
+		for _, ms := range integration.Data[0].Metrics {
+			adds[ms["controllerName"]] = ms["addsPerSecond"]
+		}
+		return adds
+	}
+
+	populate(100, 7)
+	assert.Equal(t, map[interface{}]interface{}{"deployment": float64(20), "job": float64(0)}, populate(120, 7))
+	assert.Equal(t, map[interface{}]interface{}{"deployment": float64(0), "job": float64(5)}, populate(120, 12))
+}
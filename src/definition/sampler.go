@@ -0,0 +1,48 @@
+package definition
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/cache"
+	"github.com/newrelic/infra-integrations-sdk/metric"
+)
+
+// ErrNoPreviousSample is returned by a CounterSampler when there is no
+// previous value of a counter to compute its DELTA or RATE from.
+var ErrNoPreviousSample = errors.New("no previous sample")
+
+// CounterSampler computes the values of DELTA and RATE metrics from the
+// current values of their counters.
+type CounterSampler interface {
+	// Sample returns the DELTA or RATE of the counter identified by key since
+	// its previous value. startTime is the start time, in seconds since the
+	// epoch, of the process exposing the counter, or 0 if it is unknown.
+	Sample(key string, value float64, sourceType metric.SourceType, startTime float64) (float64, error)
+}
+
+// sdkCacheSampler samples the counters with the global cache of the SDK, the
+// same way the SDK does for the DELTA and RATE metrics of a metric set.
+type sdkCacheSampler struct{}
+
+func (sdkCacheSampler) Sample(key string, value float64, sourceType metric.SourceType, _ float64) (float64, error) {
+	oldValue, oldTime, ok := cache.Get(key)
+	newTime := cache.Set(key, value)
+	if !ok {
+		return 0, nil
+	}
+
+	duration := newTime - oldTime
+	if duration == 0 {
+		return 0, fmt.Errorf("samples for %s are too close in time, skipping sampling", key)
+	}
+
+	if value-oldValue < 0 {
+		return 0, fmt.Errorf("source for %s was reset, skipping sampling", key)
+	}
+
+	if sourceType == metric.DELTA {
+		return value - oldValue, nil
+	}
+	return (value - oldValue) / float64(duration), nil
+}
@@ -23,6 +23,15 @@ type SpecGroup struct {
 	IDGenerator   EntityIDGeneratorFunc
 	TypeGenerator EntityTypeGeneratorFunc
 	Specs         []Spec
+	// StartTime optionally fetches the start time, in seconds since the
+	// epoch, of the process exposing the counters of the entity. It allows
+	// a CounterSampler to detect the counters being reset by a restart.
+	StartTime FetchFunc
+	// CounterKey optionally fetches the value identifying each metric set
+	// of the group, when an entity has several of the same type, e.g. one
+	// per controller. The counters of their DELTA and RATE metrics are then
+	// kept apart instead of being shared by entity and event type.
+	CounterKey FetchFunc
 }
 
 // SpecGroups is a map of groups indexed by group name.
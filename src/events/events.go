@@ -0,0 +1,198 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/data"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
+	"github.com/newrelic/nri-kubernetes/src/redact"
+	"github.com/newrelic/nri-kubernetes/src/storage"
+)
+
+const storageKey = "events"
+
+// Category is the category of the reported events.
+const Category = "kubernetes"
+
+// Filter selects the events to report by their type, e.g. Warning, and
+// reason, e.g. FailedScheduling. Empty lists match every event.
+type Filter struct {
+	Types          []string
+	Reasons        []string
+	ExcludeReasons []string
+}
+
+// Match returns whether the event is selected by the filter. The excluded
+// reasons take precedence over the included ones.
+func (f Filter) Match(event v1.Event) bool {
+	if len(f.Types) > 0 && !contains(f.Types, event.Type) {
+		return false
+	}
+	if contains(f.ExcludeReasons, event.Reason) {
+		return false
+	}
+	return len(f.Reasons) == 0 || contains(f.Reasons, event.Reason)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// state is what is kept between runs in the storage.
+// Its fields must be public to make them visible for the JSON Marshaller.
+type state struct {
+	ResourceVersion string
+}
+
+// Job reports the Kubernetes events as events of the entities of their
+// involved objects. The resource version of the last reported event is kept
+// in a storage.Storage, so the events listed in several runs are reported
+// once. The events created or updated before the first run are not reported.
+type Job struct {
+	Name       string
+	k8sClient  client.Kubernetes
+	storage    storage.Storage
+	filter     Filter
+	namespaces *namespace.Filter
+	redactor   *redact.Redactor
+	logger     *logrus.Logger
+}
+
+// NewJob returns a Job listing the events in the API server, and reporting
+// the ones selected by the filter whose involved object doesn't belong to a
+// namespace filtered out by namespaces, unless it is nil. The summaries of
+// the events are masked with the rules of redactor, unless it is nil.
+func NewJob(
+	k8sClient client.Kubernetes,
+	storage storage.Storage,
+	filter Filter,
+	namespaces *namespace.Filter,
+	redactor *redact.Redactor,
+	logger *logrus.Logger,
+) *Job {
+	return &Job{
+		Name:       "events",
+		k8sClient:  k8sClient,
+		storage:    storage,
+		filter:     filter,
+		namespaces: namespaces,
+		redactor:   redactor,
+		logger:     logger,
+	}
+}
+
+// Populate adds the events created or updated since the last run to the
+// entities of their involved objects.
+func (j *Job) Populate(integration *sdk.IntegrationProtocol2, clusterName string) data.PopulateResult {
+	list, err := j.k8sClient.ListEvents()
+	if err != nil {
+		return data.PopulateResult{
+			Errors:    []error{fmt.Errorf("error listing the events: %s", err)},
+			Populated: false,
+		}
+	}
+
+	var last state
+	if _, err := j.storage.Read(storageKey, &last); err != nil {
+		j.logger.Debugf("No previous events run, only the next events will be reported: %s", err)
+	}
+
+	events := list.Items
+	sort.Slice(events, func(a, b int) bool {
+		return newer(events[b].ResourceVersion, events[a].ResourceVersion)
+	})
+
+	var errs []error
+	populated := false
+	current := last
+	for _, event := range events {
+		if !newer(event.ResourceVersion, current.ResourceVersion) {
+			continue
+		}
+		current.ResourceVersion = event.ResourceVersion
+
+		// The first run only records where the events are.
+		if last.ResourceVersion == "" || !j.matches(event) {
+			continue
+		}
+
+		name, entityType := entityOf(clusterName, event.InvolvedObject)
+		e, err := integration.Entity(name, entityType)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error creating the entity of event %s/%s: %s", event.Namespace, event.Name, err))
+			continue
+		}
+		s := summary(event)
+		if j.redactor != nil {
+			s = j.redactor.RedactString(s, entityType)
+		}
+		if err := e.AddEvent(sdk.Event{Summary: s, Category: Category}); err != nil {
+			errs = append(errs, fmt.Errorf("error adding event %s/%s: %s", event.Namespace, event.Name, err))
+			continue
+		}
+		populated = true
+	}
+
+	if j.redactor != nil {
+		if counts := j.redactor.Flush(); counts.Total() > 0 {
+			j.logger.Debugf("Job %s: redacted %s", j.Name, counts)
+		}
+	}
+
+	if current != last {
+		if err := j.storage.Write(storageKey, current); err != nil {
+			errs = append(errs, fmt.Errorf("error storing the resource version of the events: %s", err))
+		}
+	}
+
+	return data.PopulateResult{Errors: errs, Populated: populated}
+}
+
+func (j *Job) matches(event v1.Event) bool {
+	if ns := event.InvolvedObject.Namespace; ns != "" && j.namespaces != nil && !j.namespaces.Match(ns) {
+		return false
+	}
+	return j.filter.Match(event)
+}
+
+// newer returns whether the resource version a is newer than b. Resource
+// versions are opaque, but they are increasing integers in practice.
+func newer(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) > len(b)
+	}
+	return a > b
+}
+
+// entityOf returns the name and type of the entity of an involved object,
+// which are the ones of the entities populated from its samples, e.g.
+// k8s:<clusterName>:<namespace>:pod.
+func entityOf(clusterName string, object v1.ObjectReference) (string, string) {
+	kind := strings.ToLower(object.Kind)
+	if object.Namespace == "" || kind == "node" || kind == "namespace" {
+		return object.Name, fmt.Sprintf("k8s:%s:%s", clusterName, kind)
+	}
+	return object.Name, fmt.Sprintf("k8s:%s:%s:%s", clusterName, object.Namespace, kind)
+}
+
+// summary returns the summary of the reported event, e.g. "Warning
+// BackOff: Back-off restarting failed container (x5)".
+func summary(event v1.Event) string {
+	s := fmt.Sprintf("%s %s: %s", event.Type, event.Reason, strings.TrimSpace(event.Message))
+	if event.Count > 1 {
+		s = fmt.Sprintf("%s (x%d)", s, event.Count)
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
+	"github.com/newrelic/nri-kubernetes/src/redact"
+	"github.com/newrelic/nri-kubernetes/src/storage"
+)
+
+func event(resourceVersion, eventType, reason, kind, ns, name string) v1.Event {
+	return v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name + "." + resourceVersion, Namespace: ns, ResourceVersion: resourceVersion},
+		InvolvedObject: v1.ObjectReference{Kind: kind, Namespace: ns, Name: name},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " message",
+		Count:          1,
+	}
+}
+
+func summaries(t *testing.T, i *sdk.IntegrationProtocol2) map[string][]string {
+	s := map[string][]string{}
+	for _, e := range i.Data {
+		for _, ev := range e.Events {
+			assert.Equal(t, Category, ev.Category)
+			key := e.Entity.Type + "/" + e.Entity.Name
+			s[key] = append(s[key], ev.Summary)
+		}
+	}
+	return s
+}
+
+func newIntegration(t *testing.T) *sdk.IntegrationProtocol2 {
+	i, err := sdk.NewIntegrationProtocol2("test", "test", new(struct{}))
+	require.NoError(t, err)
+	return i
+}
+
+func TestJob_Populate(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test_events")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	store := storage.NewJSONDiskStorage(tmpDir)
+
+	first := new(client.MockedKubernetes)
+	first.On("ListEvents").Return(&v1.EventList{Items: []v1.Event{
+		event("100", "Warning", "BackOff", "Pod", "default", "nginx-a"),
+	}}, nil)
+
+	// The first run only records the resource version of the last event.
+	i := newIntegration(t)
+	result := NewJob(first, store, Filter{}, nil, nil, logrus.New()).Populate(i, "test-cluster")
+	assert.Empty(t, result.Errors)
+	assert.False(t, result.Populated)
+	assert.Empty(t, summaries(t, i))
+
+	backOff := event("250", "Warning", "BackOff", "Pod", "default", "nginx-a")
+	backOff.Count = 5
+	second := new(client.MockedKubernetes)
+	second.On("ListEvents").Return(&v1.EventList{Items: []v1.Event{
+		event("1000", "Warning", "FailedScheduling", "Pod", "ci-42", "build"),
+		backOff,
+		event("100", "Warning", "BackOff", "Pod", "default", "nginx-a"),
+		event("300", "Normal", "Scheduled", "Pod", "default", "nginx-b"),
+		event("400", "Warning", "NodeNotReady", "Node", "", "worker-1"),
+		event("500", "Warning", "FailedMount", "Pod", "default", "redis-0"),
+	}}, nil)
+
+	filter := Filter{Types: []string{"Warning"}, ExcludeReasons: []string{"FailedMount"}}
+	namespaces, err := namespace.NewFilter(namespace.Config{ExcludeRegex: "^ci-"}, nil)
+	require.NoError(t, err)
+
+	i = newIntegration(t)
+	result = NewJob(second, store, filter, namespaces, nil, logrus.New()).Populate(i, "test-cluster")
+	assert.Empty(t, result.Errors)
+	assert.True(t, result.Populated)
+	assert.Equal(t, map[string][]string{
+		"k8s:test-cluster:default:pod/nginx-a": {"Warning BackOff: BackOff message (x5)"},
+		"k8s:test-cluster:node/worker-1":       {"Warning NodeNotReady: NodeNotReady message"},
+	}, summaries(t, i))
+
+	// The same events are not reported again.
+	i = newIntegration(t)
+	result = NewJob(second, store, filter, namespaces, nil, logrus.New()).Populate(i, "test-cluster")
+	assert.Empty(t, result.Errors)
+	assert.False(t, result.Populated)
+	assert.Empty(t, summaries(t, i))
+}
+
+func TestJob_PopulateRedacted(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test_events")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	store := storage.NewJSONDiskStorage(tmpDir)
+	require.NoError(t, store.Write(storageKey, state{ResourceVersion: "100"}))
+
+	failed := event("200", "Warning", "Failed", "Pod", "default", "nginx")
+	failed.Message = `Failed to pull image "registry.example.com/nginx": login as jane@example.com denied`
+	k8sClient := new(client.MockedKubernetes)
+	k8sClient.On("ListEvents").Return(&v1.EventList{Items: []v1.Event{failed}}, nil)
+
+	redactor, err := redact.NewRedactor(redact.Rules{Mask: []string{`[[:alnum:]._%+-]+@[[:alnum:].-]+`}})
+	require.NoError(t, err)
+
+	i := newIntegration(t)
+	result := NewJob(k8sClient, store, Filter{}, nil, redactor, logrus.New()).Populate(i, "test-cluster")
+	assert.Empty(t, result.Errors)
+	assert.True(t, result.Populated)
+	assert.Equal(t, map[string][]string{
+		"k8s:test-cluster:default:pod/nginx": {`Warning Failed: Failed to pull image "registry.example.com/nginx": login as [REDACTED] denied`},
+	}, summaries(t, i))
+}
+
+func TestJob_PopulateError(t *testing.T) {
+	c := new(client.MockedKubernetes)
+	c.On("ListEvents").Return(&v1.EventList{}, errors.New("forbidden"))
+
+	result := NewJob(c, storage.NewJSONDiskStorage(os.TempDir()), Filter{}, nil, nil, logrus.New()).Populate(newIntegration(t), "test-cluster")
+	assert.False(t, result.Populated)
+	assert.Len(t, result.Errors, 1)
+}
+
+func TestFilter_Match(t *testing.T) {
+	backOff := event("1", "Warning", "BackOff", "Pod", "default", "nginx")
+	scheduled := event("2", "Normal", "Scheduled", "Pod", "default", "nginx")
+
+	assert.True(t, Filter{}.Match(backOff))
+	assert.True(t, Filter{Types: []string{"Warning"}}.Match(backOff))
+	assert.False(t, Filter{Types: []string{"Warning"}}.Match(scheduled))
+	assert.True(t, Filter{Reasons: []string{"BackOff", "FailedMount"}}.Match(backOff))
+	assert.False(t, Filter{Reasons: []string{"FailedMount"}}.Match(backOff))
+	assert.False(t, Filter{Reasons: []string{"BackOff"}, ExcludeReasons: []string{"BackOff"}}.Match(backOff))
+}
+
+func TestEntityOf(t *testing.T) {
+	for _, c := range []struct {
+		object       v1.ObjectReference
+		name, entity string
+	}{
+		{v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "nginx"}, "nginx", "k8s:c:default:pod"},
+		{v1.ObjectReference{Kind: "Deployment", Namespace: "default", Name: "nginx"}, "nginx", "k8s:c:default:deployment"},
+		{v1.ObjectReference{Kind: "Node", Name: "worker-1"}, "worker-1", "k8s:c:node"},
+		{v1.ObjectReference{Kind: "Namespace", Namespace: "default", Name: "default"}, "default", "k8s:c:namespace"},
+	} {
+		name, entityType := entityOf("c", c.object)
+		assert.Equal(t, c.name, name)
+		assert.Equal(t, c.entity, entityType)
+	}
+}
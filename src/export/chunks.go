@@ -0,0 +1,112 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+)
+
+// ClusterEntityType is the type of the entity of the cluster, which the
+// Chunker publishes in a dedicated chunk.
+const ClusterEntityType = "k8s:cluster"
+
+// payload has the fields of sdk.IntegrationProtocol2 which are marshalled,
+// so every chunk is a valid protocol v2 document.
+type payload struct {
+	Name               string            `json:"name"`
+	ProtocolVersion    string            `json:"protocol_version"`
+	IntegrationVersion string            `json:"integration_version"`
+	Data               []*sdk.EntityData `json:"data"`
+}
+
+// Chunk is a marshalled protocol v2 payload with part of the entities of a
+// populated integration.
+type Chunk struct {
+	Entities int
+	Payload  []byte
+}
+
+// Chunker splits the entities of a populated integration in payloads with
+// at most MaxEntities entities and MaxBytes bytes each. Zero values disable
+// the limits. An entity bigger than MaxBytes is sent alone in its chunk.
+//
+// The cluster entity is sent in a dedicated first chunk, so it is reported
+// once per run whatever the number of chunks.
+type Chunker struct {
+	MaxEntities int
+	MaxBytes    int
+}
+
+// Split returns the chunks of the given integration. An integration without
+// entities returns a single chunk with empty data.
+func (c *Chunker) Split(i *sdk.IntegrationProtocol2) ([]Chunk, error) {
+	header := payload{
+		Name:               i.Name,
+		ProtocolVersion:    i.ProtocolVersion,
+		IntegrationVersion: i.IntegrationVersion,
+		Data:               []*sdk.EntityData{},
+	}
+	empty, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusterEntities, entities []*sdk.EntityData
+	for _, e := range i.Data {
+		if e.Entity.Type == ClusterEntityType {
+			clusterEntities = append(clusterEntities, e)
+			continue
+		}
+		entities = append(entities, e)
+	}
+
+	var groups [][]*sdk.EntityData
+	if len(clusterEntities) > 0 {
+		groups = append(groups, clusterEntities)
+	}
+
+	var group []*sdk.EntityData
+	size := len(empty)
+	for _, e := range entities {
+		marshalled, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling entity %s: %s", e.Entity.Name, err)
+		}
+
+		// Entities are separated by a comma in the data array.
+		entitySize := len(marshalled)
+		if len(group) > 0 {
+			entitySize++
+		}
+
+		full := c.MaxEntities > 0 && len(group) >= c.MaxEntities
+		tooBig := c.MaxBytes > 0 && size+entitySize > c.MaxBytes
+		if len(group) > 0 && (full || tooBig) {
+			groups = append(groups, group)
+			group = nil
+			size = len(empty)
+			entitySize = len(marshalled)
+		}
+
+		group = append(group, e)
+		size += entitySize
+	}
+	if len(group) > 0 || len(groups) == 0 {
+		groups = append(groups, group)
+	}
+
+	chunks := make([]Chunk, 0, len(groups))
+	for _, g := range groups {
+		p := header
+		if g != nil {
+			p.Data = g
+		}
+		marshalled, err := json.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling to JSON: %s", err)
+		}
+		chunks = append(chunks, Chunk{Entities: len(g), Payload: marshalled})
+	}
+	return chunks, nil
+}
@@ -0,0 +1,109 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chunkedIntegration(t *testing.T, pods int) *sdk.IntegrationProtocol2 {
+	i, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+
+	for n := 0; n < pods; n++ {
+		e, err := i.Entity(fmt.Sprintf("default:nginx-%d", n), "k8s:playground:pod")
+		require.NoError(t, err)
+		ms := e.NewMetricSet("K8sPodSample")
+		require.NoError(t, ms.SetMetric("podName", fmt.Sprintf("nginx-%d", n), metric.ATTRIBUTE))
+		require.NoError(t, ms.SetMetric("restartCount", n, metric.GAUGE))
+
+		// The cluster entity is created by the first job, amid the others.
+		if n == 1 {
+			c, err := i.Entity("playground", ClusterEntityType)
+			require.NoError(t, err)
+			ms := c.NewMetricSet("K8sClusterSample")
+			require.NoError(t, ms.SetMetric("clusterName", "playground", metric.ATTRIBUTE))
+		}
+	}
+	return i
+}
+
+// unmarshalChunks checks every chunk is a protocol v2 document and returns
+// the names of the entities of each one.
+func unmarshalChunks(t *testing.T, chunks []Chunk) [][]string {
+	var names [][]string
+	for _, c := range chunks {
+		var p payload
+		require.NoError(t, json.Unmarshal(c.Payload, &p))
+		assert.Equal(t, "nr.test", p.Name)
+		assert.Equal(t, "2", p.ProtocolVersion)
+		assert.Equal(t, "1.0.0", p.IntegrationVersion)
+		require.NotNil(t, p.Data)
+		assert.Len(t, p.Data, c.Entities)
+
+		chunkNames := []string{}
+		for _, e := range p.Data {
+			chunkNames = append(chunkNames, e.Entity.Name)
+		}
+		names = append(names, chunkNames)
+	}
+	return names
+}
+
+func TestChunker_SplitByEntities(t *testing.T) {
+	chunks, err := (&Chunker{MaxEntities: 2}).Split(chunkedIntegration(t, 5))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"playground"},
+		{"default:nginx-0", "default:nginx-1"},
+		{"default:nginx-2", "default:nginx-3"},
+		{"default:nginx-4"},
+	}, unmarshalChunks(t, chunks))
+}
+
+func TestChunker_SplitBySize(t *testing.T) {
+	i := chunkedIntegration(t, 20)
+	unlimited, err := (&Chunker{}).Split(i)
+	require.NoError(t, err)
+	require.Len(t, unlimited, 2)
+
+	maxBytes := len(unlimited[1].Payload) / 3
+	chunks, err := (&Chunker{MaxBytes: maxBytes}).Split(i)
+	require.NoError(t, err)
+
+	names := unmarshalChunks(t, chunks)
+	assert.True(t, len(chunks) > 3)
+	assert.Equal(t, []string{"playground"}, names[0])
+
+	entities := 0
+	for _, c := range chunks {
+		assert.True(t, len(c.Payload) <= maxBytes, "chunk of %d bytes", len(c.Payload))
+		entities += c.Entities
+	}
+	assert.Equal(t, 21, entities)
+}
+
+func TestChunker_SplitEntityBiggerThanLimit(t *testing.T) {
+	chunks, err := (&Chunker{MaxBytes: 10}).Split(chunkedIntegration(t, 2))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"playground"},
+		{"default:nginx-0"},
+		{"default:nginx-1"},
+	}, unmarshalChunks(t, chunks))
+}
+
+func TestChunker_SplitEmpty(t *testing.T) {
+	chunks, err := (&Chunker{MaxEntities: 2}).Split(chunkedIntegration(t, 0))
+	require.NoError(t, err)
+
+	require.Len(t, chunks, 1)
+	assert.Equal(t, `{"name":"nr.test","protocol_version":"2","integration_version":"1.0.0","data":[]}`, string(chunks[0].Payload))
+}
@@ -0,0 +1,194 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+)
+
+const (
+	ndjsonFilePerm   = 0644
+	ndjsonFolderPerm = 0755
+	backupTimeFormat = "20060102T150405.000000000"
+)
+
+// ndjsonLine is a line of the file written by NDJSONSink.
+// Its fields must be public to make them visible for the JSON Marshaller.
+type ndjsonLine struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	EntityName string                 `json:"entityName"`
+	EntityType string                 `json:"entityType"`
+	EventType  string                 `json:"eventType"`
+	Metrics    map[string]interface{} `json:"metrics"`
+}
+
+// NDJSONSink appends the metric sets of the populated entities to a file, as
+// one JSON object per line. The file is rotated before it grows past MaxSize
+// bytes, or once its first line is older than RotationInterval, and the
+// rotated files are removed once they were rotated longer than MaxAge ago.
+// Zero values disable the limits.
+type NDJSONSink struct {
+	Path             string
+	MaxSize          int64
+	RotationInterval time.Duration
+	MaxAge           time.Duration
+}
+
+// Write appends one line per metric set of the given entities, with the given
+// timestamp.
+func (s *NDJSONSink) Write(data []*sdk.EntityData, timestamp time.Time) error {
+	var lines []byte
+	for _, e := range data {
+		for _, ms := range e.Metrics {
+			eventType, _ := ms["event_type"].(string)
+			metrics := make(map[string]interface{}, len(ms))
+			for name, value := range ms {
+				if name != "event_type" {
+					metrics[name] = value
+				}
+			}
+
+			line, err := json.Marshal(ndjsonLine{
+				Timestamp:  timestamp,
+				EntityName: e.Entity.Name,
+				EntityType: e.Entity.Type,
+				EventType:  eventType,
+				Metrics:    metrics,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot marshal metric set %s of entity %s: %s", eventType, e.Entity.Name, err)
+			}
+			lines = append(append(lines, line...), '\n')
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), ndjsonFolderPerm); err != nil {
+		return err
+	}
+
+	if err := s.rotate(int64(len(lines)), timestamp); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, ndjsonFilePerm)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if _, err := w.Write(lines); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rotate renames the file when writing the given number of bytes would grow
+// it past MaxSize, or when it is older than RotationInterval, and removes the
+// rotated files older than MaxAge.
+func (s *NDJSONSink) rotate(size int64, now time.Time) error {
+	info, err := os.Stat(s.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil && info.Size() > 0 && s.mustRotate(info.Size()+size, now) {
+		if err := os.Rename(s.Path, s.backupPath(now)); err != nil {
+			return err
+		}
+	}
+
+	if s.MaxAge <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(s.backupPattern())
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		rotated, ok := s.backupTime(backup)
+		if !ok {
+			// Other files matching the pattern, e.g. metrics-old.ndjson.
+			continue
+		}
+		if now.Sub(rotated) > s.MaxAge {
+			if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mustRotate returns whether the file must be rotated before it grows to the
+// given size.
+func (s *NDJSONSink) mustRotate(size int64, now time.Time) bool {
+	if s.MaxSize > 0 && size > s.MaxSize {
+		return true
+	}
+	if s.RotationInterval <= 0 {
+		return false
+	}
+
+	created, err := s.firstTimestamp()
+	return err == nil && now.Sub(created) >= s.RotationInterval
+}
+
+// firstTimestamp returns the timestamp of the first line of the file.
+func (s *NDJSONSink) firstTimestamp() (time.Time, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var first ndjsonLine
+	if err := json.Unmarshal(line, &first); err != nil {
+		return time.Time{}, err
+	}
+	return first.Timestamp, nil
+}
+
+// backupPath returns the path of a file rotated at the given time, e.g.
+// metrics-20201018T213700.000000000.ndjson for metrics.ndjson.
+func (s *NDJSONSink) backupPath(now time.Time) string {
+	ext := filepath.Ext(s.Path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(s.Path, ext), now.UTC().Format(backupTimeFormat), ext)
+}
+
+func (s *NDJSONSink) backupPattern() string {
+	ext := filepath.Ext(s.Path)
+	return fmt.Sprintf("%s-*%s", strings.TrimSuffix(s.Path, ext), ext)
+}
+
+// backupTime returns the time a file was rotated at, from its path, and false
+// if the path isn't one returned by backupPath.
+func (s *NDJSONSink) backupTime(path string) (time.Time, bool) {
+	ext := filepath.Ext(s.Path)
+	prefix := strings.TrimSuffix(s.Path, ext) + "-"
+	if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, ext) {
+		return time.Time{}, false
+	}
+
+	rotated, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(path, prefix), ext))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return rotated, true
+}
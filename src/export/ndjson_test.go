@@ -0,0 +1,107 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readLines(t *testing.T, path string) []ndjsonLine {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines []ndjsonLine
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line ndjsonLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestNDJSONSink_Write(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test_ndjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	sink := &NDJSONSink{Path: filepath.Join(tmpDir, "archive", "metrics.ndjson")}
+	timestamp := time.Unix(1600000000, 0).UTC()
+	require.NoError(t, sink.Write(testEntities(t), timestamp))
+	require.NoError(t, sink.Write(testEntities(t), timestamp.Add(15*time.Second)))
+
+	lines := readLines(t, sink.Path)
+	require.Len(t, lines, 2)
+	assert.Equal(t, ndjsonLine{
+		Timestamp:  timestamp,
+		EntityName: "default:nginx",
+		EntityType: "k8s:playground:deployment",
+		EventType:  "K8sDeploymentSample",
+		Metrics: map[string]interface{}{
+			"entityName":     "k8s:playground:deployment:default:nginx",
+			"deploymentName": "nginx",
+			"namespace":      "default",
+			"podsMissing":    float64(2),
+			"podsDesired":    float64(3),
+		},
+	}, lines[0])
+	assert.Equal(t, timestamp.Add(15*time.Second), lines[1].Timestamp)
+}
+
+func TestNDJSONSink_Rotation(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test_ndjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	sink := &NDJSONSink{Path: filepath.Join(tmpDir, "metrics.ndjson"), MaxSize: 300, MaxAge: time.Hour}
+	timestamp := time.Unix(1600000000, 0)
+
+	// The second run doesn't fit in the size limit, so the file is rotated first.
+	require.NoError(t, sink.Write(testEntities(t), timestamp))
+	require.NoError(t, sink.Write(testEntities(t), timestamp.Add(time.Minute)))
+
+	backup := filepath.Join(tmpDir, "metrics-20200913T122740.000000000.ndjson")
+	assert.Len(t, readLines(t, backup), 1)
+	assert.Len(t, readLines(t, sink.Path), 1)
+
+	// Files which were not rotated by the sink are kept.
+	unrelated := filepath.Join(tmpDir, "metrics-old.ndjson")
+	require.NoError(t, ioutil.WriteFile(unrelated, nil, ndjsonFilePerm))
+
+	// Rotated files are removed once they were rotated longer than the age
+	// limit ago.
+	require.NoError(t, sink.Write(testEntities(t), timestamp.Add(time.Minute+2*time.Hour)))
+
+	_, err = os.Stat(backup)
+	assert.True(t, os.IsNotExist(err))
+	assert.FileExists(t, filepath.Join(tmpDir, "metrics-20200913T142740.000000000.ndjson"))
+	assert.FileExists(t, unrelated)
+}
+
+func TestNDJSONSink_RotationInterval(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test_ndjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	sink := &NDJSONSink{Path: filepath.Join(tmpDir, "metrics.ndjson"), RotationInterval: time.Hour}
+	timestamp := time.Unix(1600000000, 0)
+
+	require.NoError(t, sink.Write(testEntities(t), timestamp))
+	require.NoError(t, sink.Write(testEntities(t), timestamp.Add(30*time.Minute)))
+	assert.Len(t, readLines(t, sink.Path), 2)
+
+	// The file is rotated once its first line is older than the interval.
+	rotation := timestamp.Add(time.Hour).Add(time.Millisecond)
+	require.NoError(t, sink.Write(testEntities(t), rotation))
+	assert.Len(t, readLines(t, sink.Path), 1)
+	assert.Len(t, readLines(t, filepath.Join(tmpDir, "metrics-20200913T132640.001000000.ndjson")), 2)
+}
@@ -0,0 +1,264 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// OTLPProtocolGRPC exports the metrics with OTLP/gRPC.
+	OTLPProtocolGRPC = "grpc"
+	// OTLPProtocolHTTP exports the metrics with OTLP/HTTP, encoded as protobuf.
+	OTLPProtocolHTTP = "http"
+
+	otlpHTTPPath = "/v1/metrics"
+
+	otlpScopeName = "github.com/newrelic/nri-kubernetes"
+)
+
+// resourceAttributes maps the attributes of the metric sets which identify
+// Kubernetes resources to the OpenTelemetry semantic conventions.
+var resourceAttributes = map[string]string{
+	"clusterName":     "k8s.cluster.name",
+	"namespace":       "k8s.namespace.name",
+	"namespaceName":   "k8s.namespace.name",
+	"nodeName":        "k8s.node.name",
+	"podName":         "k8s.pod.name",
+	"containerName":   "k8s.container.name",
+	"containerID":     "container.id",
+	"deploymentName":  "k8s.deployment.name",
+	"replicasetName":  "k8s.replicaset.name",
+	"daemonsetName":   "k8s.daemonset.name",
+	"statefulsetName": "k8s.statefulset.name",
+	"jobName":         "k8s.job.name",
+}
+
+// OTLPExporter exports the entities and metric sets of a populated
+// integration to an OpenTelemetry collector.
+//
+// Every entity becomes an OTLP resource, with the attributes of its metric
+// sets which identify Kubernetes resources as resource attributes following
+// the semantic conventions, e.g. k8s.pod.name. The numeric metrics of the
+// metric sets become gauges named after the event type and metric, e.g.
+// podsMissing of K8sDeploymentSample becomes k8s.deployment.pods_missing,
+// with the rest of the attributes of the metric set as data point
+// attributes. The values which are neither numbers nor strings, like
+// booleans, are also attributes. DELTA and RATE metrics are exported as
+// gauges, as they are already computed for the interval.
+type OTLPExporter struct {
+	endpoint *url.URL
+	protocol string
+	timeout  time.Duration
+	version  string
+}
+
+// NewOTLPExporter returns an OTLPExporter which sends the metrics to the
+// collector at the given endpoint, e.g. http://otel-collector:4318 for
+// OTLP/HTTP or https://otel-collector:4317 for OTLP/gRPC. The scheme
+// determines whether TLS is used. version is reported as the version of the
+// instrumentation scope.
+func NewOTLPExporter(endpoint, protocol string, timeout time.Duration, version string) (*OTLPExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: %s", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: the scheme must be http or https", endpoint)
+	}
+	if protocol != OTLPProtocolGRPC && protocol != OTLPProtocolHTTP {
+		return nil, fmt.Errorf("unknown OTLP protocol %q, expected %s or %s", protocol, OTLPProtocolGRPC, OTLPProtocolHTTP)
+	}
+
+	return &OTLPExporter{
+		endpoint: u,
+		protocol: protocol,
+		timeout:  timeout,
+		version:  version,
+	}, nil
+}
+
+// Export sends the metric sets of the given entities to the collector. It
+// fails if the collector rejects any data point.
+func (e *OTLPExporter) Export(data []*sdk.EntityData) error {
+	request := otlpRequest(data, e.version, time.Now())
+
+	var response *colmetricspb.ExportMetricsServiceResponse
+	var err error
+	if e.protocol == OTLPProtocolGRPC {
+		response, err = e.exportGRPC(request)
+	} else {
+		response, err = e.exportHTTP(request)
+	}
+	if err != nil {
+		return err
+	}
+
+	if rejected := response.GetPartialSuccess().GetRejectedDataPoints(); rejected > 0 {
+		return fmt.Errorf("OTLP collector rejected %d data points: %s", rejected, response.GetPartialSuccess().GetErrorMessage())
+	}
+	return nil
+}
+
+func (e *OTLPExporter) exportHTTP(request *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	payload, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: e.timeout}
+	resp, err := client.Post(strings.TrimSuffix(e.endpoint.String(), "/")+otlpHTTPPath, "application/x-protobuf", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("OTLP collector returned %s: %s", resp.Status, body)
+	}
+
+	response := &colmetricspb.ExportMetricsServiceResponse{}
+	if resp.Header.Get("Content-Type") == "application/x-protobuf" {
+		if err := proto.Unmarshal(body, response); err != nil {
+			return nil, fmt.Errorf("invalid OTLP collector response: %s", err)
+		}
+	}
+	return response, nil
+}
+
+func (e *OTLPExporter) exportGRPC(request *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	transportCredentials := insecure.NewCredentials()
+	if e.endpoint.Scheme == "https" {
+		transportCredentials = credentials.NewTLS(&tls.Config{})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, e.endpoint.Host, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return colmetricspb.NewMetricsServiceClient(conn).Export(ctx, request, grpc.UseCompressor(gzip.Name))
+}
+
+// otlpRequest converts the metric sets of the given entities into an OTLP
+// metrics export request, with the given timestamp for all the data points.
+func otlpRequest(data []*sdk.EntityData, version string, timestamp time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	request := &colmetricspb.ExportMetricsServiceRequest{}
+	for _, e := range data {
+		resource := &resourcepb.Resource{}
+		resourceAttributeKeys := make(map[string]bool)
+
+		metrics := make(map[string]*metricspb.Metric)
+		var names []string
+		for _, ms := range e.Metrics {
+			eventType, ok := ms["event_type"].(string)
+			if !ok {
+				continue
+			}
+			prefix := strings.Replace(metricSetPrefix(eventType), "_", ".", -1)
+
+			var attributes []*commonpb.KeyValue
+			values := make(map[string]float64)
+			for name, value := range ms {
+				if name == "event_type" {
+					continue
+				}
+
+				switch v := value.(type) {
+				case string:
+					if key, ok := resourceAttributes[name]; ok {
+						// The first value of a resource attribute is kept.
+						if !resourceAttributeKeys[key] {
+							resourceAttributeKeys[key] = true
+							resource.Attributes = append(resource.Attributes, stringKeyValue(key, v))
+						}
+						continue
+					}
+					attributes = append(attributes, stringKeyValue(name, v))
+				case bool:
+					attributes = append(attributes, &commonpb.KeyValue{
+						Key:   name,
+						Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}},
+					})
+				default:
+					f, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64)
+					if err != nil {
+						attributes = append(attributes, stringKeyValue(name, fmt.Sprintf("%v", value)))
+						continue
+					}
+					values[name] = f
+				}
+			}
+			sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+
+			for name, value := range values {
+				metricName := prefix + "." + snakeCase(name)
+				m, ok := metrics[metricName]
+				if !ok {
+					m = &metricspb.Metric{
+						Name:        metricName,
+						Description: fmt.Sprintf("%s of %s.", name, eventType),
+						Data:        &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}},
+					}
+					metrics[metricName] = m
+					names = append(names, metricName)
+				}
+				gauge := m.GetGauge()
+				gauge.DataPoints = append(gauge.DataPoints, &metricspb.NumberDataPoint{
+					Attributes:   attributes,
+					TimeUnixNano: uint64(timestamp.UnixNano()),
+					Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+				})
+			}
+		}
+
+		if len(names) == 0 {
+			continue
+		}
+
+		scopeMetrics := &metricspb.ScopeMetrics{
+			Scope: &commonpb.InstrumentationScope{Name: otlpScopeName, Version: version},
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			scopeMetrics.Metrics = append(scopeMetrics.Metrics, metrics[name])
+		}
+		sort.Slice(resource.Attributes, func(i, j int) bool { return resource.Attributes[i].Key < resource.Attributes[j].Key })
+		request.ResourceMetrics = append(request.ResourceMetrics, &metricspb.ResourceMetrics{
+			Resource:     resource,
+			ScopeMetrics: []*metricspb.ScopeMetrics{scopeMetrics},
+		})
+	}
+	return request
+}
+
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
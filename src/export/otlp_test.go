@@ -0,0 +1,205 @@
+package export
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func otlpEntities(t *testing.T) []*sdk.EntityData {
+	i, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+
+	e, err := i.Entity("default:nginx-5d4f", "k8s:playground:pod")
+	require.NoError(t, err)
+
+	ms := e.NewMetricSet("K8sPodSample")
+	require.NoError(t, ms.SetMetric("podName", "nginx-5d4f", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("namespace", "default", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("clusterName", "playground", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("status", "Running", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("restartCount", 3, metric.GAUGE))
+	ms["hostNetwork"] = false
+
+	// Entities without numeric metrics are not exported.
+	_, err = i.Entity("playground", "k8s:cluster")
+	require.NoError(t, err)
+
+	return i.Data
+}
+
+// attributes returns the values of the given attributes by key.
+func attributes(kvs []*commonpb.KeyValue) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, kv := range kvs {
+		switch v := kv.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			values[kv.GetKey()] = v.StringValue
+		case *commonpb.AnyValue_BoolValue:
+			values[kv.GetKey()] = v.BoolValue
+		}
+	}
+	return values
+}
+
+func assertExportRequest(t *testing.T, request *colmetricspb.ExportMetricsServiceRequest) {
+	require.Len(t, request.GetResourceMetrics(), 1)
+
+	resourceMetrics := request.GetResourceMetrics()[0]
+	assert.Equal(t, map[string]interface{}{
+		"k8s.pod.name":       "nginx-5d4f",
+		"k8s.namespace.name": "default",
+		"k8s.cluster.name":   "playground",
+	}, attributes(resourceMetrics.GetResource().GetAttributes()))
+
+	require.Len(t, resourceMetrics.GetScopeMetrics(), 1)
+	scopeMetrics := resourceMetrics.GetScopeMetrics()[0]
+	assert.Equal(t, otlpScopeName, scopeMetrics.GetScope().GetName())
+	assert.Equal(t, "2.4.0", scopeMetrics.GetScope().GetVersion())
+	require.Len(t, scopeMetrics.GetMetrics(), 1)
+
+	m := scopeMetrics.GetMetrics()[0]
+	assert.Equal(t, "k8s.pod.restart_count", m.GetName())
+	require.Len(t, m.GetGauge().GetDataPoints(), 1)
+
+	dp := m.GetGauge().GetDataPoints()[0]
+	assert.Equal(t, float64(3), dp.GetAsDouble())
+	assert.Equal(t, map[string]interface{}{
+		"entityName":  "k8s:playground:pod:default:nginx-5d4f",
+		"status":      "Running",
+		"hostNetwork": false,
+	}, attributes(dp.GetAttributes()))
+}
+
+func TestOTLPRequest(t *testing.T) {
+	payload, err := proto.Marshal(otlpRequest(otlpEntities(t), "2.4.0", time.Unix(1600000000, 0)))
+	require.NoError(t, err)
+
+	request := &colmetricspb.ExportMetricsServiceRequest{}
+	require.NoError(t, proto.Unmarshal(payload, request))
+	assertExportRequest(t, request)
+	assert.Equal(t, uint64(1600000000000000000), request.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetGauge().DataPoints[0].GetTimeUnixNano())
+}
+
+// httpCollector is an OTLP/HTTP collector which decodes the requests like
+// the OpenTelemetry receiver, and answers with the given response.
+func httpCollector(t *testing.T, response *colmetricspb.ExportMetricsServiceResponse, requests chan<- *colmetricspb.ExportMetricsServiceRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+
+		payload, _ := ioutil.ReadAll(r.Body)
+		request := &colmetricspb.ExportMetricsServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(payload, request))
+		requests <- request
+
+		body, err := proto.Marshal(response)
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestOTLPExporter_HTTP(t *testing.T) {
+	requests := make(chan *colmetricspb.ExportMetricsServiceRequest, 1)
+	collector := httpCollector(t, &colmetricspb.ExportMetricsServiceResponse{}, requests)
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL, OTLPProtocolHTTP, time.Second, "2.4.0")
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(otlpEntities(t)))
+
+	assertExportRequest(t, <-requests)
+}
+
+func TestOTLPExporter_HTTPPartialSuccess(t *testing.T) {
+	requests := make(chan *colmetricspb.ExportMetricsServiceRequest, 1)
+	collector := httpCollector(t, &colmetricspb.ExportMetricsServiceResponse{
+		PartialSuccess: &colmetricspb.ExportMetricsPartialSuccess{RejectedDataPoints: 1, ErrorMessage: "invalid"},
+	}, requests)
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL, OTLPProtocolHTTP, time.Second, "2.4.0")
+	require.NoError(t, err)
+	assert.EqualError(t, exporter.Export(otlpEntities(t)), "OTLP collector rejected 1 data points: invalid")
+}
+
+// grpcCollector is an in-process OTLP/gRPC collector over plain text, which
+// answers with the given error, if any.
+type grpcCollector struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	err      error
+	requests chan *colmetricspb.ExportMetricsServiceRequest
+}
+
+func (c *grpcCollector) Export(_ context.Context, request *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	c.requests <- request
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func (c *grpcCollector) start(t *testing.T) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(server, c)
+	go func() { _ = server.Serve(listener) }()
+
+	return "http://" + listener.Addr().String(), server.Stop
+}
+
+func TestOTLPExporter_GRPC(t *testing.T) {
+	// The requests are compressed, so the collector compresses the responses.
+	collector := &grpcCollector{requests: make(chan *colmetricspb.ExportMetricsServiceRequest, 1)}
+	endpoint, stop := collector.start(t)
+	defer stop()
+
+	exporter, err := NewOTLPExporter(endpoint, OTLPProtocolGRPC, time.Second, "2.4.0")
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(otlpEntities(t)))
+
+	assertExportRequest(t, <-collector.requests)
+}
+
+func TestOTLPExporter_GRPCError(t *testing.T) {
+	// Errors are answered in trailers-only responses.
+	collector := &grpcCollector{
+		err:      status.Error(codes.InvalidArgument, "rejected"),
+		requests: make(chan *colmetricspb.ExportMetricsServiceRequest, 1),
+	}
+	endpoint, stop := collector.start(t)
+	defer stop()
+
+	exporter, err := NewOTLPExporter(endpoint, OTLPProtocolGRPC, time.Second, "2.4.0")
+	require.NoError(t, err)
+
+	err = exporter.Export(otlpEntities(t))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "rejected", status.Convert(err).Message())
+}
+
+func TestNewOTLPExporter_InvalidConfig(t *testing.T) {
+	_, err := NewOTLPExporter("otel-collector:4317", OTLPProtocolGRPC, time.Second, "2.4.0")
+	assert.Error(t, err)
+
+	_, err = NewOTLPExporter("http://otel-collector:4317", "thrift", time.Second, "2.4.0")
+	assert.Error(t, err)
+}
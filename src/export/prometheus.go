@@ -0,0 +1,320 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	model "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	infoSuffix    = "info"
+	counterSuffix = "_total"
+)
+
+// PrometheusHandler serves the entities and metric sets of the last populated
+// integration in the Prometheus text exposition format.
+//
+// Every metric set is converted into metric families named after its event
+// type and metrics, e.g. podsMissing of K8sDeploymentSample becomes
+// k8s_deployment_pods_missing. The attributes of the metric set, i.e. its
+// ATTRIBUTE metrics, become the labels of all its samples, and are also
+// exposed as an info metric, e.g. k8s_deployment_info, with value 1. When
+// several attributes get the same label name, e.g. podName and pod_name, only
+// the first one in alphabetical order is kept.
+//
+// The metrics are exposed with the source type of their specs, looked up by
+// the event type of their metric set and their name, as the same name may be
+// used by the specs of several groups. The GAUGE
+// metrics are exposed as gauges, unless they are named like counters, e.g.
+// containerCpuCfsPeriodsTotal, which are exposed as counters. The DELTA and
+// RATE metrics are already computed for the interval, so they are exposed as
+// counters which accumulate the deltas, or the rates over the time elapsed
+// between updates, since the first update which reported them.
+type PrometheusHandler struct {
+	lock   sync.RWMutex
+	body   []byte
+	logger *logrus.Logger
+
+	sourceTypes map[sourceTypeKey]metric.SourceType
+	// counters are the values of the counters accumulated from the DELTA and
+	// RATE metrics, by time-series.
+	counters   map[string]float64
+	lastUpdate time.Time
+}
+
+// sourceTypeKey identifies the spec of a metric by the event type of its
+// metric set and its name.
+type sourceTypeKey struct {
+	eventType string
+	name      string
+}
+
+// NewPrometheusHandler returns a PrometheusHandler which serves no metrics
+// until it is updated. The source types of the metrics are taken from the
+// given spec groups, under the event types which msTypeGuessers give to the
+// metric sets of each group.
+func NewPrometheusHandler(logger *logrus.Logger, msTypeGuessers []definition.GuessFunc, specGroups ...definition.SpecGroups) *PrometheusHandler {
+	sourceTypes := make(map[sourceTypeKey]metric.SourceType)
+	for _, groups := range specGroups {
+		for groupLabel, group := range groups {
+			for _, msTypeGuesser := range msTypeGuessers {
+				eventType, err := msTypeGuesser("", groupLabel, "", nil)
+				if err != nil {
+					logger.WithError(err).Warnf("cannot guess the event type of group %s", groupLabel)
+					continue
+				}
+				for _, spec := range group.Specs {
+					sourceTypes[sourceTypeKey{eventType: eventType, name: spec.Name}] = spec.Type
+				}
+			}
+		}
+	}
+
+	return &PrometheusHandler{
+		logger:      logger,
+		sourceTypes: sourceTypes,
+		counters:    make(map[string]float64),
+	}
+}
+
+// Update replaces the served metrics with the ones of the given entities.
+func (h *PrometheusHandler) Update(data []*sdk.EntityData) {
+	h.update(data, time.Now())
+}
+
+func (h *PrometheusHandler) update(data []*sdk.EntityData, now time.Time) {
+	var elapsed float64
+	if !h.lastUpdate.IsZero() {
+		elapsed = now.Sub(h.lastUpdate).Seconds()
+	}
+	h.lastUpdate = now
+
+	var buf bytes.Buffer
+	for _, family := range h.metricFamilies(data, elapsed) {
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			h.logger.WithError(err).Warnf("cannot expose metric family %s", family.GetName())
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.body = buf.Bytes()
+}
+
+// ServeHTTP implements http.Handler.
+func (h *PrometheusHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	w.Header().Set("Content-Type", string(expfmt.FmtText))
+	if _, err := w.Write(h.body); err != nil {
+		h.logger.WithError(err).Debug("writing the metrics response")
+	}
+}
+
+// metricFamilies converts the metric sets of the given entities into
+// Prometheus metric families, sorted by name. elapsed is the number of
+// seconds since the previous update, used to accumulate the RATE metrics.
+// The counters of the time-series which are not reported are forgotten.
+func (h *PrometheusHandler) metricFamilies(data []*sdk.EntityData, elapsed float64) []*model.MetricFamily {
+	families := make(map[string]*model.MetricFamily)
+	counters := make(map[string]float64)
+	for _, e := range data {
+		for _, ms := range e.Metrics {
+			eventType, ok := ms["event_type"].(string)
+			if !ok {
+				continue
+			}
+			prefix := metricSetPrefix(eventType)
+
+			labels, values, names := splitMetricSet(ms)
+			addSample(families, prefix+"_"+infoSuffix, fmt.Sprintf("Attributes of %s.", eventType), model.MetricType_GAUGE, labels, 1)
+
+			seen := make(map[string]bool, len(names))
+			for _, name := range names {
+				familyName := prefix + "_" + snakeCase(name)
+				if seen[familyName] {
+					continue
+				}
+				seen[familyName] = true
+
+				help := fmt.Sprintf("%s of %s.", name, eventType)
+				value := values[name]
+				switch h.sourceType(eventType, name) {
+				case metric.DELTA:
+					key := seriesKey(familyName, labels)
+					counters[key] = h.counters[key] + value
+					addSample(families, familyName, help, model.MetricType_COUNTER, labels, counters[key])
+				case metric.RATE:
+					key := seriesKey(familyName, labels)
+					counters[key] = h.counters[key] + value*elapsed
+					addSample(families, familyName, help, model.MetricType_COUNTER, labels, counters[key])
+				default:
+					metricType := model.MetricType_GAUGE
+					if strings.HasSuffix(familyName, counterSuffix) {
+						metricType = model.MetricType_COUNTER
+					}
+					addSample(families, familyName, help, metricType, labels, value)
+				}
+			}
+		}
+	}
+	h.counters = counters
+
+	familyNames := make([]string, 0, len(families))
+	for name := range families {
+		familyNames = append(familyNames, name)
+	}
+	sort.Strings(familyNames)
+
+	sorted := make([]*model.MetricFamily, 0, len(familyNames))
+	for _, name := range familyNames {
+		sorted = append(sorted, families[name])
+	}
+	return sorted
+}
+
+// sourceType returns the source type of the spec of the given metric of a
+// metric set with the given event type. The metrics populated from
+// FetchedValues are named after their spec, e.g.
+// apiserverRequestsDelta_verb_GET for apiserverRequestsDelta. Metrics without
+// a known spec are gauges.
+func (h *PrometheusHandler) sourceType(eventType, name string) metric.SourceType {
+	if sourceType, ok := h.sourceTypes[sourceTypeKey{eventType: eventType, name: name}]; ok {
+		return sourceType
+	}
+	if i := strings.Index(name, "_"); i > 0 {
+		if sourceType, ok := h.sourceTypes[sourceTypeKey{eventType: eventType, name: name[:i]}]; ok {
+			return sourceType
+		}
+	}
+	return metric.GAUGE
+}
+
+// seriesKey identifies the time-series of a metric family with the given
+// labels, which are sorted by name.
+func seriesKey(familyName string, labels []*model.LabelPair) string {
+	var b strings.Builder
+	b.WriteString(familyName)
+	for _, l := range labels {
+		fmt.Fprintf(&b, ",%s=%q", l.GetName(), l.GetValue())
+	}
+	return b.String()
+}
+
+// splitMetricSet returns the attributes of the metric set as labels, sorted
+// by name, and its numeric metrics as float64, with their names sorted.
+// Attributes whose label name was already taken by a previous one in
+// alphabetical order are skipped.
+func splitMetricSet(ms map[string]interface{}) ([]*model.LabelPair, map[string]float64, []string) {
+	attributes := make(map[string]string)
+	var attributeNames []string
+	values := make(map[string]float64)
+	var names []string
+	for name, value := range ms {
+		if name == "event_type" {
+			continue
+		}
+
+		if s, ok := value.(string); ok {
+			attributes[name] = s
+			attributeNames = append(attributeNames, name)
+			continue
+		}
+
+		v, err := strconv.ParseFloat(fmt.Sprintf("%v", value), 64)
+		if err != nil {
+			continue
+		}
+		values[name] = v
+		names = append(names, name)
+	}
+	sort.Strings(attributeNames)
+	sort.Strings(names)
+
+	labels := make([]*model.LabelPair, 0, len(attributeNames))
+	taken := make(map[string]bool, len(attributeNames))
+	for _, name := range attributeNames {
+		labelName := snakeCase(name)
+		if taken[labelName] {
+			continue
+		}
+		taken[labelName] = true
+		labels = append(labels, &model.LabelPair{Name: proto.String(labelName), Value: proto.String(attributes[name])})
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+	return labels, values, names
+}
+
+func addSample(families map[string]*model.MetricFamily, name, help string, metricType model.MetricType, labels []*model.LabelPair, value float64) {
+	family, ok := families[name]
+	if !ok {
+		family = &model.MetricFamily{
+			Name: proto.String(name),
+			Help: proto.String(help),
+			Type: metricType.Enum(),
+		}
+		families[name] = family
+	}
+
+	m := &model.Metric{Label: labels}
+	if family.GetType() == model.MetricType_COUNTER {
+		m.Counter = &model.Counter{Value: proto.Float64(value)}
+	} else {
+		m.Gauge = &model.Gauge{Value: proto.Float64(value)}
+	}
+	family.Metric = append(family.Metric, m)
+}
+
+// metricSetPrefix returns the prefix of the metric families of an event
+// type, e.g. k8s_pod for K8sPodSample.
+func metricSetPrefix(eventType string) string {
+	return snakeCase(strings.TrimSuffix(eventType, "Sample"))
+}
+
+// snakeCase converts a camel case name into a valid Prometheus metric or
+// label name in snake case, e.g. podIP becomes pod_ip. Invalid characters
+// are replaced by underscores.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				b.WriteRune('_')
+			}
+		}
+
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	snake := b.String()
+	if snake != "" && unicode.IsDigit(rune(snake[0])) {
+		snake = "_" + snake
+	}
+	return snake
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	model "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntities(t *testing.T) []*sdk.EntityData {
+	i, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+
+	e, err := i.Entity("default:nginx", "k8s:playground:deployment")
+	require.NoError(t, err)
+
+	ms := e.NewMetricSet("K8sDeploymentSample")
+	require.NoError(t, ms.SetMetric("deploymentName", "nginx", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("namespace", "default", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("podsMissing", 2, metric.GAUGE))
+	require.NoError(t, ms.SetMetric("podsDesired", 3, metric.GAUGE))
+
+	return i.Data
+}
+
+// sampleTypeGuesser gives the metric sets of a group an event type named
+// after it, e.g. K8sContainerSample for container.
+func sampleTypeGuesser(_, groupLabel, _ string, _ definition.RawGroups) (string, error) {
+	return "K8s" + strings.Title(groupLabel) + "Sample", nil
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	handler := NewPrometheusHandler(logrus.New(), nil)
+	handler.Update(testEntities(t))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	labels := `{deployment_name="nginx",entity_name="k8s:playground:deployment:default:nginx",namespace="default"}`
+	expected := `# HELP k8s_deployment_info Attributes of K8sDeploymentSample.
+# TYPE k8s_deployment_info gauge
+k8s_deployment_info` + labels + ` 1
+# HELP k8s_deployment_pods_desired podsDesired of K8sDeploymentSample.
+# TYPE k8s_deployment_pods_desired gauge
+k8s_deployment_pods_desired` + labels + ` 3
+# HELP k8s_deployment_pods_missing podsMissing of K8sDeploymentSample.
+# TYPE k8s_deployment_pods_missing gauge
+k8s_deployment_pods_missing` + labels + ` 2
+`
+	assert.Equal(t, expected, string(body))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestPrometheusHandler_Counters(t *testing.T) {
+	handler := NewPrometheusHandler(logrus.New(), []definition.GuessFunc{sampleTypeGuesser}, definition.SpecGroups{
+		"container": {
+			Specs: []definition.Spec{
+				{Name: "restartCountDelta", Type: metric.DELTA},
+				{Name: "net.rxBytesPerSecond", Type: metric.RATE},
+				{Name: "containerCpuCfsPeriodsTotal", Type: metric.GAUGE},
+				{Name: "apiserverRequestsDelta", Type: metric.DELTA},
+			},
+		},
+	})
+
+	entities := func(delta, rate float64) []*sdk.EntityData {
+		i, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+		require.NoError(t, err)
+		e, err := i.Entity("default:nginx:nginx", "k8s:playground:container")
+		require.NoError(t, err)
+		ms := e.NewMetricSet("K8sContainerSample")
+		require.NoError(t, ms.SetMetric("restartCountDelta", delta, metric.GAUGE))
+		require.NoError(t, ms.SetMetric("net.rxBytesPerSecond", rate, metric.GAUGE))
+		require.NoError(t, ms.SetMetric("containerCpuCfsPeriodsTotal", 42, metric.GAUGE))
+		require.NoError(t, ms.SetMetric("apiserverRequestsDelta_verb_GET", delta, metric.GAUGE))
+		return i.Data
+	}
+
+	// The first update has no previous one to accumulate the rates over.
+	handler.metricFamilies(entities(1, 10), 0)
+	families := make(map[string]*model.MetricFamily)
+	for _, f := range handler.metricFamilies(entities(2, 10), 30) {
+		families[f.GetName()] = f
+	}
+
+	for name, expected := range map[string]float64{
+		"k8s_container_restart_count_delta":               3,
+		"k8s_container_net_rx_bytes_per_second":           300,
+		"k8s_container_container_cpu_cfs_periods_total":   42,
+		"k8s_container_apiserver_requests_delta_verb_get": 3,
+	} {
+		require.Contains(t, families, name)
+		assert.Equal(t, model.MetricType_COUNTER, families[name].GetType(), name)
+		assert.Equal(t, expected, families[name].Metric[0].GetCounter().GetValue(), name)
+	}
+	assert.Equal(t, model.MetricType_GAUGE, families["k8s_container_info"].GetType())
+
+	// The counters of the time-series which are not reported are forgotten.
+	handler.Update(nil)
+	assert.Empty(t, handler.counters)
+}
+
+func TestPrometheusHandler_SourceTypesByEventType(t *testing.T) {
+	handler := NewPrometheusHandler(logrus.New(), []definition.GuessFunc{sampleTypeGuesser},
+		definition.SpecGroups{
+			"pod": {
+				Specs: []definition.Spec{
+					{Name: "restarts", Type: metric.DELTA},
+				},
+			},
+		},
+		definition.SpecGroups{
+			"container": {
+				Specs: []definition.Spec{
+					{Name: "restarts", Type: metric.GAUGE},
+				},
+			},
+		},
+	)
+
+	i, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+	e, err := i.Entity("default:nginx", "k8s:playground:pod")
+	require.NoError(t, err)
+	require.NoError(t, e.NewMetricSet("K8sPodSample").SetMetric("restarts", 2, metric.GAUGE))
+	require.NoError(t, e.NewMetricSet("K8sContainerSample").SetMetric("restarts", 2, metric.GAUGE))
+
+	handler.metricFamilies(i.Data, 0)
+	families := make(map[string]*model.MetricFamily)
+	for _, f := range handler.metricFamilies(i.Data, 30) {
+		families[f.GetName()] = f
+	}
+
+	// The spec of each group is used for its own metric sets only.
+	require.Contains(t, families, "k8s_pod_restarts")
+	assert.Equal(t, model.MetricType_COUNTER, families["k8s_pod_restarts"].GetType())
+	assert.Equal(t, float64(4), families["k8s_pod_restarts"].Metric[0].GetCounter().GetValue())
+	require.Contains(t, families, "k8s_container_restarts")
+	assert.Equal(t, model.MetricType_GAUGE, families["k8s_container_restarts"].GetType())
+	assert.Equal(t, float64(2), families["k8s_container_restarts"].Metric[0].GetGauge().GetValue())
+}
+
+func TestPrometheusHandler_LabelCollisions(t *testing.T) {
+	i, err := sdk.NewIntegrationProtocol2("nr.test", "1.0.0", new(struct{}))
+	require.NoError(t, err)
+	e, err := i.Entity("default:nginx", "k8s:playground:pod")
+	require.NoError(t, err)
+	ms := e.NewMetricSet("K8sPodSample")
+	require.NoError(t, ms.SetMetric("podName", "nginx", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("pod_name", "other", metric.ATTRIBUTE))
+	require.NoError(t, ms.SetMetric("restarts", 1, metric.GAUGE))
+	require.NoError(t, ms.SetMetric("Restarts", 2, metric.GAUGE))
+
+	handler := NewPrometheusHandler(logrus.New(), nil)
+	handler.Update(i.Data)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	// Only the first attribute and metric, in alphabetical order, are kept.
+	labels := `{entity_name="k8s:playground:pod:default:nginx",pod_name="nginx"}`
+	expected := `# HELP k8s_pod_info Attributes of K8sPodSample.
+# TYPE k8s_pod_info gauge
+k8s_pod_info` + labels + ` 1
+# HELP k8s_pod_restarts Restarts of K8sPodSample.
+# TYPE k8s_pod_restarts gauge
+k8s_pod_restarts` + labels + ` 2
+`
+	assert.Equal(t, expected, string(body))
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"podsMissing":                     "pods_missing",
+		"K8sApiServerDimensional":         "k8s_api_server_dimensional",
+		"podIP":                           "pod_ip",
+		"IPAddress":                       "ip_address",
+		"apiserverRequestsDelta_verb_GET": "apiserver_requests_delta_verb_get",
+		"container.name":                  "container_name",
+		"2xx":                             "_2xx",
+	} {
+		assert.Equal(t, expected, snakeCase(name), name)
+	}
+}
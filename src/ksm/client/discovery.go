@@ -48,6 +48,8 @@ type ksm struct {
 	endpoint   url.URL
 	nodeIP     string
 	logger     *logrus.Logger
+	// certificates are recorded when KSM is called over https
+	certificates client.CertificateRecorder
 }
 
 func (sd *discoverer) Discover(timeout time.Duration) (client.HTTPClient, error) {
@@ -120,7 +122,14 @@ func (c *ksm) Do(method, urlPath string) (*http.Response, error) {
 
 	c.logger.Debugf("Calling kube-state-metrics endpoint: %s", r.URL.String())
 
-	return c.httpClient.Do(r)
+	resp, err := c.httpClient.Do(r)
+	c.certificates.RecordResponse(resp)
+	return resp, err
+}
+
+// Certificates returns the certificates recorded while calling KSM.
+func (c *ksm) Certificates() *client.CertificateRecorder {
+	return &c.certificates
 }
 
 // dnsDiscover uses DNS to discover KSM
@@ -2,6 +2,7 @@ package ksm
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/newrelic/nri-kubernetes/src/client"
 	"github.com/newrelic/nri-kubernetes/src/data"
@@ -58,6 +59,12 @@ func (r *ksmGrouper) Group(specGroups definition.SpecGroups) (definition.RawGrou
 			errs = append(errs, err)
 		}
 	}
+	if podGroup, ok := groups["pod"]; ok {
+		err = r.addPendingDetailsToGroup(podGroup)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if len(errs) == 0 {
 		return groups, nil
 	}
@@ -73,3 +80,33 @@ func NewGrouper(c client.HTTPClient, queries []prometheus.Query, logger *logrus.
 		k8sClient: k8sClient,
 	}
 }
+
+// NewCertificatesFetchFunc returns a FetchFunc that reports the certificates
+// recorded while querying the given KSM clients as metrics of the given node.
+// When several KSM instances are queried, the certificate that expires the
+// soonest is reported.
+func NewCertificatesFetchFunc(nodeName string, clients []client.HTTPClient) data.FetchFunc {
+	return func() (definition.RawGroups, error) {
+		var earliest definition.RawMetrics
+		for _, c := range clients {
+			certificates := client.CertificateMetrics(c, "ksm", time.Now())
+			expiresIn, ok := certificates["ksmCertExpiresInSeconds"].(float64)
+			if !ok {
+				continue
+			}
+			if earliest == nil || expiresIn < earliest["ksmCertExpiresInSeconds"].(float64) {
+				earliest = certificates
+			}
+		}
+
+		if earliest == nil {
+			return definition.RawGroups{}, nil
+		}
+
+		return definition.RawGroups{
+			"node": {
+				nodeName: earliest,
+			},
+		}, nil
+	}
+}
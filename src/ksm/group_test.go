@@ -1,6 +1,8 @@
 package ksm
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/newrelic/nri-kubernetes/src/client"
@@ -43,3 +45,36 @@ func TestAddServiceSpecSelectorToGroup(t *testing.T) {
 	assert.Equal(t, expected["selector_l1"], actual["selector_l1"])
 	assert.Equal(t, expected["selector_l2"], actual["selector_l2"])
 }
+
+type certificatesClient struct {
+	certificates client.CertificateRecorder
+}
+
+func (c *certificatesClient) Do(method, path string) (*http.Response, error) { return nil, nil }
+func (c *certificatesClient) NodeIP() string                                 { return "1.2.3.4" }
+func (c *certificatesClient) Certificates() *client.CertificateRecorder      { return &c.certificates }
+
+func TestCertificatesFetchFunc(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+
+	withTLS := &certificatesClient{}
+	withTLS.certificates.RecordResponse(resp)
+
+	groups, err := NewCertificatesFetchFunc("node-1", []client.HTTPClient{&certificatesClient{}, withTLS})()
+	require.NoError(t, err)
+
+	node := groups["node"]["node-1"]
+	assert.Contains(t, node, "ksmCertExpiresInSeconds")
+	assert.Equal(t, server.Certificate().Issuer.String(), node["ksmCertIssuer"])
+}
+
+func TestCertificatesFetchFunc_NoTLS(t *testing.T) {
+	groups, err := NewCertificatesFetchFunc("node-1", []client.HTTPClient{&certificatesClient{}})()
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
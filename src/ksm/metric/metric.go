@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/owner"
 	"github.com/newrelic/nri-kubernetes/src/prometheus"
 )
 
@@ -33,6 +34,13 @@ func GetStatusForContainer() definition.FetchFunc {
 // a ReplicaSet.
 func GetDeploymentNameForReplicaSet() definition.FetchFunc {
 	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		if m, ok := groups[groupLabel][entityID]["kube_replicaset_owner"].(prometheus.Metric); ok {
+			if m.Labels["owner_kind"] == "Deployment" {
+				return m.Labels["owner_name"], nil
+			}
+			return "", nil
+		}
+
 		replicasetName, err := prometheus.FromLabelValue("kube_replicaset_created", "replicaset")(groupLabel, entityID, groups)
 		if err != nil {
 			return nil, err
@@ -50,6 +58,10 @@ func GetDeploymentNameForReplicaSet() definition.FetchFunc {
 // Pod.  It returns an empty string if Pod hasn't been created by a deployment.
 func GetDeploymentNameForPod() definition.FetchFunc {
 	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		if name, ok := resolvedDeploymentName(groups[groupLabel][entityID]); ok {
+			return name, nil
+		}
+
 		creatorKind, err := prometheus.FromLabelValue("kube_pod_info", "created_by_kind")(groupLabel, entityID, groups)
 		if err != nil {
 			return nil, err
@@ -77,6 +89,10 @@ func GetDeploymentNameForPod() definition.FetchFunc {
 // pod. Returns an empty string if its pod hasn't been created by a deployment.
 func GetDeploymentNameForContainer() definition.FetchFunc {
 	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		if name, ok := resolvedDeploymentName(groups[groupLabel][entityID]); ok {
+			return name, nil
+		}
+
 		mm := map[string]string{
 			"created_by_kind": "created_by_kind",
 			"created_by_name": "created_by_name",
@@ -99,6 +115,17 @@ func GetDeploymentNameForContainer() definition.FetchFunc {
 	}
 }
 
+// resolvedDeploymentName returns the name of the deployment of a pod or
+// container whose owners were resolved by an owner.Resolver, which is empty
+// if none of its owners is a deployment.
+func resolvedDeploymentName(metrics definition.RawMetrics) (string, bool) {
+	if _, ok := metrics[owner.WorkloadKindKey]; !ok {
+		return "", false
+	}
+	name, _ := metrics[owner.DeploymentNameKey].(string)
+	return name, true
+}
+
 func deploymentNameBasedOnCreator(creatorKind, creatorName string) string {
 	var deploymentName string
 	if creatorKind == "ReplicaSet" {
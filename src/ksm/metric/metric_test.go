@@ -92,6 +92,70 @@ func TestGetDeploymentNameForReplicaSet_ErrorOnEmptyData(t *testing.T) {
 	assert.Empty(t, fetchedValue)
 }
 
+func TestGetDeploymentNameForReplicaSet_FromOwner(t *testing.T) {
+	raw := definition.RawGroups{
+		"replicaset": {
+			"default_canary-7d4b9c8f6": definition.RawMetrics{
+				"kube_replicaset_owner": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: map[string]string{
+						"namespace":  "default",
+						"replicaset": "canary-7d4b9c8f6",
+						"owner_kind": "Rollout",
+						"owner_name": "canary",
+					},
+				},
+			},
+			"default_nginx-5c689d88bb": definition.RawMetrics{
+				"kube_replicaset_owner": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: map[string]string{
+						"namespace":  "default",
+						"replicaset": "nginx-5c689d88bb",
+						"owner_kind": "Deployment",
+						"owner_name": "nginx",
+					},
+				},
+			},
+		},
+	}
+
+	fetchedValue, err := GetDeploymentNameForReplicaSet()("replicaset", "default_canary-7d4b9c8f6", raw)
+	assert.Nil(t, err)
+	assert.Empty(t, fetchedValue)
+
+	fetchedValue, err = GetDeploymentNameForReplicaSet()("replicaset", "default_nginx-5c689d88bb", raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "nginx", fetchedValue)
+}
+
+func TestGetDeploymentNameForPod_ResolvedOwners(t *testing.T) {
+	raw := definition.RawGroups{
+		"pod": {
+			"default_canary-7d4b9c8f6-8mfzc": definition.RawMetrics{
+				"workloadKind": "Rollout",
+				"workloadName": "canary",
+				"kube_pod_info": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: map[string]string{
+						"created_by_kind": "ReplicaSet",
+						"created_by_name": "canary-7d4b9c8f6",
+					},
+				},
+			},
+		},
+	}
+
+	fetchedValue, err := GetDeploymentNameForPod()("pod", "default_canary-7d4b9c8f6-8mfzc", raw)
+	assert.Nil(t, err)
+	assert.Empty(t, fetchedValue)
+
+	raw["pod"]["default_canary-7d4b9c8f6-8mfzc"]["deploymentName"] = "canary-stable"
+	fetchedValue, err = GetDeploymentNameForPod()("pod", "default_canary-7d4b9c8f6-8mfzc", raw)
+	assert.Nil(t, err)
+	assert.Equal(t, "canary-stable", fetchedValue)
+}
+
 func TestGetDeploymentNameForPod_CreatedByReplicaSet(t *testing.T) {
 	expectedValue := "fluentd-elasticsearch"
 	fetchedValue, err := GetDeploymentNameForPod()("pod", "fluentd-elasticsearch-jnqb7", rawGroups)
@@ -0,0 +1,147 @@
+package ksm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+)
+
+// Raw metrics set in the pending pods raw entities from their PodScheduled
+// condition.
+const (
+	PendingReasonKey      = "pendingReason"
+	PendingMessageKey     = "pendingMessage"
+	AvailableNodesKey     = "availableNodes"
+	TotalNodesKey         = "totalNodes"
+	UnschedulableNodesKey = "unschedulableNodes"
+)
+
+// availableNodesRegexp matches the start of the messages of the scheduler,
+// e.g. "0/3 nodes are available: 1 Insufficient cpu, 2 node(s) had taint
+// {node-role.kubernetes.io/master: }, that the pod didn't tolerate."
+var availableNodesRegexp = regexp.MustCompile(`^(\d+)/(\d+) nodes are available(?::\s*(.*))?`)
+
+// causeCountRegexp matches the start of each cause in the message, which is
+// the number of nodes discarded by it.
+var causeCountRegexp = regexp.MustCompile(`(?:^|, )(\d+) `)
+
+// schedulingDetails are the details of a pod that the scheduler failed to
+// schedule.
+type schedulingDetails struct {
+	available, total int
+	// unschedulable is the number of nodes discarded by each cause, e.g.
+	// insufficientCpu.
+	unschedulable map[string]int
+}
+
+// parseSchedulingMessage parses the message of the Unschedulable condition
+// of a pod. It returns false when the message doesn't have the format of
+// the scheduler.
+func parseSchedulingMessage(message string) (schedulingDetails, bool) {
+	m := availableNodesRegexp.FindStringSubmatch(strings.TrimSpace(message))
+	if m == nil {
+		return schedulingDetails{}, false
+	}
+	available, _ := strconv.Atoi(m[1])
+	total, _ := strconv.Atoi(m[2])
+	details := schedulingDetails{available: available, total: total, unschedulable: make(map[string]int)}
+
+	causes := m[3]
+	// Newer schedulers append the result of the preemption, which repeats
+	// the nodes count.
+	if i := strings.Index(causes, ". preemption:"); i >= 0 {
+		causes = causes[:i]
+	}
+	causes = strings.TrimSuffix(strings.TrimSpace(causes), ".")
+
+	bounds := causeCountRegexp.FindAllStringSubmatchIndex(causes, -1)
+	for i, b := range bounds {
+		end := len(causes)
+		if i+1 < len(bounds) {
+			end = bounds[i+1][0]
+		}
+		count, _ := strconv.Atoi(causes[b[2]:b[3]])
+		details.unschedulable[classifyCause(causes[b[1]:end])] += count
+	}
+	return details, true
+}
+
+// classifyCause returns the name of the metric of a cause of the scheduler
+// discarding nodes, e.g. untoleratedTaint for "node(s) had taint {...},
+// that the pod didn't tolerate".
+func classifyCause(cause string) string {
+	cause = strings.ToLower(strings.TrimSpace(cause))
+	switch {
+	case strings.HasPrefix(cause, "insufficient "):
+		return "insufficient" + camelCase(strings.TrimPrefix(cause, "insufficient "))
+	case strings.Contains(cause, "taint"):
+		return "untoleratedTaint"
+	case strings.Contains(cause, "volume node affinity"):
+		return "volumeNodeAffinityConflict"
+	case strings.Contains(cause, "node selector"), strings.Contains(cause, "node affinity"):
+		return "nodeSelectorMismatch"
+	case strings.Contains(cause, "unschedulable"):
+		return "nodeUnschedulable"
+	case strings.Contains(cause, "free ports"):
+		return "portConflict"
+	case strings.Contains(cause, "affinity"), strings.Contains(cause, "topology spread"):
+		return "podAffinityMismatch"
+	default:
+		return "other"
+	}
+}
+
+// camelCase turns a resource name, e.g. nvidia.com/gpu, into NvidiaComGpu.
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// addPendingDetailsToGroup adds the reason why the pending pods of the pod
+// group were not scheduled, and the number of nodes discarded by each cause
+// when the scheduler reported them.
+func (r *ksmGrouper) addPendingDetailsToGroup(podGroup map[string]definition.RawMetrics) error {
+	pods, err := r.k8sClient.ListUnscheduledPods()
+	if err != nil {
+		return err
+	}
+	for _, p := range pods.Items {
+		podRawMetrics, ok := podGroup[fmt.Sprintf("%s_%s", p.Namespace, p.Name)]
+		if !ok {
+			continue
+		}
+		for _, c := range p.Status.Conditions {
+			if c.Type != v1.PodScheduled || c.Status != v1.ConditionFalse {
+				continue
+			}
+			podRawMetrics[PendingReasonKey] = c.Reason
+			podRawMetrics[PendingMessageKey] = c.Message
+			if c.Reason != v1.PodReasonUnschedulable {
+				continue
+			}
+			details, ok := parseSchedulingMessage(c.Message)
+			if !ok {
+				continue
+			}
+			podRawMetrics[AvailableNodesKey] = details.available
+			podRawMetrics[TotalNodesKey] = details.total
+			unschedulable := make(definition.FetchedValues, len(details.unschedulable))
+			for cause, count := range details.unschedulable {
+				unschedulable[UnschedulableNodesKey+"."+cause] = count
+			}
+			podRawMetrics[UnschedulableNodesKey] = unschedulable
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package ksm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+)
+
+func TestParseSchedulingMessage(t *testing.T) {
+	for _, c := range []struct {
+		message  string
+		expected schedulingDetails
+	}{
+		{
+			message: "0/3 nodes are available: 1 node(s) had taint {node-role.kubernetes.io/master: }, that the pod didn't tolerate, 2 Insufficient cpu.",
+			expected: schedulingDetails{available: 0, total: 3, unschedulable: map[string]int{
+				"untoleratedTaint": 1,
+				"insufficientCpu":  2,
+			}},
+		},
+		{
+			message: "0/6 nodes are available: 1 node(s) had untolerated taint {node.kubernetes.io/unreachable: }, 2 Insufficient memory, " +
+				"2 Insufficient nvidia.com/gpu, 1 node(s) didn't match Pod's node affinity/selector. " +
+				"preemption: 0/6 nodes are available: 6 Preemption is not helpful for scheduling.",
+			expected: schedulingDetails{available: 0, total: 6, unschedulable: map[string]int{
+				"untoleratedTaint":         1,
+				"insufficientMemory":       2,
+				"insufficientNvidiaComGpu": 2,
+				"nodeSelectorMismatch":     1,
+			}},
+		},
+		{
+			message: "1/5 nodes are available: 1 node(s) were unschedulable, 1 node(s) didn't have free ports for the requested pod ports, " +
+				"1 node(s) had volume node affinity conflict, 1 node(s) didn't match pod anti-affinity rules.",
+			expected: schedulingDetails{available: 1, total: 5, unschedulable: map[string]int{
+				"nodeUnschedulable":          1,
+				"portConflict":               1,
+				"volumeNodeAffinityConflict": 1,
+				"podAffinityMismatch":        1,
+			}},
+		},
+		{
+			message:  "0/2 nodes are available: 2 node(s) exceed max volume count.",
+			expected: schedulingDetails{available: 0, total: 2, unschedulable: map[string]int{"other": 2}},
+		},
+		{
+			message:  "0/0 nodes are available",
+			expected: schedulingDetails{unschedulable: map[string]int{}},
+		},
+	} {
+		details, ok := parseSchedulingMessage(c.message)
+		assert.True(t, ok, c.message)
+		assert.Equal(t, c.expected, details, c.message)
+	}
+
+	_, ok := parseSchedulingMessage("pod has unbound immediate PersistentVolumeClaims")
+	assert.False(t, ok)
+}
+
+func pendingPod(name string, conditions ...v1.PodCondition) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Status:     v1.PodStatus{Phase: v1.PodPending, Conditions: conditions},
+	}
+}
+
+func TestAddPendingDetailsToGroup(t *testing.T) {
+	k8sClient := new(client.MockedKubernetes)
+	k8sClient.On("ListUnscheduledPods").Return(&v1.PodList{Items: []v1.Pod{
+		pendingPod("big", v1.PodCondition{
+			Type:    v1.PodScheduled,
+			Status:  v1.ConditionFalse,
+			Reason:  v1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient memory.",
+		}),
+		pendingPod("claim", v1.PodCondition{
+			Type:    v1.PodScheduled,
+			Status:  v1.ConditionFalse,
+			Reason:  v1.PodReasonUnschedulable,
+			Message: "pod has unbound immediate PersistentVolumeClaims",
+		}),
+		pendingPod("new"),
+		// Not reported by KSM.
+		pendingPod("other", v1.PodCondition{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: v1.PodReasonUnschedulable}),
+	}}, nil)
+
+	grouper := &ksmGrouper{k8sClient: k8sClient}
+	podGroup := map[string]definition.RawMetrics{
+		"default_big":   {},
+		"default_claim": {},
+		"default_new":   {},
+	}
+	require.NoError(t, grouper.addPendingDetailsToGroup(podGroup))
+
+	assert.Equal(t, map[string]definition.RawMetrics{
+		"default_big": {
+			"pendingReason":      "Unschedulable",
+			"pendingMessage":     "0/3 nodes are available: 3 Insufficient memory.",
+			"availableNodes":     0,
+			"totalNodes":         3,
+			"unschedulableNodes": definition.FetchedValues{"unschedulableNodes.insufficientMemory": 3},
+		},
+		"default_claim": {
+			"pendingReason":  "Unschedulable",
+			"pendingMessage": "pod has unbound immediate PersistentVolumeClaims",
+		},
+		"default_new": {},
+	}, podGroup)
+}
+
+func TestAddPendingDetailsToGroup_Error(t *testing.T) {
+	k8sClient := new(client.MockedKubernetes)
+	k8sClient.On("ListUnscheduledPods").Return(&v1.PodList{}, errors.New("forbidden"))
+
+	grouper := &ksmGrouper{k8sClient: k8sClient}
+	assert.Error(t, grouper.addPendingDetailsToGroup(map[string]definition.RawMetrics{}))
+}
@@ -47,6 +47,8 @@ type kubelet struct {
 	nodeName   string
 	httpType   int // httpBasic, httpInsecure, httpSecure
 	logger     *logrus.Logger
+	// certificates are recorded when the kubelet is called over https
+	certificates client.CertificateRecorder
 }
 
 type connectionParams struct {
@@ -95,7 +97,14 @@ func (c *kubelet) Do(method, urlPath string) (*http.Response, error) {
 
 	c.logger.Debugf("Calling Kubelet endpoint: %s", r.URL.String())
 
-	return c.httpClient.Do(r)
+	resp, err := c.httpClient.Do(r)
+	c.certificates.RecordResponse(resp)
+	return resp, err
+}
+
+// Certificates returns the certificates recorded while calling the kubelet.
+func (c *kubelet) Certificates() *client.CertificateRecorder {
+	return &c.certificates
 }
 
 func (sd *discoverer) Discover(timeout time.Duration) (client.HTTPClient, error) {
@@ -2,6 +2,7 @@ package kubelet
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/newrelic/nri-kubernetes/src/apiserver"
 
@@ -83,15 +84,22 @@ func (r *kubelet) Group(definition.SpecGroups) (definition.RawGroups, *data.Erro
 		}
 	}
 
+	nodeMetrics := definition.RawMetrics{
+		"labels":               nodeInfo.Labels,
+		"allocatable":          nodeInfo.Allocatable,
+		"capacity":             nodeInfo.Capacity,
+		"memoryRequestedBytes": requestedMemoryBytes,
+		"cpuRequestedCores":    requestedCPUMillis,
+	}
+
+	// Certificates are only recorded when the kubelet is queried over TLS.
+	for name, value := range client.CertificateMetrics(r.client, "", time.Now()) {
+		nodeMetrics[name] = value
+	}
+
 	g := definition.RawGroups{
 		"node": {
-			response.Node.NodeName: definition.RawMetrics{
-				"labels":               nodeInfo.Labels,
-				"allocatable":          nodeInfo.Allocatable,
-				"capacity":             nodeInfo.Capacity,
-				"memoryRequestedBytes": requestedMemoryBytes,
-				"cpuRequestedCores":    requestedCPUMillis,
-			},
+			response.Node.NodeName: nodeMetrics,
 		},
 	}
 	fillGroupsAndMergeNonExistent(rawGroups, g)
@@ -16,6 +16,7 @@ import (
 
 	"time"
 
+	"github.com/newrelic/nri-kubernetes/src/annotation"
 	"github.com/newrelic/nri-kubernetes/src/client"
 	"github.com/newrelic/nri-kubernetes/src/data"
 	"github.com/newrelic/nri-kubernetes/src/definition"
@@ -25,6 +26,25 @@ import (
 // KubeletPodsPath is the path where kubelet serves information about pods.
 const KubeletPodsPath = "/pods"
 
+// Values of the containerType of the containers.
+const (
+	regularContainerType   = "regular"
+	initContainerType      = "init"
+	ephemeralContainerType = "ephemeral"
+)
+
+// ephemeralContainers are the fields of the ephemeral containers of a pod,
+// which are missing in the version of v1.Pod used. Their specs are a subset
+// of the ones of the regular containers.
+type ephemeralContainers struct {
+	Spec struct {
+		EphemeralContainers []v1.Container `json:"ephemeralContainers"`
+	} `json:"spec"`
+	Status struct {
+		EphemeralContainerStatuses []v1.ContainerStatus `json:"ephemeralContainerStatuses"`
+	} `json:"status"`
+}
+
 // PodsFetcher queries the kubelet and fetches the information of pods
 // running on the node. It contains an in-memory cache to store the
 // results and avoid querying the kubelet multiple times in the same
@@ -69,6 +89,15 @@ func doPodsFetch(logger *logrus.Logger, c client.HTTPClient, enableStaticPodsSta
 		return nil, fmt.Errorf("error decoding response from kubelet %s path. %s", KubeletPodsPath, err)
 	}
 
+	// The ephemeral containers of each pod, in the same order as the pods.
+	var ephemeral struct {
+		Items []ephemeralContainers `json:"items"`
+	}
+	err = json.Unmarshal(rawPods, &ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ephemeral containers from kubelet %s path. %s", KubeletPodsPath, err)
+	}
+
 	raw := definition.RawGroups{
 		"pod":       make(map[string]definition.RawMetrics),
 		"container": make(map[string]definition.RawMetrics),
@@ -80,7 +109,7 @@ func doPodsFetch(logger *logrus.Logger, c client.HTTPClient, enableStaticPodsSta
 	var missingNodeIPPodIDs []string
 	var nodeIP string
 
-	for _, p := range pods.Items {
+	for i, p := range pods.Items {
 		id := podID(&p)
 		raw["pod"][id] = fetchPodData(logger, &p, enableStaticPodsStatus)
 
@@ -94,7 +123,12 @@ func doPodsFetch(logger *logrus.Logger, c client.HTTPClient, enableStaticPodsSta
 			raw["pod"][id]["nodeIP"] = nodeIP
 		}
 
-		containers := fetchContainersData(logger, &p, enableStaticPodsStatus)
+		var podEphemeral ephemeralContainers
+		if i < len(ephemeral.Items) {
+			podEphemeral = ephemeral.Items[i]
+		}
+
+		containers := fetchContainersData(logger, &p, podEphemeral, enableStaticPodsStatus)
 		for id, c := range containers {
 			raw["container"][id] = c
 
@@ -142,68 +176,90 @@ func NewPodsFetcher(l *logrus.Logger, c client.HTTPClient, enableStaticPodsStatu
 	}
 }
 
-func fetchContainersData(logger *logrus.Logger, pod *v1.Pod, enableStaticPodsStatus bool) map[string]definition.RawMetrics {
+// fetchContainersData returns the data of the regular, init and ephemeral
+// containers of a pod, each with its containerType.
+func fetchContainersData(logger *logrus.Logger, pod *v1.Pod, ephemeral ephemeralContainers, enableStaticPodsStatus bool) map[string]definition.RawMetrics {
 	statuses := make(map[string]definition.RawMetrics)
 	if enableStaticPodsStatus || !isStaticPod(pod) {
-		fillContainerStatuses(pod, statuses)
+		fillContainerStatuses(pod, pod.Status.ContainerStatuses, statuses)
+		fillContainerStatuses(pod, pod.Status.InitContainerStatuses, statuses)
+		fillContainerStatuses(pod, ephemeral.Status.EphemeralContainerStatuses, statuses)
 	} else {
 		logger.Debugf("static pod found. Skip fetching containers status for pod %q", podID(pod))
 	}
 
 	metrics := make(map[string]definition.RawMetrics)
 
-	for _, c := range pod.Spec.Containers {
-		id := containerID(pod, c.Name)
-		metrics[id] = definition.RawMetrics{
-			"containerName":  c.Name,
-			"containerImage": c.Image,
-			"namespace":      pod.GetObjectMeta().GetNamespace(),
-			"podName":        pod.GetObjectMeta().GetName(),
-			"nodeName":       pod.Spec.NodeName,
+	for _, containers := range []struct {
+		containerType string
+		specs         []v1.Container
+	}{
+		{regularContainerType, pod.Spec.Containers},
+		{initContainerType, pod.Spec.InitContainers},
+		{ephemeralContainerType, ephemeral.Spec.EphemeralContainers},
+	} {
+		for _, c := range containers.specs {
+			fillContainerData(metrics, pod, c, containers.containerType, statuses)
 		}
+	}
 
-		if v := pod.Status.HostIP; v != "" {
-			metrics[id]["nodeIP"] = v
-		}
+	return metrics
+}
 
-		if v, ok := c.Resources.Requests[v1.ResourceCPU]; ok {
-			metrics[id]["cpuRequestedCores"] = v.MilliValue()
-		}
+// fillContainerData sets in metrics the data of a container of a pod, merged
+// with its status from statuses.
+func fillContainerData(metrics map[string]definition.RawMetrics, pod *v1.Pod, c v1.Container, containerType string, statuses map[string]definition.RawMetrics) {
+	id := containerID(pod, c.Name)
+	metrics[id] = definition.RawMetrics{
+		"containerName":  c.Name,
+		"containerImage": c.Image,
+		"containerType":  containerType,
+		"namespace":      pod.GetObjectMeta().GetNamespace(),
+		"podName":        pod.GetObjectMeta().GetName(),
+		"nodeName":       pod.Spec.NodeName,
+	}
 
-		if v, ok := c.Resources.Limits[v1.ResourceCPU]; ok {
-			metrics[id]["cpuLimitCores"] = v.MilliValue()
-		}
+	if v := pod.Status.HostIP; v != "" {
+		metrics[id]["nodeIP"] = v
+	}
 
-		if v, ok := c.Resources.Requests[v1.ResourceMemory]; ok {
-			metrics[id]["memoryRequestedBytes"] = v.Value()
-		}
+	if v, ok := c.Resources.Requests[v1.ResourceCPU]; ok {
+		metrics[id]["cpuRequestedCores"] = v.MilliValue()
+	}
 
-		if v, ok := c.Resources.Limits[v1.ResourceMemory]; ok {
-			metrics[id]["memoryLimitBytes"] = v.Value()
-		}
+	if v, ok := c.Resources.Limits[v1.ResourceCPU]; ok {
+		metrics[id]["cpuLimitCores"] = v.MilliValue()
+	}
 
-		if ref := pod.GetOwnerReferences(); len(ref) > 0 {
-			if d := deploymentNameBasedOnCreator(ref[0].Kind, ref[0].Name); d != "" {
-				metrics[id]["deploymentName"] = d
-			}
-		}
+	if v, ok := c.Resources.Requests[v1.ResourceMemory]; ok {
+		metrics[id]["memoryRequestedBytes"] = v.Value()
+	}
 
-		// merging status data
-		for k, v := range statuses[id] {
-			metrics[id][k] = v
-		}
+	if v, ok := c.Resources.Limits[v1.ResourceMemory]; ok {
+		metrics[id]["memoryLimitBytes"] = v.Value()
+	}
 
-		labels := podLabels(pod)
-		if len(labels) > 0 {
-			metrics[id]["labels"] = labels
+	if ref := pod.GetOwnerReferences(); len(ref) > 0 {
+		if d := deploymentNameBasedOnCreator(ref[0].Kind, ref[0].Name); d != "" {
+			metrics[id]["deploymentName"] = d
 		}
 	}
 
-	return metrics
+	fillContainerSecurity(metrics[id], pod, c)
+
+	// merging status data
+	for k, v := range statuses[id] {
+		metrics[id][k] = v
+	}
+
+	labels := podLabels(pod)
+	if len(labels) > 0 {
+		metrics[id]["labels"] = labels
+	}
 }
 
-func fillContainerStatuses(pod *v1.Pod, dest map[string]definition.RawMetrics) {
-	for _, c := range pod.Status.ContainerStatuses {
+func fillContainerStatuses(pod *v1.Pod, containerStatuses []v1.ContainerStatus, dest map[string]definition.RawMetrics) {
+	for _, c := range containerStatuses {
 		name := c.Name
 		id := containerID(pod, name)
 
@@ -267,6 +323,7 @@ func fetchPodData(logger *logrus.Logger, pod *v1.Pod, staticPodsStatusSupport bo
 
 	if staticPodsStatusSupport || !isStaticPod(pod) {
 		fillPodStatus(logger, metrics, pod)
+		fillPodLatencies(metrics, pod)
 	} else {
 		logger.Debugf("Static pod found. Skip fetching pod status for pod %q", podID(pod))
 	}
@@ -299,11 +356,18 @@ func fetchPodData(logger *logrus.Logger, pod *v1.Pod, staticPodsStatusSupport bo
 		metrics["message"] = pod.Status.Message
 	}
 
+	fillPodSecurity(metrics, pod)
+
 	labels := podLabels(pod)
 	if len(labels) > 0 {
 		metrics["labels"] = labels
 	}
 
+	// All of them are fetched, the scrape job only keeps the allowed ones.
+	if annotations := pod.GetObjectMeta().GetAnnotations(); len(annotations) > 0 {
+		metrics["annotations"] = annotations
+	}
+
 	return metrics
 }
 
@@ -331,6 +395,44 @@ func fillPodStatus(logger *logrus.Logger, r definition.RawMetrics, pod *v1.Pod)
 	r["status"] = string(pod.Status.Phase)
 }
 
+// podLatencies are the durations computed from the last transition of the
+// conditions of the pods, or from their creation when the first one is
+// empty, by raw metric.
+var podLatencies = map[string][2]v1.PodConditionType{
+	"schedulingLatencySeconds":      {"", v1.PodScheduled},
+	"initializationLatencySeconds":  {v1.PodScheduled, v1.PodInitialized},
+	"containersReadyLatencySeconds": {v1.PodInitialized, "ContainersReady"},
+	"startupLatencySeconds":         {"", v1.PodReady},
+}
+
+// fillPodLatencies sets how long the pod took to be scheduled, initialized
+// and ready, from the transition times of its conditions. Only the conditions
+// which are true are considered, and the latest transition of a condition is
+// the only one known, so a pod becoming ready again after failing a readiness
+// probe reports the time until it became ready the last time.
+func fillPodLatencies(r definition.RawMetrics, pod *v1.Pod) {
+	if isFakePendingPod(pod.Status) {
+		return
+	}
+
+	transitions := map[v1.PodConditionType]time.Time{
+		"": pod.GetObjectMeta().GetCreationTimestamp().Time,
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Status == v1.ConditionTrue && !c.LastTransitionTime.IsZero() {
+			transitions[c.Type] = c.LastTransitionTime.Time
+		}
+	}
+
+	for name, conditions := range podLatencies {
+		from, to := transitions[conditions[0]], transitions[conditions[1]]
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			continue
+		}
+		r[name] = to.Sub(from).Seconds()
+	}
+}
+
 func podLabels(p *v1.Pod) map[string]string {
 	labels := make(map[string]string, len(p.GetObjectMeta().GetLabels()))
 	for k, v := range p.GetObjectMeta().GetLabels() {
@@ -370,6 +472,24 @@ func OneMetricPerLabel(rawLabels definition.FetchedValue) (definition.FetchedVal
 	return modified, nil
 }
 
+// OneMetricPerAnnotation transforms a map of annotations to FetchedValues
+// type, which will be converted later to one metric per annotation.
+// The keys are sanitized and prefixed with 'annotation.', like the
+// annotations inherited from KSM metrics.
+func OneMetricPerAnnotation(rawAnnotations definition.FetchedValue) (definition.FetchedValue, error) {
+	annotations, ok := rawAnnotations.(map[string]string)
+	if !ok {
+		return rawAnnotations, errors.New("error on creating kubelet annotation metrics")
+	}
+
+	modified := make(definition.FetchedValues, len(annotations))
+	for k, v := range annotations {
+		modified[annotation.AttributeName(k)] = v
+	}
+
+	return modified, nil
+}
+
 func podID(pod *v1.Pod) string {
 	return fmt.Sprintf("%v_%v", pod.GetObjectMeta().GetNamespace(), pod.GetObjectMeta().GetName())
 }
@@ -11,10 +11,16 @@ import (
 
 	"io"
 
+	"time"
+
 	"github.com/newrelic/nri-kubernetes/src/definition"
 	"github.com/newrelic/nri-kubernetes/src/kubelet/metric/testdata"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type testClient struct {
@@ -132,6 +138,45 @@ func TestOneMetricPerLabel(t *testing.T) {
 	assert.Equal(t, expected, v)
 }
 
+func TestOneMetricPerAnnotation(t *testing.T) {
+	g := map[string]string{
+		"example.com/team": "platform",
+	}
+
+	expected := definition.FetchedValues{
+		"annotation.example_com_team": "platform",
+	}
+
+	v, err := OneMetricPerAnnotation(g)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, v)
+}
+
+func TestOneMetricPerAnnotation_MatchesKSM(t *testing.T) {
+	fromKubelet, err := OneMetricPerAnnotation(map[string]string{"example.com/owner": "team-a"})
+	require.NoError(t, err)
+
+	// KSM sanitizes the annotation keys into labels.
+	raw := definition.RawGroups{
+		"pod": {
+			"default_nginx": definition.RawMetrics{
+				"kube_pod_annotations": prometheus.Metric{
+					Value: prometheus.GaugeValue(1),
+					Labels: prometheus.Labels{
+						"namespace":                    "default",
+						"pod":                          "nginx",
+						"annotation_example_com_owner": "team-a",
+					},
+				},
+			},
+		},
+	}
+	fromKSM, err := prometheus.InheritAllAnnotationsFrom("pod", "kube_pod_annotations")("pod", "default_nginx", raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, fromKSM, fromKubelet)
+}
+
 func assertError(t *testing.T, errorMessage string, handler http.HandlerFunc) {
 	c := testClient{
 		handler: handler,
@@ -143,3 +188,86 @@ func assertError(t *testing.T, errorMessage string, handler http.HandlerFunc) {
 	assert.EqualError(t, err, errorMessage)
 	assert.Empty(t, g)
 }
+
+func TestFillPodLatencies(t *testing.T) {
+	created := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	condition := func(conditionType v1.PodConditionType, status v1.ConditionStatus, after time.Duration) v1.PodCondition {
+		return v1.PodCondition{Type: conditionType, Status: status, LastTransitionTime: metav1.NewTime(created.Add(after))}
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			Conditions: []v1.PodCondition{
+				condition(v1.PodScheduled, v1.ConditionTrue, 2*time.Second),
+				condition(v1.PodInitialized, v1.ConditionTrue, 7*time.Second),
+				condition("ContainersReady", v1.ConditionTrue, 19*time.Second),
+				condition(v1.PodReady, v1.ConditionTrue, 20*time.Second),
+			},
+		},
+	}
+
+	r := definition.RawMetrics{}
+	fillPodLatencies(r, pod)
+	assert.Equal(t, definition.RawMetrics{
+		"schedulingLatencySeconds":      2.,
+		"initializationLatencySeconds":  5.,
+		"containersReadyLatencySeconds": 12.,
+		"startupLatencySeconds":         20.,
+	}, r)
+
+	// The conditions which are not true are left out.
+	pod.Status.Conditions[2] = condition("ContainersReady", v1.ConditionFalse, 30*time.Second)
+	pod.Status.Conditions[3] = condition(v1.PodReady, v1.ConditionFalse, 30*time.Second)
+	r = definition.RawMetrics{}
+	fillPodLatencies(r, pod)
+	assert.Equal(t, definition.RawMetrics{
+		"schedulingLatencySeconds":     2.,
+		"initializationLatencySeconds": 5.,
+	}, r)
+}
+
+func TestFetchFunc_InitAndEphemeralContainers(t *testing.T) {
+	c := testClient{
+		handler: func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"items": [{
+				"metadata": {"name": "web", "namespace": "default"},
+				"spec": {
+					"nodeName": "worker-1",
+					"initContainers": [{"name": "migrate", "image": "migrate:1", "resources": {"requests": {"cpu": "250m"}}}],
+					"containers": [{"name": "nginx", "image": "nginx:1"}],
+					"ephemeralContainers": [{"name": "debugger", "image": "busybox", "targetContainerName": "nginx"}]
+				},
+				"status": {
+					"phase": "Pending",
+					"hostIP": "10.0.0.1",
+					"initContainerStatuses": [{"name": "migrate", "restartCount": 4, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}],
+					"containerStatuses": [{"name": "nginx", "state": {"waiting": {"reason": "PodInitializing"}}}],
+					"ephemeralContainerStatuses": [{"name": "debugger", "restartCount": 0, "state": {"terminated": {"reason": "Completed"}}}]
+				}
+			}]}`)) // nolint: errcheck
+		},
+	}
+
+	g, err := NewPodsFetcher(logrus.StandardLogger(), &c, true).FetchFuncWithCache()()
+	require.NoError(t, err)
+
+	containers := g["container"]
+	assert.Len(t, containers, 3)
+	assert.Equal(t, "regular", containers["default_web_nginx"]["containerType"])
+	assert.Equal(t, "PodInitializing", containers["default_web_nginx"]["reason"])
+
+	migrate := containers["default_web_migrate"]
+	assert.Equal(t, "init", migrate["containerType"])
+	assert.Equal(t, "Waiting", migrate["status"])
+	assert.Equal(t, "CrashLoopBackOff", migrate["reason"])
+	assert.Equal(t, int32(4), migrate["restartCount"])
+	assert.Equal(t, int64(250), migrate["cpuRequestedCores"])
+
+	debugger := containers["default_web_debugger"]
+	assert.Equal(t, "ephemeral", debugger["containerType"])
+	assert.Equal(t, "busybox", debugger["containerImage"])
+	assert.Equal(t, "Terminated", debugger["status"])
+	assert.Equal(t, "Completed", debugger["reason"])
+	assert.Equal(t, "10.0.0.1", debugger["nodeIP"])
+}
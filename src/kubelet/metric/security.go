@@ -0,0 +1,90 @@
+package metric
+
+import (
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+)
+
+// fillPodSecurity sets the security attributes of the spec of a pod: the
+// namespaces of the host it shares, whether its containers must run as a
+// non-root user by default, its service account, and the paths of the host
+// mounted by its volumes, comma separated.
+func fillPodSecurity(metrics definition.RawMetrics, pod *v1.Pod) {
+	metrics["hostNetwork"] = pod.Spec.HostNetwork
+	metrics["hostPID"] = pod.Spec.HostPID
+	metrics["hostIPC"] = pod.Spec.HostIPC
+	metrics["runAsNonRoot"] = pod.Spec.SecurityContext != nil && isTrue(pod.Spec.SecurityContext.RunAsNonRoot)
+
+	if v := pod.Spec.ServiceAccountName; v != "" {
+		metrics["serviceAccountName"] = v
+	}
+
+	var hostPaths []string
+	for _, v := range pod.Spec.Volumes {
+		if v.HostPath != nil {
+			hostPaths = append(hostPaths, v.HostPath.Path)
+		}
+	}
+	if len(hostPaths) > 0 {
+		metrics["hostPathVolumes"] = joinSorted(hostPaths)
+	}
+}
+
+// fillContainerSecurity sets the effective security attributes of a
+// container of a pod, taking the defaults of Kubernetes and of the security
+// context of the pod into account. The added capabilities and the paths of
+// the host mounted in the container are comma separated.
+func fillContainerSecurity(metrics definition.RawMetrics, pod *v1.Pod, c v1.Container) {
+	sc := c.SecurityContext
+	if sc == nil {
+		sc = &v1.SecurityContext{}
+	}
+
+	metrics["privileged"] = isTrue(sc.Privileged)
+	// Privilege escalation is allowed unless it is disabled explicitly.
+	metrics["allowPrivilegeEscalation"] = sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation
+	metrics["readOnlyRootFilesystem"] = isTrue(sc.ReadOnlyRootFilesystem)
+
+	runAsNonRoot := sc.RunAsNonRoot
+	if runAsNonRoot == nil && pod.Spec.SecurityContext != nil {
+		runAsNonRoot = pod.Spec.SecurityContext.RunAsNonRoot
+	}
+	metrics["runAsNonRoot"] = isTrue(runAsNonRoot)
+
+	if sc.Capabilities != nil && len(sc.Capabilities.Add) > 0 {
+		capabilities := make([]string, 0, len(sc.Capabilities.Add))
+		for _, capability := range sc.Capabilities.Add {
+			capabilities = append(capabilities, string(capability))
+		}
+		metrics["capabilitiesAdded"] = joinSorted(capabilities)
+	}
+
+	hostPathVolumes := make(map[string]string)
+	for _, v := range pod.Spec.Volumes {
+		if v.HostPath != nil {
+			hostPathVolumes[v.Name] = v.HostPath.Path
+		}
+	}
+	var hostPaths []string
+	for _, m := range c.VolumeMounts {
+		if path, ok := hostPathVolumes[m.Name]; ok {
+			hostPaths = append(hostPaths, path)
+		}
+	}
+	if len(hostPaths) > 0 {
+		metrics["hostPathMounts"] = joinSorted(hostPaths)
+	}
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func joinSorted(values []string) string {
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
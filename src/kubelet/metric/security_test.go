@@ -0,0 +1,71 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestFillSecurity(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			ServiceAccountName: "operator",
+			HostPID:            true,
+			SecurityContext:    &v1.PodSecurityContext{RunAsNonRoot: boolPtr(true)},
+			Volumes: []v1.Volume{
+				{Name: "logs", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/var/log"}}},
+				{Name: "proc", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/proc"}}},
+				{Name: "cache", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+
+	r := definition.RawMetrics{}
+	fillPodSecurity(r, pod)
+	assert.Equal(t, definition.RawMetrics{
+		"hostNetwork":        false,
+		"hostPID":            true,
+		"hostIPC":            false,
+		"runAsNonRoot":       true,
+		"serviceAccountName": "operator",
+		"hostPathVolumes":    "/proc,/var/log",
+	}, r)
+
+	// The container inherits runAsNonRoot from the pod.
+	r = definition.RawMetrics{}
+	fillContainerSecurity(r, pod, v1.Container{
+		VolumeMounts: []v1.VolumeMount{{Name: "cache"}, {Name: "proc"}},
+	})
+	assert.Equal(t, definition.RawMetrics{
+		"privileged":               false,
+		"allowPrivilegeEscalation": true,
+		"readOnlyRootFilesystem":   false,
+		"runAsNonRoot":             true,
+		"hostPathMounts":           "/proc",
+	}, r)
+
+	r = definition.RawMetrics{}
+	fillContainerSecurity(r, pod, v1.Container{
+		SecurityContext: &v1.SecurityContext{
+			Privileged:               boolPtr(true),
+			AllowPrivilegeEscalation: boolPtr(false),
+			ReadOnlyRootFilesystem:   boolPtr(true),
+			RunAsNonRoot:             boolPtr(false),
+			Capabilities:             &v1.Capabilities{Add: []v1.Capability{"SYS_ADMIN", "NET_ADMIN"}},
+		},
+	})
+	assert.Equal(t, definition.RawMetrics{
+		"privileged":               true,
+		"allowPrivilegeEscalation": false,
+		"readOnlyRootFilesystem":   true,
+		"runAsNonRoot":             false,
+		"capabilitiesAdded":        "NET_ADMIN,SYS_ADMIN",
+	}, r)
+}
@@ -15,17 +15,26 @@ var ExpectedGroupData = definition.RawGroups{
 	},
 	"pod": {
 		"kube-system_newrelic-infra-rz225": {
-			"createdKind": "DaemonSet",
-			"createdBy":   "newrelic-infra",
-			"nodeIP":      "192.168.99.100",
-			"namespace":   "kube-system",
-			"podName":     "newrelic-infra-rz225",
-			"nodeName":    "minikube",
-			"startTime":   parseTime("2018-02-14T16:26:33Z"),
-			"status":      "Running",
-			"isReady":     "True",
-			"isScheduled": "True",
-			"createdAt":   parseTime("2018-02-14T16:26:33Z"),
+			"createdKind":        "DaemonSet",
+			"createdBy":          "newrelic-infra",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "kube-system",
+			"podName":            "newrelic-infra-rz225",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "default",
+			"hostPathVolumes":    "/,/var/run/docker.sock",
+			"startTime":          parseTime("2018-02-14T16:26:33Z"),
+			"status":             "Running",
+			"isReady":            "True",
+			"isScheduled":        "True",
+			"createdAt":          parseTime("2018-02-14T16:26:33Z"),
+			// The pod became ready for the last time long after starting.
+			"schedulingLatencySeconds": 27.0,
+			"startupLatencySeconds":    1119285.0,
 			"labels": map[string]string{
 				"controller-revision-hash": "3887482659",
 				"name":                     "newrelic-infra",
@@ -46,19 +55,29 @@ var ExpectedGroupData = definition.RawGroups{
 					"errors":  uint64(0),
 				},
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":                 "2018-02-27T15:21:31.663551743Z",
+				"kubernetes.io/config.source":               "api",
+				"scheduler.alpha.kubernetes.io/tolerations": "[{\"operator\": \"Exists\", \"effect\": \"NoSchedule\"}]\n",
+			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq": {
-			"createdKind":    "ReplicaSet",
-			"createdBy":      "kube-state-metrics-57f4659995",
-			"nodeIP":         "192.168.99.100",
-			"namespace":      "kube-system",
-			"podName":        "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":       "minikube",
-			"status":         "Running",
-			"isReady":        "True",
-			"isScheduled":    "True",
-			"createdAt":      parseTime("2018-02-14T16:27:38Z"),
-			"deploymentName": "kube-state-metrics",
+			"createdKind":        "ReplicaSet",
+			"createdBy":          "kube-state-metrics-57f4659995",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "kube-system",
+			"podName":            "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "kube-state-metrics",
+			"status":             "Running",
+			"isReady":            "True",
+			"isScheduled":        "True",
+			"createdAt":          parseTime("2018-02-14T16:27:38Z"),
+			"deploymentName":     "kube-state-metrics",
 			"labels": map[string]string{
 				"k8s-app":           "kube-state-metrics",
 				"pod-template-hash": "1390215551",
@@ -78,24 +97,37 @@ var ExpectedGroupData = definition.RawGroups{
 					"errors":  uint64(0),
 				},
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":   "2018-02-27T15:21:31.663544832Z",
+				"kubernetes.io/config.source": "api",
+			},
 		},
 		"default_sh-7c95664875-4btqh": {
-			"createdKind":    "ReplicaSet",
-			"createdBy":      "sh-7c95664875",
-			"nodeIP":         "192.168.99.100",
-			"namespace":      "default",
-			"podName":        "sh-7c95664875-4btqh",
-			"nodeName":       "minikube",
-			"status":         "Failed",
-			"reason":         "Evicted",
-			"message":        "The node was low on resource: memory.",
-			"createdAt":      parseTime("2019-03-13T07:59:00Z"),
-			"startTime":      parseTime("2019-03-13T07:59:00Z"),
-			"deploymentName": "sh",
+			"createdKind":        "ReplicaSet",
+			"createdBy":          "sh-7c95664875",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "default",
+			"podName":            "sh-7c95664875-4btqh",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "default",
+			"status":             "Failed",
+			"reason":             "Evicted",
+			"message":            "The node was low on resource: memory.",
+			"createdAt":          parseTime("2019-03-13T07:59:00Z"),
+			"startTime":          parseTime("2019-03-13T07:59:00Z"),
+			"deploymentName":     "sh",
 			"labels": map[string]string{
 				"pod-template-hash": "3751220431",
 				"run":               "sh",
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":   "2019-03-13T08:03:01.880958599Z",
+				"kubernetes.io/config.source": "api",
+			},
 		},
 		"kube-system_kube-controller-manager-minikube": {
 			"isReady":   "True",
@@ -107,38 +139,57 @@ var ExpectedGroupData = definition.RawGroups{
 				"k8s-app":   "kube-controller-manager",
 				"component": "kube-controller-manager",
 			},
-			"namespace":   "kube-system",
-			"podName":     "kube-controller-manager-minikube",
-			"nodeName":    "minikube",
-			"isScheduled": "True",
+			"namespace":       "kube-system",
+			"podName":         "kube-controller-manager-minikube",
+			"nodeName":        "minikube",
+			"hostNetwork":     true,
+			"hostPID":         false,
+			"hostIPC":         false,
+			"runAsNonRoot":    false,
+			"hostPathVolumes": "/etc/kubernetes/controller-manager.conf,/etc/ssl/certs,/usr/share/ca-certificates,/var/lib/minikube/certs",
+			"isScheduled":     "True",
+			// The static pod has no creation time.
+			"initializationLatencySeconds":  0.0,
+			"containersReadyLatencySeconds": 1.0,
+			"annotations": map[string]string{
+				"kubernetes.io/config.hash":   "38d78cbd438e068d417c11c848b26f09",
+				"kubernetes.io/config.seen":   "2019-10-23T17:10:43.500021033Z",
+				"kubernetes.io/config.source": "file",
+			},
 		},
 	},
 	"container": {
 		"kube-system_newrelic-infra-rz225_newrelic-infra": {
-			"containerName":        "newrelic-infra",
-			"containerID":          "69d7203a8f2d2d027ffa51d61002eac63357f22a17403363ef79e66d1c3146b2",
-			"containerImage":       "newrelic/ohaik:1.0.0-beta3",
-			"containerImageID":     "sha256:1a95d0df2997f93741fbe2a15d2c31a394e752fd942ec29bf16a44163342f6a1",
-			"namespace":            "kube-system",
-			"podName":              "newrelic-infra-rz225",
-			"nodeName":             "minikube",
-			"nodeIP":               "192.168.99.100",
-			"restartCount":         int32(6),
-			"isReady":              true,
-			"status":               "Running",
-			"startedAt":            parseTime("2018-02-27T15:21:16Z"),
-			"cpuRequestedCores":    int64(100),
-			"memoryRequestedBytes": int64(104857600),
-			"memoryLimitBytes":     int64(104857600),
-			"usageBytes":           uint64(18083840),
-			"workingSetBytes":      uint64(17113088),
-			"usageNanoCores":       uint64(17428240),
-			"fsAvailableBytes":     uint64(14924988416),
-			"fsUsedBytes":          uint64(126976),
-			"fsCapacityBytes":      uint64(17293533184),
-			"fsInodesFree":         uint64(9713372),
-			"fsInodes":             uint64(9732096),
-			"fsInodesUsed":         uint64(36),
+			"containerName":            "newrelic-infra",
+			"containerID":              "69d7203a8f2d2d027ffa51d61002eac63357f22a17403363ef79e66d1c3146b2",
+			"containerImage":           "newrelic/ohaik:1.0.0-beta3",
+			"containerType":            "regular",
+			"containerImageID":         "sha256:1a95d0df2997f93741fbe2a15d2c31a394e752fd942ec29bf16a44163342f6a1",
+			"namespace":                "kube-system",
+			"podName":                  "newrelic-infra-rz225",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"hostPathMounts":           "/,/var/run/docker.sock",
+			"nodeIP":                   "192.168.99.100",
+			"restartCount":             int32(6),
+			"isReady":                  true,
+			"status":                   "Running",
+			"startedAt":                parseTime("2018-02-27T15:21:16Z"),
+			"cpuRequestedCores":        int64(100),
+			"memoryRequestedBytes":     int64(104857600),
+			"memoryLimitBytes":         int64(104857600),
+			"usageBytes":               uint64(18083840),
+			"workingSetBytes":          uint64(17113088),
+			"usageNanoCores":           uint64(17428240),
+			"fsAvailableBytes":         uint64(14924988416),
+			"fsUsedBytes":              uint64(126976),
+			"fsCapacityBytes":          uint64(17293533184),
+			"fsInodesFree":             uint64(9713372),
+			"fsInodes":                 uint64(9732096),
+			"fsInodesUsed":             uint64(36),
 			"labels": map[string]string{
 				"controller-revision-hash": "3887482659",
 				"name":                     "newrelic-infra",
@@ -146,14 +197,19 @@ var ExpectedGroupData = definition.RawGroups{
 			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq_kube-state-metrics": {
-			"containerName":    "kube-state-metrics",
-			"containerID":      "c452821fcf6c5f594d4f98a1426e7a2c51febb65d5d50d92903f9dfb367bfba7",
-			"containerImage":   "quay.io/coreos/kube-state-metrics:v1.1.0",
-			"containerImageID": "quay.io/coreos/kube-state-metrics@sha256:52a2c47355c873709bb4e37e990d417e9188c2a778a0c38ed4c09776ddc54efb",
-			"namespace":        "kube-system",
-			"podName":          "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":         "minikube",
-			"nodeIP":           "192.168.99.100",
+			"containerName":            "kube-state-metrics",
+			"containerID":              "c452821fcf6c5f594d4f98a1426e7a2c51febb65d5d50d92903f9dfb367bfba7",
+			"containerImage":           "quay.io/coreos/kube-state-metrics:v1.1.0",
+			"containerType":            "regular",
+			"containerImageID":         "quay.io/coreos/kube-state-metrics@sha256:52a2c47355c873709bb4e37e990d417e9188c2a778a0c38ed4c09776ddc54efb",
+			"namespace":                "kube-system",
+			"podName":                  "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
 			//"restartCount": int32(7), // No restartCount since there is no restartCount in status field in the pod fetched from kubelet /pods.
 			//"isReady":              false, // No isReady since there is no isReady in status field in the pod fetched from kubelet /pods.
 			//"status":         "Running", // No Status since there is no ContainerStatuses field in the pod fetched from kubelet /pods.
@@ -178,14 +234,19 @@ var ExpectedGroupData = definition.RawGroups{
 			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq_addon-resizer": {
-			"containerName":    "addon-resizer",
-			"containerID":      "3328c17bfd22f1a82fcdf8707c2f8f040c462e548c24780079bba95d276d93e1",
-			"containerImage":   "gcr.io/google_containers/addon-resizer:1.0",
-			"containerImageID": "gcr.io/google_containers/addon-resizer@sha256:e77acf80697a70386c04ae3ab494a7b13917cb30de2326dcf1a10a5118eddabe",
-			"namespace":        "kube-system",
-			"podName":          "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":         "minikube",
-			"nodeIP":           "192.168.99.100",
+			"containerName":            "addon-resizer",
+			"containerID":              "3328c17bfd22f1a82fcdf8707c2f8f040c462e548c24780079bba95d276d93e1",
+			"containerImage":           "gcr.io/google_containers/addon-resizer:1.0",
+			"containerType":            "regular",
+			"containerImageID":         "gcr.io/google_containers/addon-resizer@sha256:e77acf80697a70386c04ae3ab494a7b13917cb30de2326dcf1a10a5118eddabe",
+			"namespace":                "kube-system",
+			"podName":                  "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
 			//"restartCount": int32(7), // No restartCount since there is no restartCount in status field in the pod fetched from kubelet /pods.
 			//"isReady":              false, // No isReady since there is no isReady in status field in the pod fetched from kubelet /pods.
 			//"status":         "Running", // No Status since there is no ContainerStatuses field in the pod fetched from kubelet /pods.
@@ -210,13 +271,18 @@ var ExpectedGroupData = definition.RawGroups{
 			},
 		},
 		"default_sh-7c95664875-4btqh_sh": {
-			"containerName":  "sh",
-			"containerImage": "python",
-			"namespace":      "default",
-			"podName":        "sh-7c95664875-4btqh",
-			"nodeName":       "minikube",
-			"nodeIP":         "192.168.99.100",
-			"deploymentName": "sh",
+			"containerName":            "sh",
+			"containerImage":           "python",
+			"containerType":            "regular",
+			"namespace":                "default",
+			"podName":                  "sh-7c95664875-4btqh",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
+			"deploymentName":           "sh",
 			"labels": map[string]string{
 				"pod-template-hash": "3751220431",
 				"run":               "sh",
@@ -225,6 +291,7 @@ var ExpectedGroupData = definition.RawGroups{
 		"kube-system_kube-controller-manager-minikube_kube-controller-manager": {
 			"containerName":     "kube-controller-manager",
 			"containerImage":    "k8s.gcr.io/kube-controller-manager:v1.16.0",
+			"containerType":     "regular",
 			"nodeIP":            "192.168.99.100",
 			"cpuRequestedCores": int64(200),
 			"status":            "Running",
@@ -236,9 +303,14 @@ var ExpectedGroupData = definition.RawGroups{
 				"component": "kube-controller-manager",
 				"tier":      "control-plane",
 			},
-			"namespace": "kube-system",
-			"podName":   "kube-controller-manager-minikube",
-			"nodeName":  "minikube",
+			"namespace":                "kube-system",
+			"podName":                  "kube-controller-manager-minikube",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"hostPathMounts":           "/etc/kubernetes/controller-manager.conf,/etc/ssl/certs,/usr/share/ca-certificates,/var/lib/minikube/certs",
 		},
 	},
 	"node": {
@@ -420,17 +492,26 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 	},
 	"pod": {
 		"kube-system_newrelic-infra-rz225": {
-			"createdKind": "DaemonSet",
-			"createdBy":   "newrelic-infra",
-			"nodeIP":      "192.168.99.100",
-			"namespace":   "kube-system",
-			"podName":     "newrelic-infra-rz225",
-			"nodeName":    "minikube",
-			"startTime":   parseTime("2018-02-14T16:26:33Z"),
-			"status":      "Running",
-			"isReady":     "True",
-			"isScheduled": "True",
-			"createdAt":   parseTime("2018-02-14T16:26:33Z"),
+			"createdKind":        "DaemonSet",
+			"createdBy":          "newrelic-infra",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "kube-system",
+			"podName":            "newrelic-infra-rz225",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "default",
+			"hostPathVolumes":    "/,/var/run/docker.sock",
+			"startTime":          parseTime("2018-02-14T16:26:33Z"),
+			"status":             "Running",
+			"isReady":            "True",
+			"isScheduled":        "True",
+			"createdAt":          parseTime("2018-02-14T16:26:33Z"),
+			// The pod became ready for the last time long after starting.
+			"schedulingLatencySeconds": 27.0,
+			"startupLatencySeconds":    1119285.0,
 			"labels": map[string]string{
 				"controller-revision-hash": "3887482659",
 				"name":                     "newrelic-infra",
@@ -451,19 +532,29 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 					"errors":  uint64(0),
 				},
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":                 "2018-02-27T15:21:31.663551743Z",
+				"kubernetes.io/config.source":               "api",
+				"scheduler.alpha.kubernetes.io/tolerations": "[{\"operator\": \"Exists\", \"effect\": \"NoSchedule\"}]\n",
+			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq": {
-			"createdKind":    "ReplicaSet",
-			"createdBy":      "kube-state-metrics-57f4659995",
-			"nodeIP":         "192.168.99.100",
-			"namespace":      "kube-system",
-			"podName":        "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":       "minikube",
-			"status":         "Running",
-			"isReady":        "True",
-			"isScheduled":    "True",
-			"createdAt":      parseTime("2018-02-14T16:27:38Z"),
-			"deploymentName": "kube-state-metrics",
+			"createdKind":        "ReplicaSet",
+			"createdBy":          "kube-state-metrics-57f4659995",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "kube-system",
+			"podName":            "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "kube-state-metrics",
+			"status":             "Running",
+			"isReady":            "True",
+			"isScheduled":        "True",
+			"createdAt":          parseTime("2018-02-14T16:27:38Z"),
+			"deploymentName":     "kube-state-metrics",
 			"labels": map[string]string{
 				"k8s-app":           "kube-state-metrics",
 				"pod-template-hash": "1390215551",
@@ -483,24 +574,37 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 					"errors":  uint64(0),
 				},
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":   "2018-02-27T15:21:31.663544832Z",
+				"kubernetes.io/config.source": "api",
+			},
 		},
 		"default_sh-7c95664875-4btqh": {
-			"createdKind":    "ReplicaSet",
-			"createdBy":      "sh-7c95664875",
-			"nodeIP":         "192.168.99.100",
-			"namespace":      "default",
-			"podName":        "sh-7c95664875-4btqh",
-			"nodeName":       "minikube",
-			"status":         "Failed",
-			"reason":         "Evicted",
-			"message":        "The node was low on resource: memory.",
-			"createdAt":      parseTime("2019-03-13T07:59:00Z"),
-			"startTime":      parseTime("2019-03-13T07:59:00Z"),
-			"deploymentName": "sh",
+			"createdKind":        "ReplicaSet",
+			"createdBy":          "sh-7c95664875",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "default",
+			"podName":            "sh-7c95664875-4btqh",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "default",
+			"status":             "Failed",
+			"reason":             "Evicted",
+			"message":            "The node was low on resource: memory.",
+			"createdAt":          parseTime("2019-03-13T07:59:00Z"),
+			"startTime":          parseTime("2019-03-13T07:59:00Z"),
+			"deploymentName":     "sh",
 			"labels": map[string]string{
 				"pod-template-hash": "3751220431",
 				"run":               "sh",
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":   "2019-03-13T08:03:01.880958599Z",
+				"kubernetes.io/config.source": "api",
+			},
 		},
 		"kube-system_kube-controller-manager-minikube": {
 			"startTime": parseTime("2019-10-23T17:10:48Z"),
@@ -510,37 +614,53 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 				"k8s-app":   "kube-controller-manager",
 				"component": "kube-controller-manager",
 			},
-			"namespace": "kube-system",
-			"podName":   "kube-controller-manager-minikube",
-			"nodeName":  "minikube",
+			"namespace":       "kube-system",
+			"podName":         "kube-controller-manager-minikube",
+			"nodeName":        "minikube",
+			"hostNetwork":     true,
+			"hostPID":         false,
+			"hostIPC":         false,
+			"runAsNonRoot":    false,
+			"hostPathVolumes": "/etc/kubernetes/controller-manager.conf,/etc/ssl/certs,/usr/share/ca-certificates,/var/lib/minikube/certs",
+			"annotations": map[string]string{
+				"kubernetes.io/config.hash":   "38d78cbd438e068d417c11c848b26f09",
+				"kubernetes.io/config.seen":   "2019-10-23T17:10:43.500021033Z",
+				"kubernetes.io/config.source": "file",
+			},
 		},
 	},
 	"container": {
 		"kube-system_newrelic-infra-rz225_newrelic-infra": {
-			"containerName":        "newrelic-infra",
-			"containerID":          "69d7203a8f2d2d027ffa51d61002eac63357f22a17403363ef79e66d1c3146b2",
-			"containerImage":       "newrelic/ohaik:1.0.0-beta3",
-			"containerImageID":     "sha256:1a95d0df2997f93741fbe2a15d2c31a394e752fd942ec29bf16a44163342f6a1",
-			"namespace":            "kube-system",
-			"podName":              "newrelic-infra-rz225",
-			"nodeName":             "minikube",
-			"nodeIP":               "192.168.99.100",
-			"restartCount":         int32(6),
-			"isReady":              true,
-			"status":               "Running",
-			"startedAt":            parseTime("2018-02-27T15:21:16Z"),
-			"cpuRequestedCores":    int64(100),
-			"memoryRequestedBytes": int64(104857600),
-			"memoryLimitBytes":     int64(104857600),
-			"usageBytes":           uint64(18083840),
-			"workingSetBytes":      uint64(17113088),
-			"usageNanoCores":       uint64(17428240),
-			"fsAvailableBytes":     uint64(14924988416),
-			"fsUsedBytes":          uint64(126976),
-			"fsCapacityBytes":      uint64(17293533184),
-			"fsInodesFree":         uint64(9713372),
-			"fsInodes":             uint64(9732096),
-			"fsInodesUsed":         uint64(36),
+			"containerName":            "newrelic-infra",
+			"containerID":              "69d7203a8f2d2d027ffa51d61002eac63357f22a17403363ef79e66d1c3146b2",
+			"containerImage":           "newrelic/ohaik:1.0.0-beta3",
+			"containerType":            "regular",
+			"containerImageID":         "sha256:1a95d0df2997f93741fbe2a15d2c31a394e752fd942ec29bf16a44163342f6a1",
+			"namespace":                "kube-system",
+			"podName":                  "newrelic-infra-rz225",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"hostPathMounts":           "/,/var/run/docker.sock",
+			"nodeIP":                   "192.168.99.100",
+			"restartCount":             int32(6),
+			"isReady":                  true,
+			"status":                   "Running",
+			"startedAt":                parseTime("2018-02-27T15:21:16Z"),
+			"cpuRequestedCores":        int64(100),
+			"memoryRequestedBytes":     int64(104857600),
+			"memoryLimitBytes":         int64(104857600),
+			"usageBytes":               uint64(18083840),
+			"workingSetBytes":          uint64(17113088),
+			"usageNanoCores":           uint64(17428240),
+			"fsAvailableBytes":         uint64(14924988416),
+			"fsUsedBytes":              uint64(126976),
+			"fsCapacityBytes":          uint64(17293533184),
+			"fsInodesFree":             uint64(9713372),
+			"fsInodes":                 uint64(9732096),
+			"fsInodesUsed":             uint64(36),
 			"labels": map[string]string{
 				"controller-revision-hash": "3887482659",
 				"name":                     "newrelic-infra",
@@ -548,14 +668,19 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq_kube-state-metrics": {
-			"containerName":    "kube-state-metrics",
-			"containerID":      "c452821fcf6c5f594d4f98a1426e7a2c51febb65d5d50d92903f9dfb367bfba7",
-			"containerImage":   "quay.io/coreos/kube-state-metrics:v1.1.0",
-			"containerImageID": "quay.io/coreos/kube-state-metrics@sha256:52a2c47355c873709bb4e37e990d417e9188c2a778a0c38ed4c09776ddc54efb",
-			"namespace":        "kube-system",
-			"podName":          "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":         "minikube",
-			"nodeIP":           "192.168.99.100",
+			"containerName":            "kube-state-metrics",
+			"containerID":              "c452821fcf6c5f594d4f98a1426e7a2c51febb65d5d50d92903f9dfb367bfba7",
+			"containerImage":           "quay.io/coreos/kube-state-metrics:v1.1.0",
+			"containerType":            "regular",
+			"containerImageID":         "quay.io/coreos/kube-state-metrics@sha256:52a2c47355c873709bb4e37e990d417e9188c2a778a0c38ed4c09776ddc54efb",
+			"namespace":                "kube-system",
+			"podName":                  "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
 			//"restartCount": int32(7), // No restartCount since there is no restartCount in status field in the pod fetched from kubelet /pods.
 			//"isReady":              false, // No isReady since there is no isReady in status field in the pod fetched from kubelet /pods.
 			//"status":         "Running", // No Status since there is no ContainerStatuses field in the pod fetched from kubelet /pods.
@@ -580,14 +705,19 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq_addon-resizer": {
-			"containerName":    "addon-resizer",
-			"containerID":      "3328c17bfd22f1a82fcdf8707c2f8f040c462e548c24780079bba95d276d93e1",
-			"containerImage":   "gcr.io/google_containers/addon-resizer:1.0",
-			"containerImageID": "gcr.io/google_containers/addon-resizer@sha256:e77acf80697a70386c04ae3ab494a7b13917cb30de2326dcf1a10a5118eddabe",
-			"namespace":        "kube-system",
-			"podName":          "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":         "minikube",
-			"nodeIP":           "192.168.99.100",
+			"containerName":            "addon-resizer",
+			"containerID":              "3328c17bfd22f1a82fcdf8707c2f8f040c462e548c24780079bba95d276d93e1",
+			"containerImage":           "gcr.io/google_containers/addon-resizer:1.0",
+			"containerType":            "regular",
+			"containerImageID":         "gcr.io/google_containers/addon-resizer@sha256:e77acf80697a70386c04ae3ab494a7b13917cb30de2326dcf1a10a5118eddabe",
+			"namespace":                "kube-system",
+			"podName":                  "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
 			//"restartCount": int32(7), // No restartCount since there is no restartCount in status field in the pod fetched from kubelet /pods.
 			//"isReady":              false, // No isReady since there is no isReady in status field in the pod fetched from kubelet /pods.
 			//"status":         "Running", // No Status since there is no ContainerStatuses field in the pod fetched from kubelet /pods.
@@ -612,13 +742,18 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 			},
 		},
 		"default_sh-7c95664875-4btqh_sh": {
-			"containerName":  "sh",
-			"containerImage": "python",
-			"namespace":      "default",
-			"podName":        "sh-7c95664875-4btqh",
-			"nodeName":       "minikube",
-			"nodeIP":         "192.168.99.100",
-			"deploymentName": "sh",
+			"containerName":            "sh",
+			"containerImage":           "python",
+			"containerType":            "regular",
+			"namespace":                "default",
+			"podName":                  "sh-7c95664875-4btqh",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
+			"deploymentName":           "sh",
 			"labels": map[string]string{
 				"pod-template-hash": "3751220431",
 				"run":               "sh",
@@ -627,6 +762,7 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 		"kube-system_kube-controller-manager-minikube_kube-controller-manager": {
 			"containerName":     "kube-controller-manager",
 			"containerImage":    "k8s.gcr.io/kube-controller-manager:v1.16.0",
+			"containerType":     "regular",
 			"nodeIP":            "192.168.99.100",
 			"cpuRequestedCores": int64(200),
 			"labels": map[string]string{
@@ -634,9 +770,14 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 				"component": "kube-controller-manager",
 				"tier":      "control-plane",
 			},
-			"namespace": "kube-system",
-			"podName":   "kube-controller-manager-minikube",
-			"nodeName":  "minikube",
+			"namespace":                "kube-system",
+			"podName":                  "kube-controller-manager-minikube",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"hostPathMounts":           "/etc/kubernetes/controller-manager.conf,/etc/ssl/certs,/usr/share/ca-certificates,/var/lib/minikube/certs",
 		},
 	},
 	"node": {
@@ -666,7 +807,7 @@ var ExpectedGroupDataWithoutStaticPodsStatus = definition.RawGroups{
 			"txBytes":               uint64(120789968),
 			"usageCoreNanoSeconds":  uint64(22332102208229),
 			"usageNanoCores":        uint64(228759290),
-			"cpuRequestedCores": int64(501),
+			"cpuRequestedCores":     int64(501),
 			"labels": map[string]string{
 				"kubernetes.io/arch":             "amd64",
 				"kubernetes.io/hostname":         "minikube",
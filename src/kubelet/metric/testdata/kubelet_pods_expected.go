@@ -10,81 +10,132 @@ import (
 var ExpectedRawData = definition.RawGroups{
 	"pod": {
 		"kube-system_kube-controller-manager-minikube": {
-			"nodeName":    "minikube",
-			"isReady":     "True",
-			"isScheduled": "True",
-			"nodeIP":      "192.168.99.100",
-			"labels":      map[string]string{"k8s-app": "kube-controller-manager", "component": "kube-controller-manager", "tier": "control-plane"},
-			"namespace":   "kube-system",
-			"podName":     "kube-controller-manager-minikube",
-			"status":      "Running",
-			"startTime":   parseTime("2019-10-23T17:10:48Z"),
+			"nodeName":        "minikube",
+			"hostNetwork":     true,
+			"hostPID":         false,
+			"hostIPC":         false,
+			"runAsNonRoot":    false,
+			"hostPathVolumes": "/etc/kubernetes/controller-manager.conf,/etc/ssl/certs,/usr/share/ca-certificates,/var/lib/minikube/certs",
+			"isReady":         "True",
+			"isScheduled":     "True",
+			"nodeIP":          "192.168.99.100",
+			"labels":          map[string]string{"k8s-app": "kube-controller-manager", "component": "kube-controller-manager", "tier": "control-plane"},
+			"namespace":       "kube-system",
+			"podName":         "kube-controller-manager-minikube",
+			"status":          "Running",
+			"startTime":       parseTime("2019-10-23T17:10:48Z"),
+			// The static pod has no creation time.
+			"initializationLatencySeconds":  0.0,
+			"containersReadyLatencySeconds": 1.0,
+			"annotations": map[string]string{
+				"kubernetes.io/config.hash":   "38d78cbd438e068d417c11c848b26f09",
+				"kubernetes.io/config.seen":   "2019-10-23T17:10:43.500021033Z",
+				"kubernetes.io/config.source": "file",
+			},
 		},
 		"kube-system_newrelic-infra-rz225": {
-			"createdKind": "DaemonSet",
-			"createdBy":   "newrelic-infra",
-			"nodeIP":      "192.168.99.100",
-			"namespace":   "kube-system",
-			"podName":     "newrelic-infra-rz225",
-			"nodeName":    "minikube",
-			"startTime":   parseTime("2018-02-14T16:26:33Z"),
-			"status":      "Running",
-			"isReady":     "True",
-			"isScheduled": "True",
-			"createdAt":   parseTime("2018-02-14T16:26:33Z"),
+			"createdKind":        "DaemonSet",
+			"createdBy":          "newrelic-infra",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "kube-system",
+			"podName":            "newrelic-infra-rz225",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "default",
+			"hostPathVolumes":    "/,/var/run/docker.sock",
+			"startTime":          parseTime("2018-02-14T16:26:33Z"),
+			"status":             "Running",
+			"isReady":            "True",
+			"isScheduled":        "True",
+			"createdAt":          parseTime("2018-02-14T16:26:33Z"),
+			// The pod became ready for the last time long after starting.
+			"schedulingLatencySeconds": 27.0,
+			"startupLatencySeconds":    1119285.0,
 			"labels": map[string]string{
 				"controller-revision-hash": "3887482659",
 				"name":                     "newrelic-infra",
 				"pod-template-generation":  "1",
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":                 "2018-02-27T15:21:31.663551743Z",
+				"kubernetes.io/config.source":               "api",
+				"scheduler.alpha.kubernetes.io/tolerations": "[{\"operator\": \"Exists\", \"effect\": \"NoSchedule\"}]\n",
+			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq": {
-			"createdKind":    "ReplicaSet",
-			"createdBy":      "kube-state-metrics-57f4659995",
-			"nodeIP":         "192.168.99.100",
-			"namespace":      "kube-system",
-			"podName":        "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":       "minikube",
-			"status":         "Running", // Running because is fake pending pod.
-			"isReady":        "True",
-			"isScheduled":    "True",
-			"createdAt":      parseTime("2018-02-14T16:27:38Z"),
-			"deploymentName": "kube-state-metrics",
+			"createdKind":        "ReplicaSet",
+			"createdBy":          "kube-state-metrics-57f4659995",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "kube-system",
+			"podName":            "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "kube-state-metrics",
+			"status":             "Running", // Running because is fake pending pod.
+			"isReady":            "True",
+			"isScheduled":        "True",
+			"createdAt":          parseTime("2018-02-14T16:27:38Z"),
+			"deploymentName":     "kube-state-metrics",
 			"labels": map[string]string{
 				"k8s-app":           "kube-state-metrics",
 				"pod-template-hash": "1390215551",
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":   "2018-02-27T15:21:31.663544832Z",
+				"kubernetes.io/config.source": "api",
+			},
 		},
 		"default_sh-7c95664875-4btqh": {
-			"createdKind":    "ReplicaSet",
-			"createdBy":      "sh-7c95664875",
-			"nodeIP":         "192.168.99.100",
-			"namespace":      "default",
-			"podName":        "sh-7c95664875-4btqh",
-			"nodeName":       "minikube",
-			"status":         "Failed",
-			"reason":         "Evicted",
-			"message":        "The node was low on resource: memory.",
-			"createdAt":      parseTime("2019-03-13T07:59:00Z"),
-			"startTime":      parseTime("2019-03-13T07:59:00Z"),
-			"deploymentName": "sh",
+			"createdKind":        "ReplicaSet",
+			"createdBy":          "sh-7c95664875",
+			"nodeIP":             "192.168.99.100",
+			"namespace":          "default",
+			"podName":            "sh-7c95664875-4btqh",
+			"nodeName":           "minikube",
+			"hostNetwork":        false,
+			"hostPID":            false,
+			"hostIPC":            false,
+			"runAsNonRoot":       false,
+			"serviceAccountName": "default",
+			"status":             "Failed",
+			"reason":             "Evicted",
+			"message":            "The node was low on resource: memory.",
+			"createdAt":          parseTime("2019-03-13T07:59:00Z"),
+			"startTime":          parseTime("2019-03-13T07:59:00Z"),
+			"deploymentName":     "sh",
 			"labels": map[string]string{
 				"pod-template-hash": "3751220431",
 				"run":               "sh",
 			},
+			"annotations": map[string]string{
+				"kubernetes.io/config.seen":   "2019-03-13T08:03:01.880958599Z",
+				"kubernetes.io/config.source": "api",
+			},
 		},
 	},
 	"container": {
 		"kube-system_newrelic-infra-rz225_newrelic-infra": {
-			"containerName":  "newrelic-infra",
-			"containerImage": "newrelic/ohaik:1.0.0-beta3",
-			"namespace":      "kube-system",
-			"podName":        "newrelic-infra-rz225",
-			"nodeName":       "minikube",
-			"nodeIP":         "192.168.99.100",
-			"restartCount":   int32(6),
-			"isReady":        true,
-			"status":         "Running",
+			"containerName":            "newrelic-infra",
+			"containerImage":           "newrelic/ohaik:1.0.0-beta3",
+			"containerType":            "regular",
+			"namespace":                "kube-system",
+			"podName":                  "newrelic-infra-rz225",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"hostPathMounts":           "/,/var/run/docker.sock",
+			"nodeIP":                   "192.168.99.100",
+			"restartCount":             int32(6),
+			"isReady":                  true,
+			"status":                   "Running",
 			//"reason": "", // TODO
 			"startedAt":            parseTime("2018-02-27T15:21:16Z"),
 			"cpuRequestedCores":    int64(100),
@@ -97,12 +148,17 @@ var ExpectedRawData = definition.RawGroups{
 			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq_kube-state-metrics": {
-			"containerName":  "kube-state-metrics",
-			"containerImage": "quay.io/coreos/kube-state-metrics:v1.1.0",
-			"namespace":      "kube-system",
-			"podName":        "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":       "minikube",
-			"nodeIP":         "192.168.99.100",
+			"containerName":            "kube-state-metrics",
+			"containerImage":           "quay.io/coreos/kube-state-metrics:v1.1.0",
+			"containerType":            "regular",
+			"namespace":                "kube-system",
+			"podName":                  "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
 			//"restartCount": int32(7), // No restartCount since there is no restartCount in status field in the pod.
 			//"isReady":              false, // No isReady since there is no isReady in status field in the pod.
 			//"status":         "Running", // No Status since there is no ContainerStatuses field in the pod.
@@ -118,12 +174,17 @@ var ExpectedRawData = definition.RawGroups{
 			},
 		},
 		"kube-system_kube-state-metrics-57f4659995-6n2qq_addon-resizer": {
-			"containerName":  "addon-resizer",
-			"containerImage": "gcr.io/google_containers/addon-resizer:1.0",
-			"namespace":      "kube-system",
-			"podName":        "kube-state-metrics-57f4659995-6n2qq",
-			"nodeName":       "minikube",
-			"nodeIP":         "192.168.99.100",
+			"containerName":            "addon-resizer",
+			"containerImage":           "gcr.io/google_containers/addon-resizer:1.0",
+			"containerType":            "regular",
+			"namespace":                "kube-system",
+			"podName":                  "kube-state-metrics-57f4659995-6n2qq",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
 			//"restartCount": int32(7), // No restartCount since there is no restartCount in status field in the pod.
 			//"isReady":        false, // No isReady since there is no isReady in status field in the pod.
 			//"status":         "Running", // No Status since there is no ContainerStatuses field in the pod.
@@ -140,13 +201,18 @@ var ExpectedRawData = definition.RawGroups{
 			},
 		},
 		"default_sh-7c95664875-4btqh_sh": {
-			"containerName":  "sh",
-			"containerImage": "python",
-			"namespace":      "default",
-			"podName":        "sh-7c95664875-4btqh",
-			"nodeName":       "minikube",
-			"nodeIP":         "192.168.99.100",
-			"deploymentName": "sh",
+			"containerName":            "sh",
+			"containerImage":           "python",
+			"containerType":            "regular",
+			"namespace":                "default",
+			"podName":                  "sh-7c95664875-4btqh",
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"nodeIP":                   "192.168.99.100",
+			"deploymentName":           "sh",
 			"labels": map[string]string{
 				"pod-template-hash": "3751220431",
 				"run":               "sh",
@@ -154,8 +220,13 @@ var ExpectedRawData = definition.RawGroups{
 		},
 
 		"kube-system_kube-controller-manager-minikube_kube-controller-manager": {
-			"nodeName": "minikube",
-			"isReady":  bool(true),
+			"nodeName":                 "minikube",
+			"privileged":               false,
+			"allowPrivilegeEscalation": true,
+			"readOnlyRootFilesystem":   false,
+			"runAsNonRoot":             false,
+			"hostPathMounts":           "/etc/kubernetes/controller-manager.conf,/etc/ssl/certs,/usr/share/ca-certificates,/var/lib/minikube/certs",
+			"isReady":                  bool(true),
 			"labels": map[string]string{
 				"tier":      "control-plane",
 				"k8s-app":   "kube-controller-manager",
@@ -163,6 +234,7 @@ var ExpectedRawData = definition.RawGroups{
 			},
 			"podName":           "kube-controller-manager-minikube",
 			"containerImage":    "k8s.gcr.io/kube-controller-manager:v1.16.0",
+			"containerType":     "regular",
 			"namespace":         "kube-system",
 			"nodeIP":            "192.168.99.100",
 			"cpuRequestedCores": int64(200),
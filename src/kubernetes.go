@@ -9,50 +9,121 @@ import (
 	"time"
 
 	sdkArgs "github.com/newrelic/infra-integrations-sdk/args"
+	"github.com/newrelic/infra-integrations-sdk/cache"
 	"github.com/newrelic/infra-integrations-sdk/log"
 	"github.com/newrelic/infra-integrations-sdk/sdk"
 	"github.com/sirupsen/logrus"
 
+	"github.com/newrelic/nri-kubernetes/src/annotation"
 	"github.com/newrelic/nri-kubernetes/src/apiserver"
+	"github.com/newrelic/nri-kubernetes/src/capacity"
 	"github.com/newrelic/nri-kubernetes/src/client"
 	"github.com/newrelic/nri-kubernetes/src/controlplane"
 	clientControlPlane "github.com/newrelic/nri-kubernetes/src/controlplane/client"
 	"github.com/newrelic/nri-kubernetes/src/data"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/events"
 	"github.com/newrelic/nri-kubernetes/src/featureflag"
 	"github.com/newrelic/nri-kubernetes/src/ksm"
 	clientKsm "github.com/newrelic/nri-kubernetes/src/ksm/client"
 	"github.com/newrelic/nri-kubernetes/src/kubelet"
 	clientKubelet "github.com/newrelic/nri-kubernetes/src/kubelet/client"
 	metric2 "github.com/newrelic/nri-kubernetes/src/kubelet/metric"
+	"github.com/newrelic/nri-kubernetes/src/lifecycle"
 	"github.com/newrelic/nri-kubernetes/src/metric"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
 	"github.com/newrelic/nri-kubernetes/src/network"
+	"github.com/newrelic/nri-kubernetes/src/owner"
+	"github.com/newrelic/nri-kubernetes/src/rate"
+	"github.com/newrelic/nri-kubernetes/src/redact"
 	"github.com/newrelic/nri-kubernetes/src/scrape"
 	"github.com/newrelic/nri-kubernetes/src/storage"
 )
 
 type argumentList struct {
 	sdkArgs.DefaultArgumentList
-	Timeout                      int    `default:"5000" help:"timeout in milliseconds for calling metrics sources"`
-	ClusterName                  string `help:"Identifier of your cluster. You could use it later to filter data in your New Relic account"`
-	DiscoveryCacheDir            string `default:"/var/cache/nr-kubernetes" help:"The location of the cached values for discovered endpoints. Obsolete, use CacheDir instead."`
-	CacheDir                     string `default:"/var/cache/nr-kubernetes" help:"The location where to store various cached data."`
-	DiscoveryCacheTTL            string `default:"1h" help:"Duration since the discovered endpoints are stored in the cache until they expire. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'"`
-	APIServerCacheTTL            string `default:"5m" help:"Duration to cache responses from the API Server. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'. Set to 0s to disable"`
-	APIServerCacheK8SVersionTTL  string `default:"3h" help:"Duration to cache the kubernetes version responses from the API Server. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'. Set to 0s to disable"`
-	EtcdTLSSecretName            string `help:"Name of the secret that stores your ETCD TLS configuration"`
-	EtcdTLSSecretNamespace       string `default:"default" help:"Namespace in which the ETCD TLS secret lives"`
-	DisableKubeStateMetrics      bool   `default:"false" help:"Used to disable KSM data fetching. Defaults to 'false''"`
-	KubeStateMetricsURL          string `help:"kube-state-metrics URL. If it is not provided, it will be discovered."`
-	KubeStateMetricsPodLabel     string `help:"discover KSM using Kubernetes Labels."`
-	KubeStateMetricsPort         int    `default:"8080" help:"port to query the KSM pod. Only works together with the pod label discovery"`
-	KubeStateMetricsScheme       string `default:"http" help:"scheme to query the KSM pod ('http' or 'https'). Only works together with the pod label discovery"`
-	DistributedKubeStateMetrics  bool   `default:"false" help:"Set to enable distributed KSM discovery. Requires that KubeStateMetricsPodLabel is set. Disabled by default."`
-	APIServerSecurePort          string `default:"" help:"Set to query the API Server over a secure port. Disabled by default"`
-	SchedulerEndpointURL         string `help:"Set a custom endpoint URL for the kube-scheduler endpoint."`
-	EtcdEndpointURL              string `help:"Set a custom endpoint URL for the Etcd endpoint."`
-	ControllerManagerEndpointURL string `help:"Set a custom endpoint URL for the kube-controller-manager endpoint."`
-	APIServerEndpointURL         string `help:"Set a custom endpoint URL for the API server endpoint."`
-	NetworkRouteFile             string `help:"Route file to get the default interface from. If left empty on Linux /proc/net/route will be used by default"`
+	Timeout                             int    `default:"5000" help:"timeout in milliseconds for calling metrics sources"`
+	ClusterName                         string `help:"Identifier of your cluster. You could use it later to filter data in your New Relic account"`
+	DiscoveryCacheDir                   string `default:"/var/cache/nr-kubernetes" help:"The location of the cached values for discovered endpoints. Obsolete, use CacheDir instead."`
+	CacheDir                            string `default:"/var/cache/nr-kubernetes" help:"The location where to store various cached data."`
+	DiscoveryCacheTTL                   string `default:"1h" help:"Duration since the discovered endpoints are stored in the cache until they expire. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'"`
+	APIServerCacheTTL                   string `default:"5m" help:"Duration to cache responses from the API Server. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'. Set to 0s to disable"`
+	APIServerCacheK8SVersionTTL         string `default:"3h" help:"Duration to cache the kubernetes version responses from the API Server. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'. Set to 0s to disable"`
+	EtcdTLSSecretName                   string `help:"Name of the secret that stores your ETCD TLS configuration"`
+	EtcdTLSSecretNamespace              string `default:"default" help:"Namespace in which the ETCD TLS secret lives"`
+	EtcdCertFile                        string `help:"Path to the client certificate used to query ETCD"`
+	EtcdKeyFile                         string `help:"Path to the client key used to query ETCD"`
+	EtcdCACertFile                      string `help:"Path to the CA bundle used to verify the ETCD server certificate"`
+	EtcdInsecureSkipVerify              bool   `default:"false" help:"Set to skip the verification of the ETCD server certificate"`
+	EtcdBearerTokenFile                 string `help:"Path to the bearer token used to query ETCD"`
+	SchedulerTLSSecretName              string `help:"Name of the secret that stores your kube-scheduler TLS configuration"`
+	SchedulerTLSSecretNamespace         string `default:"default" help:"Namespace in which the kube-scheduler TLS secret lives"`
+	SchedulerCertFile                   string `help:"Path to the client certificate used to query the kube-scheduler"`
+	SchedulerKeyFile                    string `help:"Path to the client key used to query the kube-scheduler"`
+	SchedulerCACertFile                 string `help:"Path to the CA bundle used to verify the kube-scheduler server certificate"`
+	SchedulerInsecureSkipVerify         bool   `default:"false" help:"Set to skip the verification of the kube-scheduler server certificate"`
+	SchedulerBearerTokenFile            string `help:"Path to the bearer token used to query the kube-scheduler"`
+	ControllerManagerTLSSecretName      string `help:"Name of the secret that stores your kube-controller-manager TLS configuration"`
+	ControllerManagerTLSSecretNamespace string `default:"default" help:"Namespace in which the kube-controller-manager TLS secret lives"`
+	ControllerManagerCertFile           string `help:"Path to the client certificate used to query the kube-controller-manager"`
+	ControllerManagerKeyFile            string `help:"Path to the client key used to query the kube-controller-manager"`
+	ControllerManagerCACertFile         string `help:"Path to the CA bundle used to verify the kube-controller-manager server certificate"`
+	ControllerManagerInsecureSkipVerify bool   `default:"false" help:"Set to skip the verification of the kube-controller-manager server certificate"`
+	ControllerManagerBearerTokenFile    string `help:"Path to the bearer token used to query the kube-controller-manager"`
+	APIServerTLSSecretName              string `help:"Name of the secret that stores your API server TLS configuration"`
+	APIServerTLSSecretNamespace         string `default:"default" help:"Namespace in which the API server TLS secret lives"`
+	APIServerCertFile                   string `help:"Path to the client certificate used to query the API server"`
+	APIServerKeyFile                    string `help:"Path to the client key used to query the API server"`
+	APIServerCACertFile                 string `help:"Path to the CA bundle used to verify the API server certificate"`
+	APIServerInsecureSkipVerify         bool   `default:"false" help:"Set to skip the verification of the API server certificate"`
+	APIServerBearerTokenFile            string `help:"Path to the bearer token used to query the API server"`
+	DisableKubeStateMetrics             bool   `default:"false" help:"Used to disable KSM data fetching. Defaults to 'false''"`
+	KubeStateMetricsURL                 string `help:"kube-state-metrics URL. If it is not provided, it will be discovered."`
+	KubeStateMetricsPodLabel            string `help:"discover KSM using Kubernetes Labels."`
+	KubeStateMetricsPort                int    `default:"8080" help:"port to query the KSM pod. Only works together with the pod label discovery"`
+	KubeStateMetricsScheme              string `default:"http" help:"scheme to query the KSM pod ('http' or 'https'). Only works together with the pod label discovery"`
+	DistributedKubeStateMetrics         bool   `default:"false" help:"Set to enable distributed KSM discovery. Requires that KubeStateMetricsPodLabel is set. Disabled by default."`
+	APIServerSecurePort                 string `default:"" help:"Set to query the API Server over a secure port. Disabled by default"`
+	SchedulerEndpointURL                string `help:"Set a custom endpoint URL for the kube-scheduler endpoint."`
+	EtcdEndpointURL                     string `help:"Set a custom endpoint URL for the Etcd endpoint."`
+	ControllerManagerEndpointURL        string `help:"Set a custom endpoint URL for the kube-controller-manager endpoint."`
+	APIServerEndpointURL                string `help:"Set a custom endpoint URL for the API server endpoint."`
+	NetworkRouteFile                    string `help:"Route file to get the default interface from. If left empty on Linux /proc/net/route will be used by default"`
+	DimensionalMetrics                  bool   `default:"false" help:"Set to also report labelled control plane metrics in one sample per label combination, with the labels as attributes. Disabled by default."`
+	CounterRates                        bool   `default:"false" help:"Set to compute the DELTA and RATE metrics in the integration, keeping the previous counter values in the cache directory, and report them as gauges. Disabled by default."`
+	CounterRatesTTL                     string `default:"10m" help:"Duration since a counter was last sampled until its previous value expires. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'"`
+	PrometheusListenAddress             string `default:"" help:"Set to serve the metrics in the Prometheus format at /metrics on this address, e.g. ':9877', instead of publishing them. The integration keeps running and populates them every PrometheusInterval. Disabled by default."`
+	PrometheusInterval                  string `default:"30s" help:"Interval between the populations of the metrics served in the Prometheus format. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'"`
+	OTLPEndpoint                        string `default:"" help:"Set to also export the metrics to the OpenTelemetry collector at this endpoint, e.g. 'http://otel-collector:4317'. The scheme determines whether TLS is used. Disabled by default."`
+	OTLPProtocol                        string `default:"grpc" help:"Protocol used to export the metrics to the OTLPEndpoint: 'grpc' or 'http'."`
+	NDJSONFile                          string `default:"" help:"Set to also append the metrics of every run to this file, as one JSON object per sample and line. Disabled by default."`
+	NDJSONMaxSizeMB                     int    `default:"100" help:"Size in megabytes after which the NDJSONFile is rotated. Set to 0 to disable."`
+	NDJSONRotationInterval              string `default:"24h" help:"Duration after which the NDJSONFile is rotated, regardless of its size. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'. Set to 0s to disable."`
+	NDJSONMaxAge                        string `default:"168h" help:"Duration after which the rotated NDJSONFile files are removed. Valid time units: 'ns', 'us', 'ms', 's', 'm', 'h'. Set to 0s to disable."`
+	NDJSONOnly                          bool   `default:"false" help:"Set to only write the metrics to the NDJSONFile, without publishing them to the agent."`
+	PublishMaxEntities                  int    `default:"0" help:"Set to publish the metrics in several payloads with at most this number of entities each. The cluster entity is published in a dedicated payload. Disabled by default."`
+	PublishMaxSizeKB                    int    `default:"0" help:"Set to publish the metrics in several payloads of at most this size in kilobytes each. The cluster entity is published in a dedicated payload. Disabled by default."`
+	NamespaceInclude                    string `default:"" help:"Comma-separated list of the only namespaces to monitor. Disabled by default."`
+	NamespaceIncludeRegex               string `default:"" help:"Regular expression matching the only namespaces to monitor, besides the ones in NamespaceInclude. Disabled by default."`
+	NamespaceLabelSelector              string `default:"" help:"Label selector of the only namespaces to monitor, besides the ones in NamespaceInclude, e.g. 'team=payments'. Disabled by default."`
+	NamespaceExclude                    string `default:"" help:"Comma-separated list of namespaces not to monitor. Takes precedence over the namespaces to monitor. Disabled by default."`
+	NamespaceExcludeRegex               string `default:"" help:"Regular expression matching the namespaces not to monitor. Takes precedence over the namespaces to monitor. Disabled by default."`
+	AnnotationKeys                      string `default:"" help:"Comma-separated list of the annotations of pods, deployments and namespaces to report as annotation.* attributes. Disabled by default."`
+	AnnotationPrefixes                  string `default:"" help:"Comma-separated list of prefixes of the annotations of pods, deployments and namespaces to report as annotation.* attributes, e.g. 'example.com/'. Disabled by default."`
+	AnnotationMaxLength                 int    `default:"256" help:"Length in characters after which the values of the reported annotations are truncated. Set to 0 to disable."`
+	RedactionRulesFile                  string `default:"" help:"Path of a YAML file with the rules to drop, hash or mask sensitive attributes before they are reported. Disabled by default."`
+	AttributeMaxCount                   int    `default:"200" help:"Max number of attributes derived from labels, selectors, resources and labelled Prometheus metrics per sample, like label.*. The ones over it are dropped. Set to 0 to disable."`
+	AttributeDenyRegex                  string `default:"" help:"Regular expression matching the names of the attributes derived from labels, selectors, resources and labelled Prometheus metrics never to report, e.g. '^label\\.pod-template-hash$'. Disabled by default."`
+	AttributeMaxValueLength             int    `default:"4096" help:"Length in characters after which the values of the attributes derived from labels, selectors, resources and labelled Prometheus metrics are truncated. Set to 0 to disable."`
+	WorkloadRollups                     bool   `default:"false" help:"Set to report the CPU and memory usage, requests and limits of the containers of each node summed per deployment, statefulset, daemonset and namespace."`
+	ClusterCapacity                     bool   `default:"false" help:"Set to report the allocatable and requested CPU, memory and pods of the nodes of the cluster. It is reported by the integration running on the node of kube-state-metrics, so not when it is distributed."`
+	ClusterCapacityNodeLabel            string `default:"" help:"Label of the nodes to also report the cluster capacity per value of, e.g. the node pool or 'node.kubernetes.io/instance-type'. Disabled by default."`
+	AggregatesIncludeFilteredNamespaces bool   `default:"false" help:"Set to still count the pods of the namespaces filtered out by the namespace filter in the cluster-wide aggregates, like the cluster capacity."`
+	KubernetesEvents                    bool   `default:"false" help:"Set to report the Kubernetes events as events of the entities of their involved objects. They are reported by the integration running on the node of kube-state-metrics, so not when it is distributed."`
+	EventTypes                          string `default:"" help:"Comma-separated list of the only types of Kubernetes events to report, e.g. 'Warning'. Disabled by default."`
+	EventReasons                        string `default:"" help:"Comma-separated list of the only reasons of Kubernetes events to report, e.g. 'FailedScheduling,BackOff'. Disabled by default."`
+	EventExcludeReasons                 string `default:"" help:"Comma-separated list of reasons of Kubernetes events not to report. Takes precedence over EventReasons. Disabled by default."`
+	LifecycleEvents                     bool   `default:"false" help:"Set to report the transitions of the pods, containers and nodes between runs as events, like PodStatusChanged, ContainerRestarted or EntityDisappeared."`
 }
 
 const (
@@ -64,10 +135,17 @@ const (
 	discoveryCacheDir           = "discovery"
 	apiserverCacheDir           = "apiserver"
 	apiserverCacheDirK8sVersion = "apiserverK8SVersion"
+	counterRatesCacheDir        = "counters"
+	eventsCacheDir              = "events"
+	lifecycleCacheDir           = "lifecycle"
 
 	defaultAPIServerCacheTTL           = time.Minute * 5
 	defaultAPIServerCacheK8SVersionTTL = time.Hour * 3
 	defaultDiscoveryCacheTTL           = time.Hour
+	defaultCounterRatesTTL             = time.Minute * 10
+	defaultPrometheusInterval          = time.Second * 30
+	defaultNDJSONMaxAge                = time.Hour * 168
+	defaultNDJSONRotationInterval      = time.Hour * 24
 
 	integrationName    = "com.newrelic.kubernetes"
 	integrationVersion = "2.4.0"
@@ -95,13 +173,13 @@ func controlPlaneJobs(
 	nodeIP string,
 	podsFetcher data.FetchFunc,
 	k8sClient client.Kubernetes,
-	etcdTLSSecretName string,
-	etcdTLSSecretNamespace string,
+	authConfigs map[controlplane.ComponentName]controlplane.AuthConfig,
 	apiServerSecurePort string,
 	schedulerEndpointURL string,
 	etcdEndpointURL string,
 	controllerManagerEndpointURL string,
 	apiServerEndpointURL string,
+	jobOptions ...scrape.JobOption,
 ) ([]*scrape.Job, error) {
 
 	nodeInfo, err := apiServerClient.GetNodeInfo(nodeName)
@@ -114,8 +192,8 @@ func controlPlaneJobs(
 	}
 
 	var opts []controlplane.ComponentOption
-	if etcdTLSSecretName != "" {
-		opts = append(opts, controlplane.WithEtcdTLSConfig(etcdTLSSecretName, etcdTLSSecretNamespace))
+	for name, authConfig := range authConfigs {
+		opts = append(opts, controlplane.WithAuthConfig(name, authConfig))
 	}
 
 	// Make sure API Server Secure port is used first for backwards compatibility.
@@ -169,19 +247,93 @@ func controlPlaneJobs(
 			component.Queries,
 			logger,
 			c.PodName,
+			component.GroupsByLabel,
 		)
 		jobs = append(
 			jobs,
-			scrape.NewScrapeJob(string(component.Name), componentGrouper, component.Specs),
+			scrape.NewScrapeJob(string(component.Name), componentGrouper, component.Specs, jobOptions...),
 		)
 	}
 
 	return jobs, nil
 }
 
+// controlPlaneAuthConfigs returns the TLS and authentication settings of the
+// control plane components, as given in the arguments.
+func controlPlaneAuthConfigs() map[controlplane.ComponentName]controlplane.AuthConfig {
+	return map[controlplane.ComponentName]controlplane.AuthConfig{
+		controlplane.Etcd: {
+			TLSSecretName:      args.EtcdTLSSecretName,
+			TLSSecretNamespace: args.EtcdTLSSecretNamespace,
+			CertFile:           args.EtcdCertFile,
+			KeyFile:            args.EtcdKeyFile,
+			CACertFile:         args.EtcdCACertFile,
+			InsecureSkipVerify: args.EtcdInsecureSkipVerify,
+			BearerTokenFile:    args.EtcdBearerTokenFile,
+		},
+		controlplane.Scheduler: {
+			TLSSecretName:      args.SchedulerTLSSecretName,
+			TLSSecretNamespace: args.SchedulerTLSSecretNamespace,
+			CertFile:           args.SchedulerCertFile,
+			KeyFile:            args.SchedulerKeyFile,
+			CACertFile:         args.SchedulerCACertFile,
+			InsecureSkipVerify: args.SchedulerInsecureSkipVerify,
+			BearerTokenFile:    args.SchedulerBearerTokenFile,
+		},
+		controlplane.ControllerManager: {
+			TLSSecretName:      args.ControllerManagerTLSSecretName,
+			TLSSecretNamespace: args.ControllerManagerTLSSecretNamespace,
+			CertFile:           args.ControllerManagerCertFile,
+			KeyFile:            args.ControllerManagerKeyFile,
+			CACertFile:         args.ControllerManagerCACertFile,
+			InsecureSkipVerify: args.ControllerManagerInsecureSkipVerify,
+			BearerTokenFile:    args.ControllerManagerBearerTokenFile,
+		},
+		controlplane.APIServer: {
+			TLSSecretName:      args.APIServerTLSSecretName,
+			TLSSecretNamespace: args.APIServerTLSSecretNamespace,
+			CertFile:           args.APIServerCertFile,
+			KeyFile:            args.APIServerKeyFile,
+			CACertFile:         args.APIServerCACertFile,
+			InsecureSkipVerify: args.APIServerInsecureSkipVerify,
+			BearerTokenFile:    args.APIServerBearerTokenFile,
+		},
+	}
+}
+
+func getNamespaceFilterConfig() namespace.Config {
+	return namespace.Config{
+		Include:       splitList(args.NamespaceInclude),
+		IncludeRegex:  args.NamespaceIncludeRegex,
+		LabelSelector: args.NamespaceLabelSelector,
+		Exclude:       splitList(args.NamespaceExclude),
+		ExcludeRegex:  args.NamespaceExcludeRegex,
+	}
+}
+
+func getAnnotationAllowlist() annotation.Allowlist {
+	return annotation.Allowlist{
+		Keys:      splitList(args.AnnotationKeys),
+		Prefixes:  splitList(args.AnnotationPrefixes),
+		MaxLength: args.AnnotationMaxLength,
+	}
+}
+
+// splitList splits a comma-separated list argument, ignoring the empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func main() {
-	integration, err := sdk.NewIntegrationProtocol2(integrationName, integrationVersion, &args)
-	var jobs []*scrape.Job
+	// The chunked payloads are written to the same output as the integration.
+	output := os.Stdout
+	integration, err := sdk.NewIntegrationProtocol2WithWriter(integrationName, integrationVersion, &args, output)
 	exitLog := fmt.Sprintf("Integration %q exited", integrationName)
 	if err != nil {
 		defer log.Debug(exitLog)
@@ -215,6 +367,37 @@ func main() {
 		return
 	}
 
+	if args.PrometheusListenAddress != "" {
+		servePrometheus(integration, logger, nodeName)
+		return
+	}
+
+	if populate(integration, logger, nodeName) == 0 {
+		logger.Panic("No data was populated")
+	}
+
+	exportOTLP(integration, logger)
+	writeNDJSON(integration, logger)
+
+	if args.NDJSONFile != "" && args.NDJSONOnly {
+		// Publish would also store the values of the SDK DELTA and RATE metrics.
+		if err := cache.Save(); err != nil {
+			logger.Panic(err)
+		}
+		return
+	}
+
+	if err := publish(integration, logger, output); err != nil {
+		logger.Panic(err)
+	}
+}
+
+// populate discovers the data sources and runs all the scrape jobs, which
+// populate the integration. It returns the number of jobs which populated
+// any data.
+func populate(integration *sdk.IntegrationProtocol2, logger *logrus.Logger, nodeName string) int {
+	var jobs []*scrape.Job
+
 	ttl, err := time.ParseDuration(args.DiscoveryCacheTTL)
 	if err != nil {
 		logger.WithError(err).Errorf("while parsing the cache TTL value. Defaulting to %s", defaultDiscoveryCacheTTL)
@@ -243,13 +426,106 @@ func main() {
 	kubeletNodeIP := kubeletClient.NodeIP()
 	logger.Debugf("Kubelet node IP = %s", kubeletNodeIP)
 
+	var jobOptions []scrape.JobOption
+	var counterRates *rate.Calculator
+	if args.CounterRates {
+		counterRatesTTL, err := time.ParseDuration(args.CounterRatesTTL)
+		if err != nil {
+			logger.WithError(err).Errorf("while parsing the counter rates TTL value. Defaulting to %s", defaultCounterRatesTTL)
+			counterRatesTTL = defaultCounterRatesTTL
+		}
+
+		counterRates = rate.NewCalculator(
+			storage.NewJSONDiskStorage(getCacheDir(counterRatesCacheDir)),
+			counterRatesTTL,
+			logger,
+		)
+		jobOptions = append(jobOptions, scrape.WithCounterSampler(counterRates))
+	}
+
+	var redactor *redact.Redactor
+	if args.RedactionRulesFile != "" {
+		rules, err := redact.LoadRules(args.RedactionRulesFile)
+		if err != nil {
+			logger.Panic(err)
+		}
+		redactor, err = redact.NewRedactor(rules)
+		if err != nil {
+			logger.Panic(err)
+		}
+		jobOptions = append(jobOptions, scrape.WithRedactor(redactor))
+	}
+
+	attributeLimits, err := definition.NewAttributeLimits(args.AttributeMaxCount, args.AttributeDenyRegex, args.AttributeMaxValueLength)
+	if err != nil {
+		logger.Panic(err)
+	}
+	jobOptions = append(jobOptions, scrape.WithAttributeLimits(attributeLimits))
+
 	k8s, err := client.NewKubernetes(false)
 	if err != nil {
 		logger.Panic(err)
 	}
 
+	apiServerClient := apiserver.NewClient(k8s)
+
+	ttlAPIServerCacheK8SVersion, err := time.ParseDuration(args.APIServerCacheK8SVersionTTL)
+
+	if err != nil {
+		logger.WithError(err).Errorf(
+			"while parsing the api server cache TTL value for the kubernetes server version. Defaulting to %s",
+			defaultAPIServerCacheK8SVersionTTL,
+		)
+		ttlAPIServerCacheK8SVersion = defaultAPIServerCacheK8SVersionTTL
+	}
+
+	var apiServerClientK8sVersion apiserver.Client
+	if ttlAPIServerCacheK8SVersion != time.Duration(0) {
+		apiServerClientK8sVersion = apiserver.NewFileCacheClientWrapper(
+			apiServerClient,
+			getCacheDir(apiserverCacheDirK8sVersion),
+			ttlAPIServerCacheK8SVersion,
+		)
+	} else {
+		apiServerClientK8sVersion = apiServerClient
+	}
+	k8sVersion, err := apiServerClientK8sVersion.GetServerVersion()
+	if err != nil {
+		logger.WithError(err).Errorf("getting the kubernetes server version")
+	}
+	enableStaticPodsStatus := featureflag.StaticPodsStatus(k8sVersion)
+
+	ttlAPIServerCache, err := time.ParseDuration(args.APIServerCacheTTL)
+	if err != nil {
+		logger.WithError(err).Errorf("while parsing the api server cache TTL value. Defaulting to %s", defaultAPIServerCacheTTL)
+		ttlAPIServerCache = defaultAPIServerCacheTTL
+	}
+
+	if ttlAPIServerCache != time.Duration(0) {
+		apiServerClient = apiserver.NewFileCacheClientWrapper(apiServerClient,
+			getCacheDir(apiserverCacheDir),
+			ttlAPIServerCache)
+	}
+
+	// The namespace filter, annotations and workloads only apply to the KSM and kubelet jobs, the control plane
+	// components are cluster-wide. It is a new slice, as the control plane jobs append their own options to jobOptions.
+	objectJobOptions := append([]scrape.JobOption{
+		scrape.WithAnnotationAllowlist(getAnnotationAllowlist()),
+		scrape.WithOwnerResolver(owner.NewResolver(apiServerClient, logger)),
+	}, jobOptions...)
+	var namespaceFilter *namespace.Filter
+	if namespaceFilterConfig := getNamespaceFilterConfig(); namespaceFilterConfig.IsSet() {
+		namespaceFilter, err = namespace.NewFilter(namespaceFilterConfig, apiServerClient)
+		if err != nil {
+			logger.Panic(err)
+		}
+		objectJobOptions = append(objectJobOptions, scrape.WithNamespaceFilter(namespaceFilter))
+	}
+
+	var ksmClients []client.HTTPClient
+	// The cluster-wide data is reported once, by the integration scraping KSM when it is not distributed.
+	var onKSMNode bool
 	if !args.DisableKubeStateMetrics {
-		var ksmClients []client.HTTPClient
 		var ksmNodeIP string
 		if args.DistributedKubeStateMetrics {
 			ksmDiscoverer, err := getMultiKSMDiscoverer(kubeletNodeIP, logger)
@@ -280,53 +556,39 @@ func main() {
 			// we only scrape KSM when we are on the same Node as KSM
 			if kubeletNodeIP == ksmNodeIP {
 				ksmClients = append(ksmClients, ksmClient)
+				onKSMNode = true
 			}
 		}
 		logger.Debugf("KSM Node = %s", ksmNodeIP)
 		for _, ksmClient := range ksmClients {
 			ksmGrouper := ksm.NewGrouper(ksmClient, metric.KSMQueries, logger, k8s)
-			jobs = append(jobs, scrape.NewScrapeJob("kube-state-metrics", ksmGrouper, metric.KSMSpecs))
+			jobs = append(jobs, scrape.NewScrapeJob("kube-state-metrics", ksmGrouper, metric.KSMSpecs, objectJobOptions...))
 		}
 	}
 
-	apiServerClient := apiserver.NewClient(k8s)
-
-	ttlAPIServerCacheK8SVersion, err := time.ParseDuration(args.APIServerCacheK8SVersionTTL)
-
-	if err != nil {
-		logger.WithError(err).Errorf(
-			"while parsing the api server cache TTL value for the kubernetes server version. Defaulting to %s",
-			defaultAPIServerCacheK8SVersionTTL,
-		)
-		ttlAPIServerCacheK8SVersion = defaultAPIServerCacheK8SVersionTTL
-	}
-
-	var apiServerClientK8sVersion apiserver.Client
-	if ttlAPIServerCacheK8SVersion != time.Duration(0) {
-		apiServerClientK8sVersion = apiserver.NewFileCacheClientWrapper(
-			apiServerClient,
-			getCacheDir(apiserverCacheDirK8sVersion),
-			ttlAPIServerCacheK8SVersion,
+	if args.ClusterCapacity && onKSMNode {
+		aggregatesFilter := namespaceFilter
+		if args.AggregatesIncludeFilteredNamespaces {
+			aggregatesFilter = nil
+		}
+		capacityGrouper := capacity.NewGrouper(k8s, logger, args.ClusterName, args.ClusterCapacityNodeLabel, aggregatesFilter)
+		jobs = append(jobs, scrape.NewScrapeJob("cluster-capacity", capacityGrouper, metric.ClusterCapacitySpecs, jobOptions...))
+	}
+
+	var eventsJob *events.Job
+	if args.KubernetesEvents && onKSMNode {
+		eventsJob = events.NewJob(
+			k8s,
+			storage.NewJSONDiskStorage(getCacheDir(eventsCacheDir)),
+			events.Filter{
+				Types:          splitList(args.EventTypes),
+				Reasons:        splitList(args.EventReasons),
+				ExcludeReasons: splitList(args.EventExcludeReasons),
+			},
+			namespaceFilter,
+			redactor,
+			logger,
 		)
-	} else {
-		apiServerClientK8sVersion = apiServerClient
-	}
-	k8sVersion, err := apiServerClientK8sVersion.GetServerVersion()
-	if err != nil {
-		logger.WithError(err).Errorf("getting the kubernetes server version")
-	}
-	enableStaticPodsStatus := featureflag.StaticPodsStatus(k8sVersion)
-
-	ttlAPIServerCache, err := time.ParseDuration(args.APIServerCacheTTL)
-	if err != nil {
-		logger.WithError(err).Errorf("while parsing the api server cache TTL value. Defaulting to %s", defaultAPIServerCacheTTL)
-		ttlAPIServerCache = defaultAPIServerCacheTTL
-	}
-
-	if ttlAPIServerCache != time.Duration(0) {
-		apiServerClient = apiserver.NewFileCacheClientWrapper(apiServerClient,
-			getCacheDir(apiserverCacheDir),
-			ttlAPIServerCache)
 	}
 
 	podsFetcher := metric2.NewPodsFetcher(logger, kubeletClient, enableStaticPodsStatus).FetchFuncWithCache()
@@ -338,13 +600,13 @@ func main() {
 		kubeletNodeIP,
 		podsFetcher,
 		k8s,
-		args.EtcdTLSSecretName,
-		args.EtcdTLSSecretNamespace,
+		controlPlaneAuthConfigs(),
 		args.APIServerSecurePort,
 		args.SchedulerEndpointURL,
 		args.EtcdEndpointURL,
 		args.ControllerManagerEndpointURL,
 		args.APIServerEndpointURL,
+		append(jobOptions, scrape.WithDimensionalMetrics(args.DimensionalMetrics))...,
 	)
 
 	if err != nil {
@@ -361,8 +623,14 @@ func main() {
 		defaultNetworkInterface,
 		podsFetcher,
 		metric2.CadvisorFetchFunc(kubeletClient, metric.CadvisorQueries),
+		// KSM jobs run before the kubelet one, so their certificates are already recorded.
+		ksm.NewCertificatesFetchFunc(nodeName, ksmClients),
 	)
-	jobs = append(jobs, scrape.NewScrapeJob("kubelet", kubeletGrouper, metric.KubeletSpecs))
+	kubeletJobOptions := objectJobOptions
+	if args.WorkloadRollups {
+		kubeletJobOptions = append(kubeletJobOptions, scrape.WithRollups(metric.KubeletRollupSpecs))
+	}
+	jobs = append(jobs, scrape.NewScrapeJob("kubelet", kubeletGrouper, metric.KubeletSpecs, kubeletJobOptions...))
 
 	successfulJobs := 0
 	for _, job := range jobs {
@@ -381,13 +649,36 @@ func main() {
 		}
 	}
 
-	if successfulJobs == 0 {
-		logger.Panic("No data was populated")
+	// The events are not populated from samples, so they are not a scrape job.
+	if eventsJob != nil {
+		result := eventsJob.Populate(integration, args.ClusterName)
+		if result.Populated {
+			successfulJobs++
+		}
+		if len(result.Errors) > 0 {
+			logger.WithFields(logrus.Fields{"phase": "populate", "datasource": eventsJob.Name}).Debug(result.Error())
+		}
 	}
 
-	if err := integration.Publish(); err != nil {
-		logger.Panic(err)
+	// The lifecycle transitions are diffed from the entities populated by the jobs, so they are tracked after them.
+	if args.LifecycleEvents {
+		var clusterClient client.Kubernetes
+		if onKSMNode {
+			clusterClient = k8s
+		}
+		tracker := lifecycle.NewTracker(storage.NewJSONDiskStorage(getCacheDir(lifecycleCacheDir)), nodeName, clusterClient, logger)
+		if result := tracker.Populate(integration, args.ClusterName); len(result.Errors) > 0 {
+			logger.WithFields(logrus.Fields{"phase": "populate", "datasource": "lifecycle"}).Debug(result.Error())
+		}
 	}
+
+	if counterRates != nil {
+		if err := counterRates.Flush(); err != nil {
+			logger.WithError(err).Warn("storing the counter values for the next run")
+		}
+	}
+
+	return successfulJobs
 }
 
 func getKSMDiscoverer(logger *logrus.Logger) (client.Discoverer, error) {
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
+	sdkArgs "github.com/newrelic/infra-integrations-sdk/args"
 	"github.com/newrelic/nri-kubernetes/src/apiserver"
 	"github.com/newrelic/nri-kubernetes/src/controlplane"
 	"github.com/newrelic/nri-kubernetes/src/definition"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var logger = logrus.StandardLogger()
@@ -70,8 +74,9 @@ func TestControlPlaneJobs(t *testing.T) {
 		nodeIP,
 		podsFetcher,
 		nil,
-		"test",
-		"",
+		map[controlplane.ComponentName]controlplane.AuthConfig{
+			controlplane.Etcd: {TLSSecretName: "test"},
+		},
 		"",
 		"",
 		"",
@@ -95,3 +100,44 @@ func TestControlPlaneJobs(t *testing.T) {
 		}
 	}
 }
+
+// setupDefaultArgs parses the arguments of the integration from an empty
+// command line, as the SDK does on startup.
+func setupDefaultArgs(t *testing.T) {
+	previous, previousArgs, previousFlags := args, os.Args, flag.CommandLine
+	t.Cleanup(func() {
+		args, os.Args, flag.CommandLine = previous, previousArgs, previousFlags
+	})
+
+	args = argumentList{}
+	os.Args = []string{"nri-kubernetes"}
+	flag.CommandLine = flag.NewFlagSet("nri-kubernetes", flag.ContinueOnError)
+	require.NoError(t, sdkArgs.SetupArgs(&args))
+}
+
+func TestArgumentList_DefaultArgs(t *testing.T) {
+	// The arguments must not collide with the ones of the SDK, e.g. events.
+	setupDefaultArgs(t)
+	assert.False(t, args.KubernetesEvents)
+	assert.False(t, args.Events)
+}
+
+func TestGetAnnotationAllowlist_DefaultArgsReportNoAnnotation(t *testing.T) {
+	setupDefaultArgs(t)
+
+	groups := definition.RawGroups{
+		"pod": {
+			"default_nginx": {
+				"podName": "nginx",
+				"annotations": map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"Pod"}`,
+				},
+			},
+		},
+	}
+
+	allowlist := getAnnotationAllowlist()
+	assert.False(t, allowlist.IsSet())
+	assert.Equal(t, 256, allowlist.MaxLength)
+	assert.NotContains(t, allowlist.FilterGroups(groups)["pod"]["default_nginx"], "annotations")
+}
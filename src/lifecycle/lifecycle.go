@@ -0,0 +1,336 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/data"
+	"github.com/newrelic/nri-kubernetes/src/storage"
+)
+
+const storageKey = "lifecycle"
+
+// Category is the category of the reported events.
+const Category = "lifecycle"
+
+// Transitions reported as the first word of the summary of the events.
+const (
+	PodStatusChanged       = "PodStatusChanged"
+	ContainerStatusChanged = "ContainerStatusChanged"
+	ContainerRestarted     = "ContainerRestarted"
+	NodeStatusChanged      = "NodeStatusChanged"
+	EntityDisappeared      = "EntityDisappeared"
+)
+
+// Kinds of the tracked entities.
+const (
+	podKind       = "pod"
+	containerKind = "container"
+	nodeKind      = "node"
+)
+
+// trackedSamples are the attributes kept of the entities of each sample.
+var trackedSamples = map[string]struct {
+	kind       string
+	attributes []string
+}{
+	"K8sPodSample":       {kind: podKind, attributes: []string{"status", "reason", "isReady"}},
+	"K8sContainerSample": {kind: containerKind, attributes: []string{"status", "reason", "restartCount"}},
+}
+
+// nodeSample is the sample whose presence shows that the kubelet of the node
+// was scraped, so its pods and containers which are not reported anymore
+// disappeared.
+const nodeSample = "K8sNodeSample"
+
+// entity is the snapshot of an entity.
+// Its fields must be public to make them visible for the JSON Marshaller.
+type entity struct {
+	Kind       string
+	Name       string
+	Type       string
+	Attributes map[string]string
+}
+
+// snapshot are the tracked entities of a run, by entity type and name.
+type snapshot map[string]entity
+
+// Tracker reports the transitions of the pods, containers and nodes between
+// runs as events, like a container restarting or a pod being deleted. A
+// snapshot of the tracked entities and their key attributes is kept in a
+// storage.Storage, so nothing is reported in the first run.
+//
+// Every node tracks the pods and containers scheduled on it, so each
+// transition is reported once.
+type Tracker struct {
+	storage   storage.Storage
+	nodeName  string
+	k8sClient client.Kubernetes
+	logger    *logrus.Logger
+}
+
+// NewTracker returns a Tracker of the pods and containers of the given node
+// keeping its snapshot in the given storage. If k8sClient is not nil, which
+// should only be the case in a single node of the cluster, the nodes listed
+// in the API server and the pods not scheduled yet are tracked too.
+func NewTracker(storage storage.Storage, nodeName string, k8sClient client.Kubernetes, logger *logrus.Logger) *Tracker {
+	return &Tracker{
+		storage:   storage,
+		nodeName:  nodeName,
+		k8sClient: k8sClient,
+		logger:    logger,
+	}
+}
+
+// Populate diffs the entities populated in the integration by the scrape
+// jobs with the ones of the previous run, and adds the events of their
+// transitions to them. The entities of a kind are only diffed when their
+// source was scraped, so a failing source is not reported as its entities
+// disappearing.
+func (t *Tracker) Populate(integration *sdk.IntegrationProtocol2, clusterName string) data.PopulateResult {
+	current, reported, scraped := t.snapshot(integration)
+
+	var errs []error
+	if t.k8sClient != nil {
+		nodes, err := t.k8sClient.ListNodes()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error listing the nodes: %s", err))
+		} else {
+			for _, node := range nodes.Items {
+				e := nodeEntity(clusterName, node)
+				current[key(e)] = e
+			}
+			scraped[nodeKind] = true
+		}
+	}
+
+	previous := make(snapshot)
+	_, err := t.storage.Read(storageKey, &previous)
+	first := err != nil
+	if first {
+		t.logger.Debugf("No previous lifecycle snapshot, transitions will be reported from the next run: %s", err)
+	}
+
+	// The entities of the kinds which were not scraped are kept for the next run.
+	for k, e := range previous {
+		if _, ok := current[k]; !ok && !scraped[e.Kind] {
+			current[k] = e
+		}
+	}
+
+	if err := t.storage.Write(storageKey, current); err != nil {
+		errs = append(errs, fmt.Errorf("error storing the lifecycle snapshot: %s", err))
+	}
+	if first {
+		return data.PopulateResult{Errors: errs, Populated: false}
+	}
+
+	populated := false
+	for _, k := range sortedKeys(previous, current) {
+		before, existed := previous[k]
+		after, exists := current[k]
+
+		var summary string
+		switch {
+		// The entities which are still reported but not tracked anymore, like a pod which got scheduled on
+		// another node, are tracked by another Tracker.
+		case existed && !exists && !reported[k]:
+			summary = fmt.Sprintf("%s: %s %s, %s", EntityDisappeared, before.Kind, before.Name, describe(before.Attributes))
+		case existed && exists:
+			summary = transition(before, after)
+		}
+		if summary == "" {
+			continue
+		}
+
+		e := after
+		if !exists {
+			e = before
+		}
+		entityData, err := integration.Entity(e.Name, e.Type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error creating the entity %s: %s", k, err))
+			continue
+		}
+		if err := entityData.AddEvent(sdk.Event{Summary: summary, Category: Category}); err != nil {
+			errs = append(errs, fmt.Errorf("error adding the event of %s: %s", k, err))
+			continue
+		}
+		populated = true
+	}
+
+	return data.PopulateResult{Errors: errs, Populated: populated}
+}
+
+// snapshot returns the tracked entities populated in the integration, the
+// keys of all the populated entities, and the kinds of entities whose source
+// was scraped.
+func (t *Tracker) snapshot(integration *sdk.IntegrationProtocol2) (snapshot, map[string]bool, map[string]bool) {
+	current := make(snapshot)
+	reported := make(map[string]bool)
+	scraped := make(map[string]bool)
+	for _, e := range integration.Data {
+		for _, ms := range e.Metrics {
+			eventType, _ := ms["event_type"].(string)
+			if eventType == nodeSample && ms["nodeName"] == t.nodeName {
+				scraped[podKind] = true
+				scraped[containerKind] = true
+			}
+
+			k := key(entity{Name: e.Entity.Name, Type: e.Entity.Type})
+			reported[k] = true
+
+			tracked, ok := trackedSamples[eventType]
+			if !ok || !t.owns(ms) {
+				continue
+			}
+
+			s, ok := current[k]
+			if !ok {
+				s = entity{Kind: tracked.kind, Name: e.Entity.Name, Type: e.Entity.Type, Attributes: make(map[string]string)}
+				current[k] = s
+			}
+			// The pods are reported by KSM and the kubelet, the attributes missing in one are taken from the other.
+			for _, attribute := range tracked.attributes {
+				if v, ok := ms[attribute]; ok && s.Attributes[attribute] == "" {
+					s.Attributes[attribute] = fmt.Sprint(v)
+				}
+			}
+		}
+	}
+	return current, reported, scraped
+}
+
+// owns returns whether the entity of a metric set is tracked by this Tracker,
+// which are the ones scheduled on its node, and the ones not scheduled yet if
+// it tracks the whole cluster.
+func (t *Tracker) owns(ms metric.MetricSet) bool {
+	nodeName, _ := ms["nodeName"].(string)
+	if nodeName == "" {
+		return t.k8sClient != nil
+	}
+	return nodeName == t.nodeName
+}
+
+func nodeEntity(clusterName string, node v1.Node) entity {
+	ready := "Unknown"
+	for _, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			ready = string(c.Status)
+		}
+	}
+	return entity{
+		Kind: nodeKind,
+		Name: node.Name,
+		Type: fmt.Sprintf("k8s:%s:node", clusterName),
+		Attributes: map[string]string{
+			"ready":         ready,
+			"unschedulable": fmt.Sprint(node.Spec.Unschedulable),
+		},
+	}
+}
+
+// transition returns the summary of the event of the changes of the
+// attributes of an entity, or an empty one if they didn't change.
+func transition(before, after entity) string {
+	changes := changed(before.Attributes, after.Attributes)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var name string
+	switch before.Kind {
+	case podKind:
+		name = PodStatusChanged
+	case containerKind:
+		name = ContainerStatusChanged
+		if restarted(before.Attributes["restartCount"], after.Attributes["restartCount"]) {
+			name = ContainerRestarted
+		}
+	case nodeKind:
+		name = NodeStatusChanged
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s: %s %s, %s", name, after.Kind, after.Name, strings.Join(changes, ", "))
+}
+
+// changed returns the changes of the attributes, e.g. "status Running ->
+// Waiting", in the order of their names.
+func changed(before, after map[string]string) []string {
+	var changes []string
+	for _, name := range sortedNames(before, after) {
+		if before[name] != after[name] {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", name, orNone(before[name]), orNone(after[name])))
+		}
+	}
+	return changes
+}
+
+func restarted(before, after string) bool {
+	var b, a float64
+	if _, err := fmt.Sscan(before, &b); err != nil {
+		return false
+	}
+	if _, err := fmt.Sscan(after, &a); err != nil {
+		return false
+	}
+	return a > b
+}
+
+// describe returns the last values of the attributes of an entity, e.g.
+// "status Running".
+func describe(attributes map[string]string) string {
+	var values []string
+	for _, name := range sortedNames(attributes, nil) {
+		values = append(values, fmt.Sprintf("%s %s", name, orNone(attributes[name])))
+	}
+	return strings.Join(values, ", ")
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+func key(e entity) string {
+	return e.Type + "/" + e.Name
+}
+
+func sortedKeys(snapshots ...snapshot) []string {
+	set := make(map[string]bool)
+	for _, s := range snapshots {
+		for k := range s {
+			set[k] = true
+		}
+	}
+	return sorted(set)
+}
+
+func sortedNames(attributes ...map[string]string) []string {
+	set := make(map[string]bool)
+	for _, a := range attributes {
+		for name := range a {
+			set[name] = true
+		}
+	}
+	return sorted(set)
+}
+
+func sorted(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
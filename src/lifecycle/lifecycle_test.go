@@ -0,0 +1,172 @@
+package lifecycle
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/newrelic/nri-kubernetes/src/client"
+	"github.com/newrelic/nri-kubernetes/src/storage"
+)
+
+type sample struct {
+	entityName, entityType, eventType string
+	metrics                           map[string]interface{}
+}
+
+func newIntegration(t *testing.T, samples ...sample) *sdk.IntegrationProtocol2 {
+	i, err := sdk.NewIntegrationProtocol2("test", "test", new(struct{}))
+	require.NoError(t, err)
+	for _, s := range samples {
+		e, err := i.Entity(s.entityName, s.entityType)
+		require.NoError(t, err)
+		ms := e.NewMetricSet(s.eventType)
+		for name, value := range s.metrics {
+			sourceType := metric.ATTRIBUTE
+			if _, ok := value.(string); !ok {
+				sourceType = metric.GAUGE
+			}
+			require.NoError(t, ms.SetMetric(name, value, sourceType))
+		}
+	}
+	return i
+}
+
+func node(name string) sample {
+	return sample{name, "k8s:c:node", "K8sNodeSample", map[string]interface{}{"nodeName": name}}
+}
+
+func pod(name, nodeName, status string) sample {
+	return sample{name, "k8s:c:default:pod", "K8sPodSample", map[string]interface{}{"nodeName": nodeName, "status": status}}
+}
+
+func container(name, status, reason string, restartCount int) sample {
+	return sample{name, "k8s:c:default:nginx-a:container", "K8sContainerSample", map[string]interface{}{
+		"nodeName":     "worker-1",
+		"status":       status,
+		"reason":       reason,
+		"restartCount": restartCount,
+	}}
+}
+
+func summaries(t *testing.T, i *sdk.IntegrationProtocol2) map[string][]string {
+	s := map[string][]string{}
+	for _, e := range i.Data {
+		for _, ev := range e.Events {
+			assert.Equal(t, Category, ev.Category)
+			key := e.Entity.Type + "/" + e.Entity.Name
+			s[key] = append(s[key], ev.Summary)
+		}
+	}
+	return s
+}
+
+func newStorage(t *testing.T) storage.Storage {
+	tmpDir, err := ioutil.TempDir("", "test_lifecycle")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+	return storage.NewJSONDiskStorage(tmpDir)
+}
+
+func TestTracker_Populate(t *testing.T) {
+	tracker := NewTracker(newStorage(t), "worker-1", nil, logrus.New())
+
+	// Nothing is reported in the first run.
+	i := newIntegration(t,
+		node("worker-1"),
+		pod("nginx-a", "worker-1", "Running"),
+		pod("nginx-b", "worker-1", "Running"),
+		pod("redis-0", "worker-2", "Running"),
+		container("nginx", "Running", "", 3),
+		container("sidecar", "Running", "", 0),
+	)
+	result := tracker.Populate(i, "c")
+	assert.Empty(t, result.Errors)
+	assert.False(t, result.Populated)
+	assert.Empty(t, summaries(t, i))
+
+	i = newIntegration(t,
+		node("worker-1"),
+		pod("nginx-a", "worker-1", "Failed"),
+		container("nginx", "Waiting", "CrashLoopBackOff", 4),
+		container("sidecar", "Waiting", "ContainerCreating", 0),
+	)
+	result = tracker.Populate(i, "c")
+	assert.Empty(t, result.Errors)
+	assert.True(t, result.Populated)
+	// The pods of other nodes, like redis-0, are tracked by their own integration.
+	assert.Equal(t, map[string][]string{
+		"k8s:c:default:pod/nginx-a": {"PodStatusChanged: pod nginx-a, status Running -> Failed"},
+		"k8s:c:default:pod/nginx-b": {"EntityDisappeared: pod nginx-b, status Running"},
+		"k8s:c:default:nginx-a:container/nginx": {
+			"ContainerRestarted: container nginx, reason <none> -> CrashLoopBackOff, restartCount 3 -> 4, status Running -> Waiting",
+		},
+		"k8s:c:default:nginx-a:container/sidecar": {
+			"ContainerStatusChanged: container sidecar, reason <none> -> ContainerCreating, status Running -> Waiting",
+		},
+	}, summaries(t, i))
+
+	// The kubelet was not scraped, so its pods are not considered disappeared.
+	i = newIntegration(t)
+	result = tracker.Populate(i, "c")
+	assert.Empty(t, result.Errors)
+	assert.Empty(t, summaries(t, i))
+
+	i = newIntegration(t, node("worker-1"), pod("nginx-a", "worker-1", "Failed"))
+	tracker.Populate(i, "c")
+	assert.Equal(t, map[string][]string{
+		"k8s:c:default:nginx-a:container/nginx":   {"EntityDisappeared: container nginx, reason CrashLoopBackOff, restartCount 4, status Waiting"},
+		"k8s:c:default:nginx-a:container/sidecar": {"EntityDisappeared: container sidecar, reason ContainerCreating, restartCount 0, status Waiting"},
+	}, summaries(t, i))
+}
+
+func readyNode(name string, ready v1.ConditionStatus) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}}},
+	}
+}
+
+func TestTracker_PopulateNodes(t *testing.T) {
+	store := newStorage(t)
+
+	first := new(client.MockedKubernetes)
+	first.On("ListNodes").Return(&v1.NodeList{Items: []v1.Node{
+		readyNode("worker-1", v1.ConditionTrue),
+		readyNode("worker-2", v1.ConditionTrue),
+	}}, nil)
+	i := newIntegration(t, node("worker-1"), pod("pending", "", "Pending"), pod("scheduled", "", "Pending"))
+	result := NewTracker(store, "worker-1", first, logrus.New()).Populate(i, "c")
+	assert.Empty(t, result.Errors)
+
+	second := new(client.MockedKubernetes)
+	second.On("ListNodes").Return(&v1.NodeList{Items: []v1.Node{
+		readyNode("worker-1", v1.ConditionFalse),
+	}}, nil)
+	i = newIntegration(t, node("worker-1"), pod("pending", "", "Running"), pod("scheduled", "worker-2", "Running"))
+	result = NewTracker(store, "worker-1", second, logrus.New()).Populate(i, "c")
+	assert.Empty(t, result.Errors)
+	// The pod scheduled on worker-2 is tracked by its integration from now on, so it didn't disappear.
+	assert.Equal(t, map[string][]string{
+		"k8s:c:node/worker-1":       {"NodeStatusChanged: node worker-1, ready True -> False"},
+		"k8s:c:node/worker-2":       {"EntityDisappeared: node worker-2, ready True, unschedulable false"},
+		"k8s:c:default:pod/pending": {"PodStatusChanged: pod pending, status Pending -> Running"},
+	}, summaries(t, i))
+
+	// The nodes are kept when they can't be listed.
+	failing := new(client.MockedKubernetes)
+	failing.On("ListNodes").Return(&v1.NodeList{}, errors.New("forbidden"))
+	i = newIntegration(t, node("worker-1"))
+	result = NewTracker(store, "worker-1", failing, logrus.New()).Populate(i, "c")
+	assert.Len(t, result.Errors, 1)
+	assert.NotContains(t, summaries(t, i), "k8s:c:node/worker-1")
+}
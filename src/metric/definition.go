@@ -6,10 +6,12 @@ import (
 	"time"
 
 	sdkMetric "github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/nri-kubernetes/src/capacity"
 	"github.com/newrelic/nri-kubernetes/src/definition"
 	ksmMetric "github.com/newrelic/nri-kubernetes/src/ksm/metric"
 	kubeletMetric "github.com/newrelic/nri-kubernetes/src/kubelet/metric"
 	"github.com/newrelic/nri-kubernetes/src/prometheus"
+	"github.com/newrelic/nri-kubernetes/src/rollup"
 )
 
 // APIServerSpecs are the metric specifications we want to collect
@@ -18,6 +20,7 @@ var APIServerSpecs = definition.SpecGroups{
 	"api-server": {
 		IDGenerator:   prometheus.FromRawEntityIDGenerator,
 		TypeGenerator: prometheus.ControlPlaneComponentTypeGenerator,
+		StartTime:     sumPrometheusValues("process_start_time_seconds"),
 		Specs: []definition.Spec{
 			{
 				Name: "apiserverRequestsDelta",
@@ -60,6 +63,68 @@ var APIServerSpecs = definition.SpecGroups{
 				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_object_counts", "etcdObjectCounts"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				// Only the verb and resource are kept since every label combination
+				// results in one attribute per quantile, plus the count and sum.
+				Name: "apiserverRequestDurationSeconds",
+				ValueFunc: prometheus.FromHistogram(
+					"apiserver_request_duration_seconds",
+					"apiserverRequestDurationSeconds",
+					prometheus.IncludeOnlyLabelsFilter("verb", "resource"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name: "apiserverCurrentInflightRequests",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"apiserver_current_inflight_requests",
+					"apiserverCurrentInflightRequests",
+					prometheus.IncludeOnlyLabelsFilter("request_kind"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name: "apiserverRegisteredWatchers",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"apiserver_registered_watchers",
+					"apiserverRegisteredWatchers",
+					prometheus.IncludeOnlyLabelsFilter(),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name: "apiserverAdmissionWebhookAdmissionDurationSeconds",
+				ValueFunc: prometheus.FromHistogram(
+					"apiserver_admission_webhook_admission_duration_seconds",
+					"apiserverAdmissionWebhookAdmissionDurationSeconds",
+					prometheus.IncludeOnlyLabelsFilter("name", "type"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name: "apiserverDroppedRequestsDelta",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"apiserver_dropped_requests_total",
+					"apiserverDroppedRequestsDelta",
+					prometheus.IncludeOnlyLabelsFilter("request_kind"),
+				),
+				Type:     sdkMetric.DELTA,
+				Optional: true,
+			},
+			{
+				Name: "apiserverDroppedRequestsRate",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"apiserver_dropped_requests_total",
+					"apiserverDroppedRequestsRate",
+					prometheus.IncludeOnlyLabelsFilter("request_kind"),
+				),
+				Type:     sdkMetric.RATE,
+				Optional: true,
+			},
 			{
 				Name:      "processResidentMemoryBytes",
 				ValueFunc: prometheus.FromValueWithOverriddenName("process_resident_memory_bytes", "processResidentMemoryBytes"),
@@ -80,6 +145,24 @@ var APIServerSpecs = definition.SpecGroups{
 				ValueFunc: prometheus.FromValueWithOverriddenName("go_goroutines", "goGoroutines"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				Name:      "certExpiresInSeconds",
+				ValueFunc: definition.FromRaw("certExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "certIssuer",
+				ValueFunc: definition.FromRaw("certIssuer"),
+				Type:      sdkMetric.ATTRIBUTE,
+				Optional:  true,
+			},
+			{
+				Name:      "clientCertExpiresInSeconds",
+				ValueFunc: definition.FromRaw("clientCertExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
 		},
 	},
 }
@@ -96,12 +179,30 @@ var APIServerQueries = []prometheus.Query{
 	{
 		MetricName: "etcd_object_counts",
 	},
+	{
+		MetricName: "apiserver_request_duration_seconds",
+	},
+	{
+		MetricName: "apiserver_current_inflight_requests",
+	},
+	{
+		MetricName: "apiserver_registered_watchers",
+	},
+	{
+		MetricName: "apiserver_admission_webhook_admission_duration_seconds",
+	},
+	{
+		MetricName: "apiserver_dropped_requests_total",
+	},
 	{
 		MetricName: "process_resident_memory_bytes",
 	},
 	{
 		MetricName: "process_cpu_seconds_total",
 	},
+	{
+		MetricName: "process_start_time_seconds",
+	},
 	{
 		MetricName: "go_threads",
 	},
@@ -116,25 +217,8 @@ var ControllerManagerSpecs = definition.SpecGroups{
 	"controller-manager": {
 		IDGenerator:   prometheus.FromRawEntityIDGenerator,
 		TypeGenerator: prometheus.ControlPlaneComponentTypeGenerator,
+		StartTime:     sumPrometheusValues("process_start_time_seconds"),
 		Specs: []definition.Spec{
-			{
-				Name:      "workqueueAddsDelta",
-				ValueFunc: prometheus.FromValueWithOverriddenName("workqueue_adds_total", "workqueueAddsDelta"),
-				Type:      sdkMetric.DELTA,
-				Optional:  true,
-			},
-			{
-				Name:      "workqueueDepth",
-				ValueFunc: prometheus.FromValueWithOverriddenName("workqueue_depth", "workqueueDepth"),
-				Type:      sdkMetric.GAUGE,
-				Optional:  true,
-			},
-			{
-				Name:      "workqueueRetriesDelta",
-				ValueFunc: prometheus.FromValueWithOverriddenName("workqueue_retries_total", "workqueueRetriesDelta"),
-				Type:      sdkMetric.DELTA,
-				Optional:  true,
-			},
 			{
 				Name: "leaderElectionMasterStatus",
 				ValueFunc: prometheus.FromValueWithOverriddenName(
@@ -164,8 +248,93 @@ var ControllerManagerSpecs = definition.SpecGroups{
 				ValueFunc: prometheus.FromValueWithOverriddenName("go_goroutines", "goGoroutines"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				Name:      "certExpiresInSeconds",
+				ValueFunc: definition.FromRaw("certExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "certIssuer",
+				ValueFunc: definition.FromRaw("certIssuer"),
+				Type:      sdkMetric.ATTRIBUTE,
+				Optional:  true,
+			},
+			{
+				Name:      "clientCertExpiresInSeconds",
+				ValueFunc: definition.FromRaw("clientCertExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
 		},
 	},
+	// One metric set per controller, attached to the controller manager
+	// entity. The counters are kept per controller.
+	"controller-manager-workqueue": {
+		IDGenerator:   prometheus.FromParentEntityIDGenerator,
+		TypeGenerator: prometheus.ControlPlaneComponentTypeGeneratorFor("controller-manager"),
+		CounterKey:    definition.FromRaw("name"),
+		Specs: []definition.Spec{
+			{Name: "controllerName", ValueFunc: definition.FromRaw("name"), Type: sdkMetric.ATTRIBUTE},
+			{
+				Name:      "workqueueAddsPerSecond",
+				ValueFunc: prometheus.FromValueWithOverriddenName("workqueue_adds_total", "workqueueAddsPerSecond", prometheus.IncludeOnlyLabelsFilter()),
+				Type:      sdkMetric.RATE,
+				Optional:  true,
+			},
+			{
+				Name:      "workqueueRetriesPerSecond",
+				ValueFunc: prometheus.FromValueWithOverriddenName("workqueue_retries_total", "workqueueRetriesPerSecond", prometheus.IncludeOnlyLabelsFilter()),
+				Type:      sdkMetric.RATE,
+				Optional:  true,
+			},
+			{
+				Name:      "workqueueDepth",
+				ValueFunc: prometheus.FromValueWithOverriddenName("workqueue_depth", "workqueueDepth", prometheus.IncludeOnlyLabelsFilter()),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "workqueueQueueDurationSeconds",
+				ValueFunc: prometheus.FromHistogram("workqueue_queue_duration_seconds", "workqueueQueueDurationSeconds", prometheus.IncludeOnlyLabelsFilter()),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "workqueueWorkDurationSeconds",
+				ValueFunc: prometheus.FromHistogram("workqueue_work_duration_seconds", "workqueueWorkDurationSeconds", prometheus.IncludeOnlyLabelsFilter()),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name: "workqueueUnfinishedWorkSeconds",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"workqueue_unfinished_work_seconds",
+					"workqueueUnfinishedWorkSeconds",
+					prometheus.IncludeOnlyLabelsFilter(),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name: "workqueueLongestRunningProcessorSeconds",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"workqueue_longest_running_processor_seconds",
+					"workqueueLongestRunningProcessorSeconds",
+					prometheus.IncludeOnlyLabelsFilter(),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+		},
+	},
+}
+
+// ControllerManagerGroupsByLabel are the ControllerManagerSpecs groups whose
+// metrics are split by the value of a label, so one metric set is reported
+// per value.
+var ControllerManagerGroupsByLabel = map[string]string{
+	"controller-manager-workqueue": "name",
 }
 
 // ControllerManagerQueries are the queries we will do to the control plane
@@ -180,6 +349,18 @@ var ControllerManagerQueries = []prometheus.Query{
 	{
 		MetricName: "workqueue_retries_total",
 	},
+	{
+		MetricName: "workqueue_queue_duration_seconds",
+	},
+	{
+		MetricName: "workqueue_work_duration_seconds",
+	},
+	{
+		MetricName: "workqueue_unfinished_work_seconds",
+	},
+	{
+		MetricName: "workqueue_longest_running_processor_seconds",
+	},
 	{
 		MetricName: "leader_election_master_status",
 	},
@@ -189,6 +370,9 @@ var ControllerManagerQueries = []prometheus.Query{
 	{
 		MetricName: "process_cpu_seconds_total",
 	},
+	{
+		MetricName: "process_start_time_seconds",
+	},
 	{
 		MetricName: "go_threads",
 	},
@@ -203,6 +387,7 @@ var SchedulerSpecs = definition.SpecGroups{
 	"scheduler": {
 		IDGenerator:   prometheus.FromRawEntityIDGenerator,
 		TypeGenerator: prometheus.ControlPlaneComponentTypeGenerator,
+		StartTime:     sumPrometheusValues("process_start_time_seconds"),
 		Specs: []definition.Spec{
 			{
 				Name: "leaderElectionMasterStatus",
@@ -239,6 +424,63 @@ var SchedulerSpecs = definition.SpecGroups{
 				Type:      sdkMetric.GAUGE,
 				Optional:  true,
 			},
+			{
+				// Histogram replacing the scheduler_scheduling_duration_seconds
+				// summary, which is deprecated in newer Kubernetes versions. The
+				// result is kept to tell scheduled pods from unschedulable ones.
+				Name: "schedulerSchedulingAttemptDurationSeconds",
+				ValueFunc: prometheus.FromHistogram(
+					"scheduler_scheduling_attempt_duration_seconds",
+					"schedulerSchedulingAttemptDurationSeconds",
+					prometheus.IncludeOnlyLabelsFilter("result"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				// Latency of the scheduling algorithm alone, which is only a part
+				// of each scheduling attempt.
+				Name:      "schedulerSchedulingAlgorithmDurationSeconds",
+				ValueFunc: prometheus.FromHistogram("scheduler_scheduling_algorithm_duration_seconds", "schedulerSchedulingAlgorithmDurationSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name: "schedulerE2eSchedulingDurationSeconds",
+				ValueFunc: prometheus.FromHistogram(
+					"scheduler_e2e_scheduling_duration_seconds",
+					"schedulerE2eSchedulingDurationSeconds",
+					prometheus.IncludeOnlyLabelsFilter("result"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name: "schedulerFrameworkExtensionPointDurationSeconds",
+				ValueFunc: prometheus.FromHistogram(
+					"scheduler_framework_extension_point_duration_seconds",
+					"schedulerFrameworkExtensionPointDurationSeconds",
+					prometheus.IncludeOnlyLabelsFilter("extension_point"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name:      "schedulerPodSchedulingAttempts",
+				ValueFunc: prometheus.FromHistogram("scheduler_pod_scheduling_attempts", "schedulerPodSchedulingAttempts"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name: "schedulerPendingPods",
+				ValueFunc: prometheus.FromValueWithOverriddenName(
+					"scheduler_pending_pods",
+					"schedulerPendingPods",
+					prometheus.IncludeOnlyLabelsFilter("queue"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
 			{
 				Name:      "schedulerPreemptionAttemptsDelta",
 				ValueFunc: prometheus.FromValueWithOverriddenName("scheduler_total_preemption_attempts", "schedulerPreemptionAttemptsDelta"),
@@ -269,6 +511,24 @@ var SchedulerSpecs = definition.SpecGroups{
 				ValueFunc: prometheus.FromValueWithOverriddenName("go_goroutines", "goGoroutines"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				Name:      "certExpiresInSeconds",
+				ValueFunc: definition.FromRaw("certExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "certIssuer",
+				ValueFunc: definition.FromRaw("certIssuer"),
+				Type:      sdkMetric.ATTRIBUTE,
+				Optional:  true,
+			},
+			{
+				Name:      "clientCertExpiresInSeconds",
+				ValueFunc: definition.FromRaw("clientCertExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
 		},
 	},
 }
@@ -288,6 +548,24 @@ var SchedulerQueries = []prometheus.Query{
 	{
 		MetricName: "scheduler_scheduling_duration_seconds",
 	},
+	{
+		MetricName: "scheduler_scheduling_attempt_duration_seconds",
+	},
+	{
+		MetricName: "scheduler_scheduling_algorithm_duration_seconds",
+	},
+	{
+		MetricName: "scheduler_e2e_scheduling_duration_seconds",
+	},
+	{
+		MetricName: "scheduler_framework_extension_point_duration_seconds",
+	},
+	{
+		MetricName: "scheduler_pod_scheduling_attempts",
+	},
+	{
+		MetricName: "scheduler_pending_pods",
+	},
 	{
 		MetricName: "scheduler_total_preemption_attempts",
 	},
@@ -300,6 +578,9 @@ var SchedulerQueries = []prometheus.Query{
 	{
 		MetricName: "process_cpu_seconds_total",
 	},
+	{
+		MetricName: "process_start_time_seconds",
+	},
 	{
 		MetricName: "go_threads",
 	},
@@ -314,6 +595,7 @@ var EtcdSpecs = definition.SpecGroups{
 	"etcd": {
 		IDGenerator:   prometheus.FromRawEntityIDGenerator,
 		TypeGenerator: prometheus.ControlPlaneComponentTypeGenerator,
+		StartTime:     sumPrometheusValues("process_start_time_seconds"),
 		Specs: []definition.Spec{
 			{
 				Name:      "etcdServerHasLeader",
@@ -330,6 +612,53 @@ var EtcdSpecs = definition.SpecGroups{
 				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_mvcc_db_total_size_in_bytes", "etcdMvccDbTotalSizeInBytes"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				Name:      "etcdMvccDbTotalSizeInUseInBytes",
+				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_mvcc_db_total_size_in_use_in_bytes", "etcdMvccDbTotalSizeInUseInBytes"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "etcdServerQuotaBackendBytes",
+				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_server_quota_backend_bytes", "etcdServerQuotaBackendBytes"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "etcdDiskWalFsyncDurationSeconds",
+				ValueFunc: prometheus.FromHistogram("etcd_disk_wal_fsync_duration_seconds", "etcdDiskWalFsyncDurationSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "etcdDiskBackendCommitDurationSeconds",
+				ValueFunc: prometheus.FromHistogram("etcd_disk_backend_commit_duration_seconds", "etcdDiskBackendCommitDurationSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				// One time-series per peer, labelled with the peer ID.
+				Name: "etcdNetworkPeerRoundTripTimeSeconds",
+				ValueFunc: prometheus.FromHistogram(
+					"etcd_network_peer_round_trip_time_seconds",
+					"etcdNetworkPeerRoundTripTimeSeconds",
+					prometheus.IncludeOnlyLabelsFilter("To"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				Name:      "etcdServerSlowApplyDelta",
+				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_server_slow_apply_total", "etcdServerSlowApplyDelta"),
+				Type:      sdkMetric.DELTA,
+				Optional:  true,
+			},
+			{
+				Name:      "etcdServerHeartbeatSendFailuresDelta",
+				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_server_heartbeat_send_failures_total", "etcdServerHeartbeatSendFailuresDelta"),
+				Type:      sdkMetric.DELTA,
+				Optional:  true,
+			},
 			{
 				Name:      "etcdServerProposalsCommittedRate",
 				ValueFunc: prometheus.FromValueWithOverriddenName("etcd_server_proposals_committed_total", "etcdServerProposalsCommittedRate"),
@@ -405,12 +734,58 @@ var EtcdSpecs = definition.SpecGroups{
 				ValueFunc: prometheus.FromValueWithOverriddenName("go_goroutines", "goGoroutines"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				Name:      "certExpiresInSeconds",
+				ValueFunc: definition.FromRaw("certExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
+			{
+				Name:      "certIssuer",
+				ValueFunc: definition.FromRaw("certIssuer"),
+				Type:      sdkMetric.ATTRIBUTE,
+				Optional:  true,
+			},
+			{
+				Name:      "clientCertExpiresInSeconds",
+				ValueFunc: definition.FromRaw("clientCertExpiresInSeconds"),
+				Type:      sdkMetric.GAUGE,
+				Optional:  true,
+			},
 			// computed
 			{
 				Name:      "processFdsUtilization",
 				ValueFunc: toUtilization("processOpenFds", "processMaxFds"),
 				Type:      sdkMetric.GAUGE,
 			},
+			{
+				// Share of the database file which is not in use, and can be
+				// reclaimed by defragmenting it.
+				Name: "etcdMvccDbFragmentationRatio",
+				ValueFunc: Divide(
+					Subtract(
+						sumPrometheusValues("etcd_mvcc_db_total_size_in_bytes"),
+						sumPrometheusValues("etcd_mvcc_db_total_size_in_use_in_bytes"),
+					),
+					sumPrometheusValues("etcd_mvcc_db_total_size_in_bytes"),
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
+			{
+				// etcd raises a NOSPACE alarm and stops accepting writes when
+				// the database size reaches the backend quota.
+				Name: "etcdServerQuotaBackendUtilization",
+				ValueFunc: definition.Transform(
+					Divide(
+						sumPrometheusValues("etcd_mvcc_db_total_size_in_bytes"),
+						sumPrometheusValues("etcd_server_quota_backend_bytes"),
+					),
+					toPercentage,
+				),
+				Type:     sdkMetric.GAUGE,
+				Optional: true,
+			},
 		},
 	},
 }
@@ -427,6 +802,27 @@ var EtcdQueries = []prometheus.Query{
 	{
 		MetricName: "etcd_mvcc_db_total_size_in_bytes",
 	},
+	{
+		MetricName: "etcd_mvcc_db_total_size_in_use_in_bytes",
+	},
+	{
+		MetricName: "etcd_server_quota_backend_bytes",
+	},
+	{
+		MetricName: "etcd_disk_wal_fsync_duration_seconds",
+	},
+	{
+		MetricName: "etcd_disk_backend_commit_duration_seconds",
+	},
+	{
+		MetricName: "etcd_network_peer_round_trip_time_seconds",
+	},
+	{
+		MetricName: "etcd_server_slow_apply_total",
+	},
+	{
+		MetricName: "etcd_server_heartbeat_send_failures_total",
+	},
 	{
 		MetricName: "etcd_server_proposals_committed_total",
 	},
@@ -457,6 +853,9 @@ var EtcdQueries = []prometheus.Query{
 	{
 		MetricName: "process_cpu_seconds_total",
 	},
+	{
+		MetricName: "process_start_time_seconds",
+	},
 	{
 		MetricName: "go_threads",
 	},
@@ -544,6 +943,7 @@ var KSMSpecs = definition.SpecGroups{
 			{Name: "namespaceName", ValueFunc: prometheus.FromLabelValue("kube_namespace_created", "namespace"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "status", ValueFunc: prometheus.FromLabelValue("kube_namespace_status_phase", "phase"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "label.*", ValueFunc: prometheus.InheritAllLabelsFrom("namespace", "kube_namespace_labels"), Type: sdkMetric.ATTRIBUTE},
+			{Name: "annotation.*", ValueFunc: prometheus.InheritAllAnnotationsFrom("namespace", "kube_namespace_annotations"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 		},
 	},
 	"deployment": {
@@ -563,6 +963,7 @@ var KSMSpecs = definition.SpecGroups{
 			// Important: The order of these lines is important: we could have the same label in different entities, and we would like to keep the value closer to deployment
 			{Name: "label.*", ValueFunc: prometheus.InheritAllLabelsFrom("namespace", "kube_namespace_labels"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "label.*", ValueFunc: prometheus.InheritAllLabelsFrom("deployment", "kube_deployment_labels"), Type: sdkMetric.ATTRIBUTE},
+			{Name: "annotation.*", ValueFunc: prometheus.InheritAllAnnotationsFrom("deployment", "kube_deployment_annotations"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 			//computed
 			{Name: "podsMissing", ValueFunc: Subtract(
 				definition.Transform(prometheus.FromValue("kube_deployment_spec_replicas"), fromPrometheusNumeric),
@@ -682,7 +1083,16 @@ var KSMSpecs = definition.SpecGroups{
 			{Name: "status", ValueFunc: prometheus.FromLabelValue("kube_pod_status_phase", "phase"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "isScheduled", ValueFunc: definition.Transform(prometheus.FromLabelValue("kube_pod_status_scheduled", "condition"), toNumericBoolean), Type: sdkMetric.GAUGE},
 			{Name: "deploymentName", ValueFunc: ksmMetric.GetDeploymentNameForPod(), Type: sdkMetric.ATTRIBUTE},
+			{Name: "workloadKind", ValueFunc: definition.FromRaw("workloadKind"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "workloadName", ValueFunc: definition.FromRaw("workloadName"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			// Set by the KSM grouper from the PodScheduled condition of the pending pods.
+			{Name: "pendingReason", ValueFunc: definition.FromRaw("pendingReason"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "pendingMessage", ValueFunc: definition.FromRaw("pendingMessage"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "availableNodes", ValueFunc: definition.FromRaw("availableNodes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "totalNodes", ValueFunc: definition.FromRaw("totalNodes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "unschedulableNodes.*", ValueFunc: definition.FromRaw("unschedulableNodes"), Type: sdkMetric.GAUGE, Optional: true},
 			{Name: "label.*", ValueFunc: prometheus.InheritAllLabelsFrom("pod", "kube_pod_labels"), Type: sdkMetric.ATTRIBUTE},
+			{Name: "annotation.*", ValueFunc: prometheus.InheritAllAnnotationsFrom("pod", "kube_pod_annotations"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 		},
 	},
 	"hpa": {
@@ -741,9 +1151,16 @@ var KSMQueries = []prometheus.Query{
 	{MetricName: "kube_replicaset_status_fully_labeled_replicas"},
 	{MetricName: "kube_replicaset_status_observed_generation"},
 	{MetricName: "kube_replicaset_created"},
+	{MetricName: "kube_replicaset_owner", Labels: prometheus.QueryLabels{
+		Labels: prometheus.Labels{"owner_is_controller": "true"},
+	}},
 	{MetricName: "kube_namespace_labels", Value: prometheus.QueryValue{
 		Value: prometheus.GaugeValue(1),
 	}},
+	// The annotations are only exposed by KSM 2.0+, for the ones in --metric-annotations-allowlist.
+	{MetricName: "kube_namespace_annotations", Value: prometheus.QueryValue{
+		Value: prometheus.GaugeValue(1),
+	}},
 	{MetricName: "kube_namespace_created"},
 	{MetricName: "kube_namespace_status_phase", Value: prometheus.QueryValue{
 		Value: prometheus.GaugeValue(1),
@@ -751,6 +1168,9 @@ var KSMQueries = []prometheus.Query{
 	{MetricName: "kube_deployment_labels", Value: prometheus.QueryValue{
 		Value: prometheus.GaugeValue(1),
 	}},
+	{MetricName: "kube_deployment_annotations", Value: prometheus.QueryValue{
+		Value: prometheus.GaugeValue(1),
+	}},
 	{MetricName: "kube_deployment_created"},
 	{MetricName: "kube_deployment_spec_replicas"},
 	{MetricName: "kube_deployment_status_replicas"},
@@ -764,8 +1184,12 @@ var KSMQueries = []prometheus.Query{
 		Value: prometheus.GaugeValue(1),
 	}},
 	{MetricName: "kube_pod_info"},
+	{MetricName: "kube_pod_owner", Labels: prometheus.QueryLabels{
+		Labels: prometheus.Labels{"owner_is_controller": "true"},
+	}},
 	{MetricName: "kube_pod_created"},
 	{MetricName: "kube_pod_labels"},
+	{MetricName: "kube_pod_annotations"},
 	{MetricName: "kube_pod_status_scheduled", Value: prometheus.QueryValue{
 		Value: prometheus.GaugeValue(1),
 	}},
@@ -840,6 +1264,10 @@ var KubeletSpecs = definition.SpecGroups{
 			// /pods endpoint
 			{Name: "createdAt", ValueFunc: definition.Transform(definition.FromRaw("createdAt"), toTimestamp), Type: sdkMetric.GAUGE},
 			{Name: "startTime", ValueFunc: definition.Transform(definition.FromRaw("startTime"), toTimestamp), Type: sdkMetric.GAUGE},
+			{Name: "schedulingLatencySeconds", ValueFunc: definition.FromRaw("schedulingLatencySeconds"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "initializationLatencySeconds", ValueFunc: definition.FromRaw("initializationLatencySeconds"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "containersReadyLatencySeconds", ValueFunc: definition.FromRaw("containersReadyLatencySeconds"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "startupLatencySeconds", ValueFunc: definition.FromRaw("startupLatencySeconds"), Type: sdkMetric.GAUGE, Optional: true},
 			{Name: "createdKind", ValueFunc: definition.FromRaw("createdKind"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "createdBy", ValueFunc: definition.FromRaw("createdBy"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "nodeIP", ValueFunc: definition.FromRaw("nodeIP"), Type: sdkMetric.ATTRIBUTE},
@@ -851,26 +1279,36 @@ var KubeletSpecs = definition.SpecGroups{
 			{Name: "status", ValueFunc: definition.FromRaw("status"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "isScheduled", ValueFunc: definition.Transform(definition.FromRaw("isScheduled"), toNumericBoolean), Type: sdkMetric.GAUGE},
 			{Name: "deploymentName", ValueFunc: definition.FromRaw("deploymentName"), Type: sdkMetric.ATTRIBUTE},
+			{Name: "workloadKind", ValueFunc: definition.FromRaw("workloadKind"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "workloadName", ValueFunc: definition.FromRaw("workloadName"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 			{Name: "label.*", ValueFunc: definition.Transform(definition.FromRaw("labels"), kubeletMetric.OneMetricPerLabel), Type: sdkMetric.ATTRIBUTE},
+			{Name: "annotation.*", ValueFunc: definition.Transform(definition.FromRaw("annotations"), kubeletMetric.OneMetricPerAnnotation), Type: sdkMetric.ATTRIBUTE, Optional: true},
 			{Name: "reason", ValueFunc: definition.FromRaw("reason"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "message", ValueFunc: definition.FromRaw("message"), Type: sdkMetric.ATTRIBUTE},
+			// security posture
+			{Name: "hostNetwork", ValueFunc: definition.Transform(definition.FromRaw("hostNetwork"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "hostPID", ValueFunc: definition.Transform(definition.FromRaw("hostPID"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "hostIPC", ValueFunc: definition.Transform(definition.FromRaw("hostIPC"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "runAsNonRoot", ValueFunc: definition.Transform(definition.FromRaw("runAsNonRoot"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "serviceAccountName", ValueFunc: definition.FromRaw("serviceAccountName"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "hostPathVolumes", ValueFunc: definition.FromRaw("hostPathVolumes"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 		},
 	},
 	"container": {
 		IDGenerator:   kubeletMetric.FromRawGroupsEntityIDGenerator("containerName"),
 		TypeGenerator: kubeletMetric.FromRawGroupsEntityTypeGenerator,
 		Specs: []definition.Spec{
-			// /stats/summary endpoint
-			{Name: "memoryUsedBytes", ValueFunc: definition.FromRaw("usageBytes"), Type: sdkMetric.GAUGE},
-			{Name: "memoryWorkingSetBytes", ValueFunc: definition.FromRaw("workingSetBytes"), Type: sdkMetric.GAUGE},
-			{Name: "cpuUsedCores", ValueFunc: definition.Transform(definition.FromRaw("usageNanoCores"), fromNano), Type: sdkMetric.GAUGE},
-			{Name: "fsAvailableBytes", ValueFunc: definition.FromRaw("fsAvailableBytes"), Type: sdkMetric.GAUGE},
-			{Name: "fsCapacityBytes", ValueFunc: definition.FromRaw("fsCapacityBytes"), Type: sdkMetric.GAUGE},
-			{Name: "fsUsedBytes", ValueFunc: definition.FromRaw("fsUsedBytes"), Type: sdkMetric.GAUGE},
-			{Name: "fsUsedPercent", ValueFunc: toComplementPercentage("fsUsedBytes", "fsAvailableBytes"), Type: sdkMetric.GAUGE},
-			{Name: "fsInodesFree", ValueFunc: definition.FromRaw("fsInodesFree"), Type: sdkMetric.GAUGE},
-			{Name: "fsInodes", ValueFunc: definition.FromRaw("fsInodes"), Type: sdkMetric.GAUGE},
-			{Name: "fsInodesUsed", ValueFunc: definition.FromRaw("fsInodesUsed"), Type: sdkMetric.GAUGE},
+			// /stats/summary endpoint, which doesn't report the completed init containers
+			{Name: "memoryUsedBytes", ValueFunc: definition.FromRaw("usageBytes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "memoryWorkingSetBytes", ValueFunc: definition.FromRaw("workingSetBytes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "cpuUsedCores", ValueFunc: definition.Transform(definition.FromRaw("usageNanoCores"), fromNano), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsAvailableBytes", ValueFunc: definition.FromRaw("fsAvailableBytes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsCapacityBytes", ValueFunc: definition.FromRaw("fsCapacityBytes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsUsedBytes", ValueFunc: definition.FromRaw("fsUsedBytes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsUsedPercent", ValueFunc: toComplementPercentage("fsUsedBytes", "fsAvailableBytes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsInodesFree", ValueFunc: definition.FromRaw("fsInodesFree"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsInodes", ValueFunc: definition.FromRaw("fsInodes"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "fsInodesUsed", ValueFunc: definition.FromRaw("fsInodesUsed"), Type: sdkMetric.GAUGE, Optional: true},
 
 			// /metrics/cadvisor endpoint
 			{Name: "containerID", ValueFunc: definition.FromRaw("containerID"), Type: sdkMetric.ATTRIBUTE},
@@ -886,7 +1324,10 @@ var KubeletSpecs = definition.SpecGroups{
 			// /pods endpoint
 			{Name: "containerName", ValueFunc: definition.FromRaw("containerName"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "containerImage", ValueFunc: definition.FromRaw("containerImage"), Type: sdkMetric.ATTRIBUTE},
+			{Name: "containerType", ValueFunc: definition.FromRaw("containerType"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "deploymentName", ValueFunc: definition.FromRaw("deploymentName"), Type: sdkMetric.ATTRIBUTE},
+			{Name: "workloadKind", ValueFunc: definition.FromRaw("workloadKind"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "workloadName", ValueFunc: definition.FromRaw("workloadName"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 			{Name: "namespace", ValueFunc: definition.FromRaw("namespace"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "namespaceName", ValueFunc: definition.FromRaw("namespace"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "podName", ValueFunc: definition.FromRaw("podName"), Type: sdkMetric.ATTRIBUTE},
@@ -900,6 +1341,13 @@ var KubeletSpecs = definition.SpecGroups{
 			{Name: "status", ValueFunc: definition.FromRaw("status"), Type: sdkMetric.ATTRIBUTE},
 			{Name: "isReady", ValueFunc: definition.Transform(definition.FromRaw("isReady"), toNumericBoolean), Type: sdkMetric.GAUGE},
 			{Name: "reason", ValueFunc: definition.FromRaw("reason"), Type: sdkMetric.ATTRIBUTE}, // Previously called statusWaitingReason
+			// security posture
+			{Name: "privileged", ValueFunc: definition.Transform(definition.FromRaw("privileged"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "allowPrivilegeEscalation", ValueFunc: definition.Transform(definition.FromRaw("allowPrivilegeEscalation"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "readOnlyRootFilesystem", ValueFunc: definition.Transform(definition.FromRaw("readOnlyRootFilesystem"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "runAsNonRoot", ValueFunc: definition.Transform(definition.FromRaw("runAsNonRoot"), toNumericBoolean), Type: sdkMetric.GAUGE},
+			{Name: "capabilitiesAdded", ValueFunc: definition.FromRaw("capabilitiesAdded"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "hostPathMounts", ValueFunc: definition.FromRaw("hostPathMounts"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 
 			// Inherit from pod
 			{Name: "label.*", ValueFunc: definition.Transform(definition.FromRaw("labels"), kubeletMetric.OneMetricPerLabel), Type: sdkMetric.ATTRIBUTE},
@@ -943,6 +1391,10 @@ var KubeletSpecs = definition.SpecGroups{
 			{Name: "capacity.*", ValueFunc: definition.Transform(definition.FromRaw("capacity"), kubeletMetric.OneAttributePerCapacity), Type: sdkMetric.GAUGE},
 			{Name: "memoryRequestedBytes", ValueFunc: definition.FromRaw("memoryRequestedBytes"), Type: sdkMetric.GAUGE},
 			{Name: "cpuRequestedCores", ValueFunc: definition.Transform(definition.FromRaw("cpuRequestedCores"), toCores), Type: sdkMetric.GAUGE},
+			{Name: "certExpiresInSeconds", ValueFunc: definition.FromRaw("certExpiresInSeconds"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "certIssuer", ValueFunc: definition.FromRaw("certIssuer"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "ksmCertExpiresInSeconds", ValueFunc: definition.FromRaw("ksmCertExpiresInSeconds"), Type: sdkMetric.GAUGE, Optional: true},
+			{Name: "ksmCertIssuer", ValueFunc: definition.FromRaw("ksmCertIssuer"), Type: sdkMetric.ATTRIBUTE, Optional: true},
 			// computed
 			{Name: "fsCapacityUtilization", ValueFunc: toUtilization("fsUsedBytes", "fsCapacityBytes"), Type: sdkMetric.GAUGE},
 			{Name: "allocatableCpuCoresUtilization", ValueFunc: toUtilization("cpuUsedCores", "allocatableCpuCores"), Type: sdkMetric.GAUGE},
@@ -971,6 +1423,71 @@ var KubeletSpecs = definition.SpecGroups{
 	},
 }
 
+// KubeletRollupSpecs are the metric specifications of the resources of the
+// containers of a node summed per workload and namespace, whose raw groups
+// are added by rollup.AddGroups.
+var KubeletRollupSpecs = definition.SpecGroups{
+	rollup.DeploymentGroup:  workloadRollupSpecGroup("deploymentName"),
+	rollup.StatefulSetGroup: workloadRollupSpecGroup("statefulsetName"),
+	rollup.DaemonSetGroup:   workloadRollupSpecGroup("daemonsetName"),
+	rollup.NamespaceGroup: {
+		IDGenerator:   kubeletMetric.FromRawGroupsEntityIDGenerator("namespace"),
+		TypeGenerator: rollup.EntityTypeGenerator,
+		Specs:         rollupSpecs(),
+	},
+}
+
+func workloadRollupSpecGroup(nameAttribute string) definition.SpecGroup {
+	return definition.SpecGroup{
+		IDGenerator:   kubeletMetric.FromRawGroupsEntityIDGenerator("workloadName"),
+		TypeGenerator: rollup.EntityTypeGenerator,
+		Specs: append(rollupSpecs(),
+			definition.Spec{Name: nameAttribute, ValueFunc: definition.FromRaw("workloadName"), Type: sdkMetric.ATTRIBUTE},
+		),
+	}
+}
+
+func rollupSpecs() []definition.Spec {
+	return []definition.Spec{
+		{Name: "namespace", ValueFunc: definition.FromRaw("namespace"), Type: sdkMetric.ATTRIBUTE},
+		{Name: "namespaceName", ValueFunc: definition.FromRaw("namespace"), Type: sdkMetric.ATTRIBUTE},
+		{Name: "nodeName", ValueFunc: definition.FromRaw("nodeName"), Type: sdkMetric.ATTRIBUTE},
+		{Name: "podCount", ValueFunc: definition.FromRaw("podCount"), Type: sdkMetric.GAUGE},
+		{Name: "containerCount", ValueFunc: definition.FromRaw("containerCount"), Type: sdkMetric.GAUGE},
+		{Name: "cpuUsedCores", ValueFunc: definition.Transform(definition.FromRaw("usageNanoCores"), fromNano), Type: sdkMetric.GAUGE, Optional: true},
+		{Name: "memoryWorkingSetBytes", ValueFunc: definition.FromRaw("workingSetBytes"), Type: sdkMetric.GAUGE, Optional: true},
+		{Name: "cpuRequestedCores", ValueFunc: definition.Transform(definition.FromRaw("cpuRequestedCores"), toCores), Type: sdkMetric.GAUGE, Optional: true},
+		{Name: "cpuLimitCores", ValueFunc: definition.Transform(definition.FromRaw("cpuLimitCores"), toCores), Type: sdkMetric.GAUGE, Optional: true},
+		{Name: "memoryRequestedBytes", ValueFunc: definition.FromRaw("memoryRequestedBytes"), Type: sdkMetric.GAUGE, Optional: true},
+		{Name: "memoryLimitBytes", ValueFunc: definition.FromRaw("memoryLimitBytes"), Type: sdkMetric.GAUGE, Optional: true},
+	}
+}
+
+// ClusterCapacitySpecs are the metric specifications of the capacity of the
+// nodes of the cluster, grouped from the API server by capacity.NewGrouper.
+var ClusterCapacitySpecs = definition.SpecGroups{
+	capacity.GroupLabel: {
+		IDGenerator:   kubeletMetric.FromRawGroupsEntityIDGenerator("clusterName"),
+		TypeGenerator: capacity.EntityTypeGenerator,
+		Specs: []definition.Spec{
+			{Name: "nodeGroupLabel", ValueFunc: definition.FromRaw("nodeGroupLabel"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "nodeGroup", ValueFunc: definition.FromRaw("nodeGroup"), Type: sdkMetric.ATTRIBUTE, Optional: true},
+			{Name: "nodeCount", ValueFunc: definition.FromRaw("nodeCount"), Type: sdkMetric.GAUGE},
+			{Name: "notReadyNodeCount", ValueFunc: definition.FromRaw("notReadyNodeCount"), Type: sdkMetric.GAUGE},
+			{Name: "cordonedNodeCount", ValueFunc: definition.FromRaw("cordonedNodeCount"), Type: sdkMetric.GAUGE},
+			{Name: "allocatableCpuCores", ValueFunc: definition.Transform(definition.FromRaw("allocatableMilliCpu"), toCores), Type: sdkMetric.GAUGE},
+			{Name: "allocatableMemoryBytes", ValueFunc: definition.FromRaw("allocatableMemoryBytes"), Type: sdkMetric.GAUGE},
+			{Name: "allocatablePods", ValueFunc: definition.FromRaw("allocatablePods"), Type: sdkMetric.GAUGE},
+			{Name: "requestedCpuCores", ValueFunc: definition.Transform(definition.FromRaw("requestedMilliCpu"), toCores), Type: sdkMetric.GAUGE},
+			{Name: "requestedMemoryBytes", ValueFunc: definition.FromRaw("requestedMemoryBytes"), Type: sdkMetric.GAUGE},
+			{Name: "requestedPods", ValueFunc: definition.FromRaw("requestedPods"), Type: sdkMetric.GAUGE},
+			{Name: "schedulableCpuCores", ValueFunc: definition.Transform(definition.FromRaw("schedulableMilliCpu"), toCores), Type: sdkMetric.GAUGE},
+			{Name: "schedulableMemoryBytes", ValueFunc: definition.FromRaw("schedulableMemoryBytes"), Type: sdkMetric.GAUGE},
+			{Name: "schedulablePods", ValueFunc: definition.FromRaw("schedulablePods"), Type: sdkMetric.GAUGE},
+		},
+	},
+}
+
 func isPersistentVolume() definition.FetchFunc {
 	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
 		name, err := definition.FromRaw("pvcName")(groupLabel, entityID, groups)
@@ -1081,6 +1598,46 @@ func fromPrometheusNumeric(value definition.FetchedValue) (definition.FetchedVal
 	return nil, fmt.Errorf("invalid type value '%v'. Expected 'gauge' or 'counter', got '%T'", value, value)
 }
 
+func toPercentage(value definition.FetchedValue) (definition.FetchedValue, error) {
+	v, ok := value.(float64)
+	if !ok {
+		return nil, errors.New("error transforming to percentage")
+	}
+
+	return v * 100, nil
+}
+
+// sumPrometheusValues returns a FetchFunc that adds the values of all the
+// time-series of a prometheus counter or gauge, as float64.
+func sumPrometheusValues(metricName string) definition.FetchFunc {
+	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		value, err := definition.FromRaw(metricName)(groupLabel, entityID, groups)
+		if err != nil {
+			return nil, err
+		}
+
+		var metrics []prometheus.Metric
+		switch m := value.(type) {
+		case prometheus.Metric:
+			metrics = []prometheus.Metric{m}
+		case []prometheus.Metric:
+			metrics = m
+		default:
+			return nil, fmt.Errorf("incompatible metric type for %s. Expected: Metric or []Metric. Got: %T", metricName, value)
+		}
+
+		var sum float64
+		for _, m := range metrics {
+			v, err := fromPrometheusNumeric(m.Value)
+			if err != nil {
+				return nil, err
+			}
+			sum += v.(float64)
+		}
+		return sum, nil
+	}
+}
+
 // Subtract returns a new FetchFunc that subtracts 2 values. It expects that the values are float64
 func Subtract(left definition.FetchFunc, right definition.FetchFunc) definition.FetchFunc {
 	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
@@ -1097,3 +1654,22 @@ func Subtract(left definition.FetchFunc, right definition.FetchFunc) definition.
 		return result, nil
 	}
 }
+
+// Divide returns a new FetchFunc that divides 2 values. It expects that the values are float64
+func Divide(left definition.FetchFunc, right definition.FetchFunc) definition.FetchFunc {
+	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		leftValue, err := left(groupLabel, entityID, groups)
+		if err != nil {
+			return nil, err
+		}
+		rightValue, err := right(groupLabel, entityID, groups)
+		if err != nil {
+			return nil, err
+		}
+
+		if rightValue.(float64) == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return leftValue.(float64) / rightValue.(float64), nil
+	}
+}
@@ -1,6 +1,8 @@
 package metric
 
 import (
+	"math"
+	"sort"
 	"testing"
 
 	"github.com/newrelic/nri-kubernetes/src/definition"
@@ -8,7 +10,9 @@ import (
 
 	"time"
 
+	model "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFromNano(t *testing.T) {
@@ -125,6 +129,238 @@ func TestSubtract(t *testing.T) {
 	assert.Equal(t, result, float64(5))
 }
 
+func TestDivide(t *testing.T) {
+	left := definition.FetchFunc(func(_, _ string, _ definition.RawGroups) (definition.FetchedValue, error) {
+		return float64(5), nil
+	})
+
+	right := definition.FetchFunc(func(_, _ string, _ definition.RawGroups) (definition.FetchedValue, error) {
+		return float64(20), nil
+	})
+
+	result, err := Divide(left, right)("", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0.25), result)
+
+	_, err = Divide(right, Subtract(left, left))("", "", nil)
+	assert.EqualError(t, err, "division by zero")
+}
+
+func TestSumPrometheusValues(t *testing.T) {
+	raw := definition.RawGroups{
+		"etcd": {
+			"etcd-minikube": {
+				"etcd_mvcc_db_total_size_in_bytes": []prometheus.Metric{
+					{Labels: prometheus.Labels{}, Value: prometheus.GaugeValue(1024)},
+				},
+				"etcd_server_heartbeat_send_failures_total": []prometheus.Metric{
+					{Labels: prometheus.Labels{"To": "a"}, Value: prometheus.CounterValue(2)},
+					{Labels: prometheus.Labels{"To": "b"}, Value: prometheus.CounterValue(3)},
+				},
+			},
+		},
+	}
+
+	value, err := sumPrometheusValues("etcd_mvcc_db_total_size_in_bytes")("etcd", "etcd-minikube", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1024), value)
+
+	value, err = sumPrometheusValues("etcd_server_heartbeat_send_failures_total")("etcd", "etcd-minikube", raw)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(5), value)
+
+	_, err = sumPrometheusValues("etcd_server_quota_backend_bytes")("etcd", "etcd-minikube", raw)
+	assert.Error(t, err)
+}
+
+func TestEtcdDerivedMetrics(t *testing.T) {
+	raw := definition.RawGroups{
+		"etcd": {
+			"etcd-minikube": {
+				"etcd_mvcc_db_total_size_in_bytes":        []prometheus.Metric{{Value: prometheus.GaugeValue(400)}},
+				"etcd_mvcc_db_total_size_in_use_in_bytes": []prometheus.Metric{{Value: prometheus.GaugeValue(300)}},
+				"etcd_server_quota_backend_bytes":         []prometheus.Metric{{Value: prometheus.GaugeValue(1600)}},
+			},
+		},
+	}
+
+	expected := map[string]float64{
+		"etcdMvccDbFragmentationRatio":      0.25,
+		"etcdServerQuotaBackendUtilization": 25,
+	}
+
+	for _, spec := range EtcdSpecs["etcd"].Specs {
+		v, ok := expected[spec.Name]
+		if !ok {
+			continue
+		}
+		value, err := spec.ValueFunc("etcd", "etcd-minikube", raw)
+		assert.NoError(t, err, spec.Name)
+		assert.Equal(t, v, value, spec.Name)
+		delete(expected, spec.Name)
+	}
+	assert.Empty(t, expected, "specs not found")
+}
+
+// specValue returns the value fetched by the spec of the given group with the given name.
+func specValue(t *testing.T, specs definition.SpecGroups, groupLabel, entityID, name string, raw definition.RawGroups) definition.FetchedValue {
+	for _, spec := range specs[groupLabel].Specs {
+		if spec.Name == name {
+			value, err := spec.ValueFunc(groupLabel, entityID, raw)
+			require.NoError(t, err, name)
+			return value
+		}
+	}
+	require.Failf(t, "spec not found", name)
+	return nil
+}
+
+func histogram(count uint64, sum float64, buckets map[float64]uint64) *model.Histogram {
+	h := &model.Histogram{SampleCount: &count, SampleSum: &sum}
+	for _, upperBound := range sortedBounds(buckets) {
+		upperBound, cumulativeCount := upperBound, buckets[upperBound]
+		h.Bucket = append(h.Bucket, &model.Bucket{UpperBound: &upperBound, CumulativeCount: &cumulativeCount})
+	}
+	return h
+}
+
+func sortedBounds(buckets map[float64]uint64) []float64 {
+	bounds := make([]float64, 0, len(buckets))
+	for b := range buckets {
+		bounds = append(bounds, b)
+	}
+	sort.Float64s(bounds)
+	return bounds
+}
+
+func TestAPIServerRequestDurationByVerbAndResource(t *testing.T) {
+	raw := definition.RawGroups{
+		"api-server": {
+			"kube-apiserver-minikube": {
+				"apiserver_request_duration_seconds": []prometheus.Metric{
+					{
+						Labels: prometheus.Labels{"verb": "GET", "resource": "pods", "scope": "namespace"},
+						Value:  histogram(4, 1, map[float64]uint64{0.5: 4, math.Inf(1): 4}),
+					},
+					{
+						Labels: prometheus.Labels{"verb": "GET", "resource": "pods", "scope": "cluster"},
+						Value:  histogram(6, 2, map[float64]uint64{0.5: 6, math.Inf(1): 6}),
+					},
+					{
+						Labels: prometheus.Labels{"verb": "LIST", "resource": "nodes", "scope": "cluster"},
+						Value:  histogram(2, 3, map[float64]uint64{0.5: 0, 2: 2, math.Inf(1): 2}),
+					},
+				},
+			},
+		},
+	}
+
+	value := specValue(t, APIServerSpecs, "api-server", "kube-apiserver-minikube", "apiserverRequestDurationSeconds", raw)
+	require.IsType(t, definition.FetchedDimensionalValues{}, value)
+	values := value.(definition.FetchedDimensionalValues).FetchedValues()
+
+	// The series of a verb and resource are merged, whatever their other labels.
+	assert.Equal(t, uint64(10), values["apiserverRequestDurationSeconds_resource_pods_verb_GET_count"])
+	assert.Equal(t, float64(3), values["apiserverRequestDurationSeconds_resource_pods_verb_GET_sum"])
+	assert.Equal(t, uint64(2), values["apiserverRequestDurationSeconds_resource_nodes_verb_LIST_count"])
+	assert.Equal(t, float64(3), values["apiserverRequestDurationSeconds_resource_nodes_verb_LIST_sum"])
+	assert.NotContains(t, values, "apiserverRequestDurationSeconds_verb_GET_count")
+}
+
+func TestSchedulerSpecs(t *testing.T) {
+	raw := definition.RawGroups{
+		"scheduler": {
+			"kube-scheduler-minikube": {
+				"scheduler_pending_pods": []prometheus.Metric{
+					{Labels: prometheus.Labels{"queue": "active"}, Value: prometheus.GaugeValue(2)},
+					{Labels: prometheus.Labels{"queue": "unschedulable"}, Value: prometheus.GaugeValue(5)},
+				},
+				"scheduler_scheduling_attempt_duration_seconds": []prometheus.Metric{
+					{
+						Labels: prometheus.Labels{"result": "scheduled", "profile": "default-scheduler"},
+						Value:  histogram(8, 0.4, map[float64]uint64{0.1: 8, math.Inf(1): 8}),
+					},
+					{
+						Labels: prometheus.Labels{"result": "unschedulable", "profile": "default-scheduler"},
+						Value:  histogram(2, 3, map[float64]uint64{0.1: 0, 2: 2, math.Inf(1): 2}),
+					},
+				},
+				"scheduler_scheduling_algorithm_duration_seconds": []prometheus.Metric{
+					{Value: histogram(10, 0.2, map[float64]uint64{0.1: 10, math.Inf(1): 10})},
+				},
+				"scheduler_e2e_scheduling_duration_seconds": []prometheus.Metric{
+					{
+						Labels: prometheus.Labels{"result": "scheduled", "profile": "default-scheduler"},
+						Value:  histogram(8, 0.8, map[float64]uint64{0.1: 8, math.Inf(1): 8}),
+					},
+				},
+				"scheduler_framework_extension_point_duration_seconds": []prometheus.Metric{
+					{
+						Labels: prometheus.Labels{"extension_point": "Filter", "status": "Success", "profile": "default-scheduler"},
+						Value:  histogram(8, 0.08, map[float64]uint64{0.1: 8, math.Inf(1): 8}),
+					},
+					{
+						Labels: prometheus.Labels{"extension_point": "Filter", "status": "Unschedulable", "profile": "default-scheduler"},
+						Value:  histogram(2, 0.02, map[float64]uint64{0.1: 2, math.Inf(1): 2}),
+					},
+				},
+				"scheduler_pod_scheduling_attempts": []prometheus.Metric{
+					{Value: histogram(8, 10, map[float64]uint64{1: 6, 2: 8, math.Inf(1): 8})},
+				},
+			},
+		},
+	}
+
+	fetched := func(name string) definition.FetchedValues {
+		value := specValue(t, SchedulerSpecs, "scheduler", "kube-scheduler-minikube", name, raw)
+		require.IsType(t, definition.FetchedDimensionalValues{}, value, name)
+		return value.(definition.FetchedDimensionalValues).FetchedValues()
+	}
+
+	assert.Equal(t, definition.FetchedValues{
+		"schedulerPendingPods_queue_active":        prometheus.GaugeValue(2),
+		"schedulerPendingPods_queue_unschedulable": prometheus.GaugeValue(5),
+	}, fetched("schedulerPendingPods"))
+
+	attempts := fetched("schedulerSchedulingAttemptDurationSeconds")
+	assert.Equal(t, uint64(8), attempts["schedulerSchedulingAttemptDurationSeconds_result_scheduled_count"])
+	assert.Equal(t, 0.4, attempts["schedulerSchedulingAttemptDurationSeconds_result_scheduled_sum"])
+	assert.Equal(t, uint64(2), attempts["schedulerSchedulingAttemptDurationSeconds_result_unschedulable_count"])
+	assert.Equal(t, float64(3), attempts["schedulerSchedulingAttemptDurationSeconds_result_unschedulable_sum"])
+
+	algorithm := fetched("schedulerSchedulingAlgorithmDurationSeconds")
+	assert.Equal(t, uint64(10), algorithm["schedulerSchedulingAlgorithmDurationSeconds_count"])
+
+	e2e := fetched("schedulerE2eSchedulingDurationSeconds")
+	assert.Equal(t, uint64(8), e2e["schedulerE2eSchedulingDurationSeconds_result_scheduled_count"])
+
+	// The statuses of an extension point are merged.
+	extensionPoints := fetched("schedulerFrameworkExtensionPointDurationSeconds")
+	assert.Equal(t, uint64(10), extensionPoints["schedulerFrameworkExtensionPointDurationSeconds_extension_point_Filter_count"])
+	assert.Equal(t, 0.1, extensionPoints["schedulerFrameworkExtensionPointDurationSeconds_extension_point_Filter_sum"])
+
+	podAttempts := fetched("schedulerPodSchedulingAttempts")
+	assert.Equal(t, uint64(8), podAttempts["schedulerPodSchedulingAttempts_count"])
+	assert.Equal(t, float64(10), podAttempts["schedulerPodSchedulingAttempts_sum"])
+}
+
+func TestSchedulerQueries(t *testing.T) {
+	queried := make(map[string]bool)
+	for _, q := range SchedulerQueries {
+		queried[q.MetricName] = true
+	}
+	for _, name := range []string{
+		"scheduler_pending_pods",
+		"scheduler_scheduling_attempt_duration_seconds",
+		"scheduler_scheduling_algorithm_duration_seconds",
+		"scheduler_e2e_scheduling_duration_seconds",
+		"scheduler_framework_extension_point_duration_seconds",
+		"scheduler_pod_scheduling_attempts",
+	} {
+		assert.True(t, queried[name], "%s is not queried", name)
+	}
+}
+
 func TestUtilization(t *testing.T) {
 	raw := definition.RawGroups{
 		"group1": {
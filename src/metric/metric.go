@@ -18,6 +18,17 @@ func K8sMetricSetTypeGuesser(_, groupLabel, _ string, _ definition.RawGroups) (s
 	return fmt.Sprintf("K8s%vSample", sampleName), nil
 }
 
+// K8sDimensionalMetricSetTypeGuesser is the metric set type guesser for the
+// metric sets reported per combination of dimensions, e.g.
+// K8sApiServerDimensionalSample.
+func K8sDimensionalMetricSetTypeGuesser(clusterName, groupLabel, entityID string, groups definition.RawGroups) (string, error) {
+	sampleName, err := K8sMetricSetTypeGuesser(clusterName, groupLabel, entityID, groups)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(sampleName, "Sample") + "DimensionalSample", nil
+}
+
 // K8sClusterMetricsManipulator adds 'clusterName' metric to the MetricSet 'ms',
 // taking the value from 'clusterName' argument.
 func K8sClusterMetricsManipulator(ms metric.MetricSet, _ sdk.Entity, clusterName string) error {
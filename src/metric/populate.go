@@ -8,10 +8,51 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/sdk"
 	"github.com/newrelic/nri-kubernetes/src/data"
 	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/redact"
 	"k8s.io/apimachinery/pkg/version"
 )
 
 type k8sPopulator struct {
+	dimensional bool
+	sampler     definition.CounterSampler
+	redactor    *redact.Redactor
+	limits      *definition.AttributeLimits
+}
+
+// K8sPopulatorOption configures a Kubernetes aware populator.
+type K8sPopulatorOption func(*k8sPopulator)
+
+// WithDimensionalMetricSets configures the populator to also report the
+// values of labelled metrics in one metric set per combination of labels,
+// with the labels as attributes.
+func WithDimensionalMetricSets() K8sPopulatorOption {
+	return func(p *k8sPopulator) {
+		p.dimensional = true
+	}
+}
+
+// WithCounterSampler configures the populator to compute the DELTA and RATE
+// metrics with the given sampler, instead of the SDK.
+func WithCounterSampler(sampler definition.CounterSampler) K8sPopulatorOption {
+	return func(p *k8sPopulator) {
+		p.sampler = sampler
+	}
+}
+
+// WithRedactor configures the populator to redact the attributes of the
+// metric sets it populates with the given redactor.
+func WithRedactor(redactor *redact.Redactor) K8sPopulatorOption {
+	return func(p *k8sPopulator) {
+		p.redactor = redactor
+	}
+}
+
+// WithAttributeLimits configures the populator to bound the metrics populated
+// from the FetchedValues returned by the specs with the given limits.
+func WithAttributeLimits(limits *definition.AttributeLimits) K8sPopulatorOption {
+	return func(p *k8sPopulator) {
+		p.limits = limits
+	}
 }
 
 // MultipleErrs represents a bunch of errs.
@@ -39,9 +80,35 @@ func (p *k8sPopulator) Populate(
 	clusterName string,
 	k8sVersion *version.Info,
 ) data.PopulateResult {
-	populatorFunc := definition.IntegrationProtocol2PopulateFunc(i, clusterName, k8sVersion, K8sMetricSetTypeGuesser, K8sEntityMetricsManipulator, K8sClusterMetricsManipulator)
+	// The metric sets populated by previous jobs are already redacted.
+	populatedMetricSets := make(map[*sdk.EntityData]int, len(i.Data))
+	for _, e := range i.Data {
+		populatedMetricSets[e] = len(e.Metrics)
+	}
+
+	options := []definition.PopulateOption{
+		definition.WithCounterSampler(p.sampler),
+		definition.WithAttributeLimits(p.limits),
+		definition.WithMetricSetManipulators(K8sEntityMetricsManipulator, K8sClusterMetricsManipulator),
+	}
+
+	populatorFunc := definition.NewIntegrationProtocol2PopulateFunc(i, clusterName, k8sVersion, K8sMetricSetTypeGuesser, options...)
 	ok, errs := populatorFunc(groups, specGroups)
 
+	if p.dimensional {
+		dimensionalPopulatorFunc := definition.IntegrationProtocol2DimensionalPopulateFunc(i, clusterName, K8sDimensionalMetricSetTypeGuesser, options...)
+		_, dimensionalErrs := dimensionalPopulatorFunc(groups, specGroups)
+		errs = append(errs, dimensionalErrs...)
+	}
+
+	if p.redactor != nil {
+		for _, e := range i.Data {
+			for _, ms := range e.Metrics[populatedMetricSets[e]:] {
+				p.redactor.Redact(ms, e.Entity.Type)
+			}
+		}
+	}
+
 	if len(errs) > 0 {
 		return data.PopulateResult{Errors: errs, Populated: ok}
 	}
@@ -58,6 +125,10 @@ func (p *k8sPopulator) Populate(
 }
 
 // NewK8sPopulator creates a Kubernetes aware populator.
-func NewK8sPopulator() data.Populator {
-	return &k8sPopulator{}
+func NewK8sPopulator(options ...K8sPopulatorOption) data.Populator {
+	p := &k8sPopulator{}
+	for _, option := range options {
+		option(p)
+	}
+	return p
 }
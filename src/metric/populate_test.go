@@ -8,9 +8,13 @@ import (
 
 	sdkMetric "github.com/newrelic/infra-integrations-sdk/metric"
 	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/capacity"
 	"github.com/newrelic/nri-kubernetes/src/data"
 	"github.com/newrelic/nri-kubernetes/src/definition"
+	kubeletMetric "github.com/newrelic/nri-kubernetes/src/kubelet/metric"
 	"github.com/newrelic/nri-kubernetes/src/kubelet/metric/testdata"
+	"github.com/newrelic/nri-kubernetes/src/redact"
+	"github.com/newrelic/nri-kubernetes/src/rollup"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/version"
 )
@@ -52,6 +56,8 @@ var expectedMetrics = []*sdk.EntityData{
 				"net.errorsPerSecond":            0.,
 				"createdAt":                      parseTime("2018-02-14T16:26:33Z").Unix(),
 				"startTime":                      parseTime("2018-02-14T16:26:33Z").Unix(),
+				"schedulingLatencySeconds":       27.,
+				"startupLatencySeconds":          1119285.,
 				"createdKind":                    "DaemonSet",
 				"createdBy":                      "newrelic-infra",
 				"nodeIP":                         "192.168.99.100",
@@ -62,11 +68,21 @@ var expectedMetrics = []*sdk.EntityData{
 				"isReady":                        1,
 				"status":                         "Running",
 				"isScheduled":                    1,
+				"hostNetwork":                    0,
+				"hostPID":                        0,
+				"hostIPC":                        0,
+				"runAsNonRoot":                   0,
+				"serviceAccountName":             "default",
+				"hostPathVolumes":                "/,/var/run/docker.sock",
 				"label.controller-revision-hash": "3887482659",
 				"label.name":                     "newrelic-infra",
 				"label.pod-template-generation":  "1",
 				"displayName":                    "newrelic-infra-rz225", // From manipulator
 				"clusterName":                    "test-cluster",         // From manipulator
+				// The populator reports all the annotations in the raw data, the scrape job only keeps the allowed ones.
+				"annotation.kubernetes_io_config_seen":                 "2018-02-27T15:21:31.663551743Z",
+				"annotation.kubernetes_io_config_source":               "api",
+				"annotation.scheduler_alpha_kubernetes_io_tolerations": "[{\"operator\": \"Exists\", \"effect\": \"NoSchedule\"}]\n",
 			},
 		},
 		Inventory: sdk.Inventory{},
@@ -94,6 +110,7 @@ var expectedMetrics = []*sdk.EntityData{
 				"containerName":         "newrelic-infra",
 				"containerID":           "69d7203a8f2d2d027ffa51d61002eac63357f22a17403363ef79e66d1c3146b2",
 				"containerImage":        "newrelic/ohaik:1.0.0-beta3",
+				"containerType":         "regular",
 				"containerImageID":      "sha256:1a95d0df2997f93741fbe2a15d2c31a394e752fd942ec29bf16a44163342f6a1",
 				"namespace":             "kube-system",
 				"namespaceName":         "kube-system",
@@ -107,6 +124,11 @@ var expectedMetrics = []*sdk.EntityData{
 				"status":                "Running",
 				"isReady":               1,
 				//"reason":               "",      // TODO ?
+				"privileged":                     0,
+				"allowPrivilegeEscalation":       1,
+				"readOnlyRootFilesystem":         0,
+				"runAsNonRoot":                   0,
+				"hostPathMounts":                 "/,/var/run/docker.sock",
 				"displayName":                    "newrelic-infra", // From manipulator
 				"clusterName":                    "test-cluster",   // From manipulator
 				"label.controller-revision-hash": "3887482659",
@@ -168,3 +190,134 @@ func TestPopulateK8s(t *testing.T) {
 	expectedMetrics[0].Inventory = expectedInventory
 	assert.ElementsMatch(t, expectedMetrics, i.Data)
 }
+
+func TestPopulateK8s_WithRedactor(t *testing.T) {
+	specs := definition.SpecGroups{
+		"pod": {
+			IDGenerator:   kubeletMetric.FromRawEntityIDGroupEntityIDGenerator("namespace"),
+			TypeGenerator: kubeletMetric.FromRawGroupsEntityTypeGenerator,
+			Specs: []definition.Spec{
+				{Name: "podName", ValueFunc: definition.FromRaw("podName"), Type: sdkMetric.ATTRIBUTE},
+				{Name: "label.owner-email", ValueFunc: definition.FromRaw("ownerEmail"), Type: sdkMetric.ATTRIBUTE},
+			},
+		},
+	}
+	group := func(podName string) definition.RawGroups {
+		return definition.RawGroups{
+			"pod": {
+				"default_" + podName: {
+					"namespace":  "default",
+					"podName":    podName,
+					"ownerEmail": "jane@example.com",
+				},
+			},
+		}
+	}
+
+	i, err := sdk.NewIntegrationProtocol2("test", "test", new(struct{}))
+	assert.NoError(t, err)
+	k8sVersion := &version.Info{GitVersion: "v1.15.42"}
+
+	result := NewK8sPopulator().Populate(group("nginx"), specs, i, "test-cluster", k8sVersion)
+	assert.Empty(t, result.Errors)
+
+	redactor, err := redact.NewRedactor(redact.Rules{Drop: []string{`^label\.owner-email$`}})
+	assert.NoError(t, err)
+	result = NewK8sPopulator(WithRedactor(redactor)).Populate(group("redis"), specs, i, "test-cluster", k8sVersion)
+	assert.Empty(t, result.Errors)
+
+	// Only the metric sets populated with the redactor are redacted.
+	emails := map[string]interface{}{}
+	for _, e := range i.Data {
+		for _, ms := range e.Metrics {
+			if podName, ok := ms["podName"]; ok {
+				emails[podName.(string)] = ms["label.owner-email"]
+			}
+		}
+	}
+	assert.Equal(t, map[string]interface{}{"nginx": "jane@example.com", "redis": nil}, emails)
+	assert.Equal(t, redact.Counts{Dropped: 1}, redactor.Flush())
+}
+
+func TestPopulateK8s_Rollups(t *testing.T) {
+	groups := rollup.AddGroups(definition.RawGroups{
+		"container": {
+			"default_nginx-a_nginx": {
+				"namespace":         "default",
+				"podName":           "nginx-a",
+				"nodeName":          "worker-1",
+				"deploymentName":    "nginx",
+				"usageNanoCores":    uint64(250000000),
+				"cpuRequestedCores": int64(500),
+			},
+		},
+	})
+
+	i, err := sdk.NewIntegrationProtocol2("test", "test", new(struct{}))
+	assert.NoError(t, err)
+	result := NewK8sPopulator().Populate(groups, KubeletRollupSpecs, i, "test-cluster", &version.Info{GitVersion: "v1.15.42"})
+	assert.Empty(t, result.Errors)
+
+	samples := map[string]sdkMetric.MetricSet{}
+	for _, e := range i.Data {
+		for _, ms := range e.Metrics {
+			samples[e.Entity.Type+"/"+e.Entity.Name] = ms
+		}
+	}
+
+	deployment := samples["k8s:test-cluster:default:deployment/nginx"]
+	assert.Equal(t, "K8sDeploymentNodeUsageSample", deployment["event_type"])
+	assert.Equal(t, "nginx", deployment["deploymentName"])
+	assert.Equal(t, "worker-1", deployment["nodeName"])
+	assert.Equal(t, 0.25, deployment["cpuUsedCores"])
+	assert.Equal(t, 0.5, deployment["cpuRequestedCores"])
+	assert.Equal(t, 1, deployment["podCount"])
+
+	namespace := samples["k8s:test-cluster:namespace/default"]
+	assert.Equal(t, "K8sNamespaceNodeUsageSample", namespace["event_type"])
+	assert.Equal(t, "default", namespace["namespaceName"])
+}
+
+func TestPopulateK8s_ClusterCapacity(t *testing.T) {
+	groups := definition.RawGroups{
+		capacity.GroupLabel: {
+			"cluster": {
+				"clusterName":            "test-cluster",
+				"nodeCount":              3,
+				"notReadyNodeCount":      1,
+				"cordonedNodeCount":      0,
+				"allocatableMilliCpu":    int64(6000),
+				"allocatableMemoryBytes": int64(12884901888),
+				"allocatablePods":        int64(330),
+				"requestedMilliCpu":      int64(2500),
+				"requestedMemoryBytes":   int64(5905580032),
+				"requestedPods":          int64(4),
+				"schedulableMilliCpu":    int64(1500),
+				"schedulableMemoryBytes": int64(2147483648),
+				"schedulablePods":        int64(216),
+			},
+		},
+	}
+
+	i, err := sdk.NewIntegrationProtocol2("test", "test", new(struct{}))
+	assert.NoError(t, err)
+	result := NewK8sPopulator().Populate(groups, ClusterCapacitySpecs, i, "test-cluster", &version.Info{GitVersion: "v1.15.42"})
+	assert.Empty(t, result.Errors)
+
+	// The capacity is reported in the cluster entity, along the K8sClusterSample.
+	assert.Len(t, i.Data, 1)
+	assert.Equal(t, "k8s:cluster", i.Data[0].Entity.Type)
+	assert.Equal(t, "test-cluster", i.Data[0].Entity.Name)
+
+	var capacitySample sdkMetric.MetricSet
+	for _, ms := range i.Data[0].Metrics {
+		if ms["event_type"] == "K8sClusterCapacitySample" {
+			capacitySample = ms
+		}
+	}
+	assert.Equal(t, 6.0, capacitySample["allocatableCpuCores"])
+	assert.Equal(t, 2.5, capacitySample["requestedCpuCores"])
+	assert.Equal(t, 1.5, capacitySample["schedulableCpuCores"])
+	assert.Equal(t, 1, capacitySample["notReadyNodeCount"])
+	assert.NotContains(t, capacitySample, "nodeGroup")
+}
@@ -0,0 +1,145 @@
+package namespace
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/newrelic/nri-kubernetes/src/apiserver"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+)
+
+// Config holds the criteria of a Filter. Namespaces matching any of the
+// exclusion criteria are filtered out. When any inclusion criteria is set,
+// only the namespaces matching at least one of them are kept.
+type Config struct {
+	Include       []string
+	IncludeRegex  string
+	LabelSelector string
+	Exclude       []string
+	ExcludeRegex  string
+}
+
+// IsSet returns whether any criteria is configured.
+func (c Config) IsSet() bool {
+	return len(c.Include) > 0 || c.IncludeRegex != "" || c.LabelSelector != "" ||
+		len(c.Exclude) > 0 || c.ExcludeRegex != ""
+}
+
+// Filter decides which namespaces are monitored.
+type Filter struct {
+	include      map[string]bool
+	includeRegex *regexp.Regexp
+	// selected holds the namespaces matching the label selector, if any.
+	selected     map[string]bool
+	exclude      map[string]bool
+	excludeRegex *regexp.Regexp
+}
+
+// NewFilter returns a Filter for the given criteria. The namespaces matching
+// the label selector are listed from the API server once, when the Filter is
+// created.
+func NewFilter(c Config, apiServer apiserver.Client) (*Filter, error) {
+	f := &Filter{
+		include: set(c.Include),
+		exclude: set(c.Exclude),
+	}
+
+	var err error
+	if f.includeRegex, err = compile(c.IncludeRegex); err != nil {
+		return nil, err
+	}
+	if f.excludeRegex, err = compile(c.ExcludeRegex); err != nil {
+		return nil, err
+	}
+
+	if c.LabelSelector != "" {
+		names, err := apiServer.GetNamespaceNames(c.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		f.selected = set(names)
+	}
+
+	return f, nil
+}
+
+func set(names []string) map[string]bool {
+	s := make(map[string]bool, len(names))
+	for _, name := range names {
+		s[name] = true
+	}
+	return s
+}
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace regex %q: %s", expr, err)
+	}
+	return re, nil
+}
+
+// Match returns whether the given namespace is monitored.
+func (f *Filter) Match(namespace string) bool {
+	if f.exclude[namespace] || (f.excludeRegex != nil && f.excludeRegex.MatchString(namespace)) {
+		return false
+	}
+
+	if len(f.include) == 0 && f.includeRegex == nil && f.selected == nil {
+		return true
+	}
+
+	return f.include[namespace] ||
+		(f.includeRegex != nil && f.includeRegex.MatchString(namespace)) ||
+		f.selected[namespace]
+}
+
+// FilterGroups returns the given groups without the raw entities which
+// belong to filtered namespaces, and the number of removed entities. Raw
+// entities without namespace, like nodes, are always kept. The given groups
+// are not modified, as they may be shared with other jobs.
+func (f *Filter) FilterGroups(groups definition.RawGroups) (definition.RawGroups, int) {
+	filtered := make(definition.RawGroups, len(groups))
+	removed := 0
+	for groupLabel, entities := range groups {
+		filtered[groupLabel] = make(map[string]definition.RawMetrics, len(entities))
+		for rawEntityID, metrics := range entities {
+			if ns, ok := Of(metrics); ok && !f.Match(ns) {
+				removed++
+				continue
+			}
+			filtered[groupLabel][rawEntityID] = metrics
+		}
+	}
+	return filtered, removed
+}
+
+// Of returns the namespace of a raw entity, which is either set in the
+// namespace raw metric, like in the kubelet groups, or in the namespace label
+// of its Prometheus metrics, like in the KSM groups.
+func Of(metrics definition.RawMetrics) (string, bool) {
+	if ns, ok := metrics["namespace"].(string); ok {
+		return ns, true
+	}
+
+	for _, value := range metrics {
+		switch m := value.(type) {
+		case prometheus.Metric:
+			if ns, ok := m.Labels["namespace"]; ok {
+				return ns, true
+			}
+		case []prometheus.Metric:
+			for _, metric := range m {
+				if ns, ok := metric.Labels["namespace"]; ok {
+					return ns, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
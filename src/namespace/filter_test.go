@@ -0,0 +1,128 @@
+package namespace
+
+import (
+	"testing"
+
+	"github.com/newrelic/nri-kubernetes/src/apiserver"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Match(t *testing.T) {
+	apiServer := apiserver.TestAPIServer{Namespaces: map[string]apiserver.NamespaceNames{
+		"team=payments": {"payments", "payments-ci-42"},
+	}}
+
+	tests := []struct {
+		name     string
+		config   Config
+		included []string
+		excluded []string
+	}{
+		{
+			name:     "no criteria",
+			config:   Config{},
+			included: []string{"default", "kube-system"},
+		},
+		{
+			name:     "exclude names and regex",
+			config:   Config{Exclude: []string{"kube-system"}, ExcludeRegex: "^ci-"},
+			included: []string{"default", "payments-ci-42"},
+			excluded: []string{"kube-system", "ci-1234"},
+		},
+		{
+			name:     "include names and regex",
+			config:   Config{Include: []string{"default"}, IncludeRegex: "^kube-"},
+			included: []string{"default", "kube-system", "kube-public"},
+			excluded: []string{"payments", "ci-1234"},
+		},
+		{
+			name:     "include label selector",
+			config:   Config{LabelSelector: "team=payments"},
+			included: []string{"payments", "payments-ci-42"},
+			excluded: []string{"default"},
+		},
+		{
+			name:     "exclusion wins",
+			config:   Config{LabelSelector: "team=payments", ExcludeRegex: "-ci-"},
+			included: []string{"payments"},
+			excluded: []string{"payments-ci-42", "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.config, apiServer)
+			require.NoError(t, err)
+
+			for _, ns := range tt.included {
+				assert.True(t, f.Match(ns), "namespace %s should be included", ns)
+			}
+			for _, ns := range tt.excluded {
+				assert.False(t, f.Match(ns), "namespace %s should be excluded", ns)
+			}
+		})
+	}
+}
+
+func TestNewFilter_Errors(t *testing.T) {
+	_, err := NewFilter(Config{IncludeRegex: "ci-("}, apiserver.TestAPIServer{})
+	assert.Error(t, err)
+
+	_, err = NewFilter(Config{LabelSelector: "team=unknown"}, apiserver.TestAPIServer{})
+	assert.Error(t, err)
+}
+
+func TestFilter_FilterGroups(t *testing.T) {
+	groups := definition.RawGroups{
+		"pod": {
+			"ci-1234_runner": {"namespace": "ci-1234", "podName": "runner"},
+			"default_nginx":  {"namespace": "default", "podName": "nginx"},
+		},
+		"deployment": {
+			"ci-1234_runner": {
+				"kube_deployment_labels": prometheus.Metric{
+					Labels: prometheus.Labels{"namespace": "ci-1234", "deployment": "runner"},
+				},
+			},
+			"default_nginx": {
+				"kube_deployment_labels": prometheus.Metric{
+					Labels: prometheus.Labels{"namespace": "default", "deployment": "nginx"},
+				},
+			},
+		},
+		"container": {
+			"ci-1234_runner_runner": {
+				"container_memory_usage_bytes": []prometheus.Metric{
+					{Labels: prometheus.Labels{"namespace": "ci-1234"}},
+				},
+			},
+		},
+		"node": {
+			"worker-1": {"nodeName": "worker-1"},
+		},
+	}
+
+	f, err := NewFilter(Config{ExcludeRegex: "^ci-"}, apiserver.TestAPIServer{})
+	require.NoError(t, err)
+
+	filtered, removed := f.FilterGroups(groups)
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, definition.RawGroups{
+		"pod": {
+			"default_nginx": {"namespace": "default", "podName": "nginx"},
+		},
+		"deployment": {
+			"default_nginx": groups["deployment"]["default_nginx"],
+		},
+		"container": {},
+		"node": {
+			"worker-1": {"nodeName": "worker-1"},
+		},
+	}, filtered)
+
+	// The given groups are left untouched.
+	assert.Len(t, groups["pod"], 2)
+}
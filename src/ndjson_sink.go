@@ -0,0 +1,38 @@
+package main
+
+import (
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/export"
+	"github.com/sirupsen/logrus"
+)
+
+// writeNDJSON appends the populated metrics to the NDJSONFile, if it is set.
+func writeNDJSON(integration *sdk.IntegrationProtocol2, logger *logrus.Logger) {
+	if args.NDJSONFile == "" {
+		return
+	}
+
+	maxAge, err := time.ParseDuration(args.NDJSONMaxAge)
+	if err != nil {
+		logger.WithError(err).Errorf("while parsing the NDJSON max age value. Defaulting to %s", defaultNDJSONMaxAge)
+		maxAge = defaultNDJSONMaxAge
+	}
+
+	rotationInterval, err := time.ParseDuration(args.NDJSONRotationInterval)
+	if err != nil {
+		logger.WithError(err).Errorf("while parsing the NDJSON rotation interval value. Defaulting to %s", defaultNDJSONRotationInterval)
+		rotationInterval = defaultNDJSONRotationInterval
+	}
+
+	sink := &export.NDJSONSink{
+		Path:             args.NDJSONFile,
+		MaxSize:          int64(args.NDJSONMaxSizeMB) * 1024 * 1024,
+		RotationInterval: rotationInterval,
+		MaxAge:           maxAge,
+	}
+	if err := sink.Write(integration.Data, time.Now()); err != nil {
+		logger.WithError(err).Warnf("writing the metrics to %s", args.NDJSONFile)
+	}
+}
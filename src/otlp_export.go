@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/export"
+	"github.com/sirupsen/logrus"
+)
+
+// exportOTLP exports the populated metrics to the OpenTelemetry collector at
+// OTLPEndpoint, if it is set. Failing to export them doesn't prevent them from
+// being published.
+func exportOTLP(integration *sdk.IntegrationProtocol2, logger *logrus.Logger) {
+	if args.OTLPEndpoint == "" {
+		return
+	}
+
+	timeout := time.Millisecond * time.Duration(args.Timeout)
+	exporter, err := export.NewOTLPExporter(args.OTLPEndpoint, args.OTLPProtocol, timeout, integrationVersion)
+	if err != nil {
+		logger.WithError(err).Error("configuring the OTLP exporter")
+		return
+	}
+
+	if err := exporter.Export(integration.Data); err != nil {
+		logger.WithError(err).Warn("exporting the metrics to the OTLP collector")
+	}
+}
@@ -0,0 +1,239 @@
+package owner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/newrelic/nri-kubernetes/src/apiserver"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+)
+
+// Raw metrics set in the pod and container raw entities by the Resolver.
+const (
+	WorkloadKindKey   = "workloadKind"
+	WorkloadNameKey   = "workloadName"
+	DeploymentNameKey = "deploymentName"
+)
+
+// maxDepth bounds the length of the chains of owners, in case of cycles.
+const maxDepth = 8
+
+// ksmNone is the value of the owner labels of KSM for objects without owner.
+const ksmNone = "<none>"
+
+// Resolver resolves the workload of the pods, which is the object at the top
+// of the chain of their controllers, e.g. the Deployment owning the
+// ReplicaSet owning a pod, the CronJob owning the Job owning it, or the
+// custom resource of an operator.
+//
+// The owners of the ReplicaSets are taken from the kube_replicaset_owner
+// metric of KSM when it is available, otherwise the owners of the ReplicaSets
+// and Jobs are looked up in the API server. The lookups are cached for the
+// life of the Resolver, besides the cache of the API server client.
+type Resolver struct {
+	apiServer apiserver.Client
+	logger    *logrus.Logger
+	owners    map[string]*apiserver.OwnerReference
+}
+
+// NewResolver returns a Resolver looking up the intermediate objects in the
+// given API server.
+func NewResolver(apiServer apiserver.Client, logger *logrus.Logger) *Resolver {
+	return &Resolver{
+		apiServer: apiServer,
+		logger:    logger,
+		owners:    make(map[string]*apiserver.OwnerReference),
+	}
+}
+
+// workload is the result of the resolution of the owners of a pod.
+type workload struct {
+	kind, name string
+	// deployment is the name of the Deployment in the chain of owners, if
+	// any, or the one guessed from the name of the ReplicaSet when its owner
+	// can't be resolved.
+	deployment string
+}
+
+// ResolveGroups returns the given groups with the kind and name of their
+// workload set in the pod and container raw entities, under WorkloadKindKey
+// and WorkloadNameKey. The name of the Deployment in their chain of owners is
+// set under DeploymentNameKey, and removed when there is none. The given
+// groups are not modified, as they may be shared with other jobs.
+func (r *Resolver) ResolveGroups(groups definition.RawGroups) definition.RawGroups {
+	resolved := make(definition.RawGroups, len(groups))
+	for groupLabel, entities := range groups {
+		resolved[groupLabel] = entities
+	}
+
+	workloads := make(map[string]workload)
+	if pods, ok := groups["pod"]; ok {
+		resolved["pod"] = make(map[string]definition.RawMetrics, len(pods))
+		for rawEntityID, metrics := range pods {
+			w, ok := r.resolvePod(groups, metrics)
+			if !ok {
+				resolved["pod"][rawEntityID] = metrics
+				continue
+			}
+			workloads[rawEntityID] = w
+			resolved["pod"][rawEntityID] = withWorkload(metrics, w)
+		}
+	}
+
+	if containers, ok := groups["container"]; ok {
+		resolved["container"] = make(map[string]definition.RawMetrics, len(containers))
+		for rawEntityID, metrics := range containers {
+			w, ok := workloads[podID(metrics)]
+			if !ok {
+				resolved["container"][rawEntityID] = metrics
+				continue
+			}
+			resolved["container"][rawEntityID] = withWorkload(metrics, w)
+		}
+	}
+
+	return resolved
+}
+
+func withWorkload(metrics definition.RawMetrics, w workload) definition.RawMetrics {
+	copied := make(definition.RawMetrics, len(metrics)+3)
+	for k, v := range metrics {
+		copied[k] = v
+	}
+
+	copied[WorkloadKindKey] = w.kind
+	copied[WorkloadNameKey] = w.name
+	if w.deployment != "" {
+		copied[DeploymentNameKey] = w.deployment
+	} else {
+		delete(copied, DeploymentNameKey)
+	}
+	return copied
+}
+
+func (r *Resolver) resolvePod(groups definition.RawGroups, metrics definition.RawMetrics) (workload, bool) {
+	ns, ok := namespace.Of(metrics)
+	if !ok {
+		return workload{}, false
+	}
+	owner, ok := podOwner(metrics)
+	if !ok {
+		return workload{}, false
+	}
+
+	var w workload
+	for depth := 0; depth < maxDepth; depth++ {
+		if owner.Kind == "Deployment" {
+			w.deployment = owner.Name
+		}
+
+		next, ok := r.ownerOf(groups, ns, owner)
+		if !ok && owner.Kind == "ReplicaSet" && w.deployment == "" {
+			w.deployment = replicasetNameToDeploymentName(owner.Name)
+		}
+		if !ok || next.Kind == "" {
+			break
+		}
+		owner = next
+	}
+
+	w.kind = owner.Kind
+	w.name = owner.Name
+	return w, true
+}
+
+// podOwner returns the controller of a pod, which is set in the createdKind
+// and createdBy raw metrics of the kubelet groups, or in the labels of the
+// kube_pod_owner or kube_pod_info metrics of the KSM groups.
+func podOwner(metrics definition.RawMetrics) (apiserver.OwnerReference, bool) {
+	kind, _ := metrics["createdKind"].(string)
+	name, _ := metrics["createdBy"].(string)
+	if kind != "" && name != "" {
+		return apiserver.OwnerReference{Kind: kind, Name: name}, true
+	}
+
+	if m, ok := metrics["kube_pod_owner"].(prometheus.Metric); ok {
+		if owner, ok := ksmOwner(m.Labels, "owner_kind", "owner_name"); ok {
+			return owner, true
+		}
+	}
+
+	if m, ok := metrics["kube_pod_info"].(prometheus.Metric); ok {
+		if owner, ok := ksmOwner(m.Labels, "created_by_kind", "created_by_name"); ok {
+			return owner, true
+		}
+	}
+
+	return apiserver.OwnerReference{}, false
+}
+
+func ksmOwner(labels prometheus.Labels, kindLabel, nameLabel string) (apiserver.OwnerReference, bool) {
+	kind, name := labels[kindLabel], labels[nameLabel]
+	if kind == "" || kind == ksmNone || name == "" || name == ksmNone {
+		return apiserver.OwnerReference{}, false
+	}
+	return apiserver.OwnerReference{Kind: kind, Name: name}, true
+}
+
+// ownerOf returns the controller of the given object, which has an empty
+// kind if it has none, and whether it could be resolved. Only the owners of
+// ReplicaSets and Jobs are resolved, the rest of the objects are considered
+// workloads.
+func (r *Resolver) ownerOf(groups definition.RawGroups, ns string, object apiserver.OwnerReference) (apiserver.OwnerReference, bool) {
+	if object.Kind != "ReplicaSet" && object.Kind != "Job" {
+		return apiserver.OwnerReference{}, true
+	}
+
+	if object.Kind == "ReplicaSet" {
+		rsID := fmt.Sprintf("%s_%s", ns, object.Name)
+		if m, ok := groups["replicaset"][rsID]["kube_replicaset_owner"].(prometheus.Metric); ok {
+			owner, _ := ksmOwner(m.Labels, "owner_kind", "owner_name")
+			return owner, true
+		}
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", object.Kind, ns, object.Name)
+	owner, ok := r.owners[key]
+	if !ok {
+		var err error
+		owner, err = r.apiServer.GetOwnerReference(object.Kind, ns, object.Name)
+		if err != nil {
+			r.logger.Debugf("Could not resolve the owner of %s: %s", key, err)
+		}
+		// Failed lookups are cached too, so they are not retried for every pod.
+		r.owners[key] = owner
+	}
+	if owner == nil {
+		return apiserver.OwnerReference{}, false
+	}
+	return *owner, true
+}
+
+// podID returns the raw entity ID of the pod of a container, from the
+// namespace and podName raw metrics of the kubelet groups, or from the labels
+// of the Prometheus metrics of the KSM groups.
+func podID(metrics definition.RawMetrics) string {
+	ns, _ := metrics["namespace"].(string)
+	pod, _ := metrics["podName"].(string)
+	if ns != "" && pod != "" {
+		return fmt.Sprintf("%s_%s", ns, pod)
+	}
+
+	for _, value := range metrics {
+		if m, ok := value.(prometheus.Metric); ok && m.Labels["namespace"] != "" && m.Labels["pod"] != "" {
+			return fmt.Sprintf("%s_%s", m.Labels["namespace"], m.Labels["pod"])
+		}
+	}
+	return ""
+}
+
+// replicasetNameToDeploymentName guesses the name of the Deployment owning a
+// ReplicaSet by removing the pod template hash suffix from its name.
+func replicasetNameToDeploymentName(rsName string) string {
+	s := strings.Split(rsName, "-")
+	return strings.Join(s[:len(s)-1], "-")
+}
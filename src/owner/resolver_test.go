@@ -0,0 +1,119 @@
+package owner
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/nri-kubernetes/src/apiserver"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/prometheus"
+)
+
+func TestResolver_ResolveGroupsKubelet(t *testing.T) {
+	apiServer := apiserver.TestAPIServer{Owners: map[string]*apiserver.OwnerReference{
+		"ReplicaSet/default/nginx-5c689d88bb": {Kind: "Deployment", Name: "nginx"},
+		"ReplicaSet/default/canary-7d4b9c8f6": {Kind: "Rollout", Name: "canary"},
+		"Job/default/backup-1600000000":       {Kind: "CronJob", Name: "backup"},
+		"Job/default/migrate":                 {},
+	}}
+
+	pod := func(name, kind, createdBy string) definition.RawMetrics {
+		return definition.RawMetrics{
+			"namespace":      "default",
+			"podName":        name,
+			"createdKind":    kind,
+			"createdBy":      createdBy,
+			"deploymentName": "guessed",
+		}
+	}
+	groups := definition.RawGroups{
+		"pod": {
+			"default_nginx-5c689d88bb-x2tqp":  pod("nginx-5c689d88bb-x2tqp", "ReplicaSet", "nginx-5c689d88bb"),
+			"default_canary-7d4b9c8f6-8mfzc":  pod("canary-7d4b9c8f6-8mfzc", "ReplicaSet", "canary-7d4b9c8f6"),
+			"default_redis-6b8f9d5c7d-l9t2v":  pod("redis-6b8f9d5c7d-l9t2v", "ReplicaSet", "redis-6b8f9d5c7d"),
+			"default_backup-1600000000-4h7xk": pod("backup-1600000000-4h7xk", "Job", "backup-1600000000"),
+			"default_migrate-q8w2n":           pod("migrate-q8w2n", "Job", "migrate"),
+			"default_workflow-step-1":         pod("workflow-step-1", "Workflow", "workflow"),
+			"default_static":                  {"namespace": "default", "podName": "static"},
+		},
+		"container": {
+			"default_nginx-5c689d88bb-x2tqp_nginx": {
+				"namespace":      "default",
+				"podName":        "nginx-5c689d88bb-x2tqp",
+				"containerName":  "nginx",
+				"deploymentName": "guessed",
+			},
+		},
+		"node": {
+			"worker-1": {"nodeName": "worker-1"},
+		},
+	}
+
+	resolved := NewResolver(apiServer, logrus.New()).ResolveGroups(groups)
+
+	workloads := map[string][]interface{}{}
+	for id, metrics := range resolved["pod"] {
+		workloads[id] = []interface{}{metrics[WorkloadKindKey], metrics[WorkloadNameKey], metrics[DeploymentNameKey]}
+	}
+	assert.Equal(t, map[string][]interface{}{
+		"default_nginx-5c689d88bb-x2tqp":  {"Deployment", "nginx", "nginx"},
+		"default_canary-7d4b9c8f6-8mfzc":  {"Rollout", "canary", nil},
+		"default_redis-6b8f9d5c7d-l9t2v":  {"ReplicaSet", "redis-6b8f9d5c7d", "redis"},
+		"default_backup-1600000000-4h7xk": {"CronJob", "backup", nil},
+		"default_migrate-q8w2n":           {"Job", "migrate", nil},
+		"default_workflow-step-1":         {"Workflow", "workflow", nil},
+		"default_static":                  {nil, nil, nil},
+	}, workloads)
+
+	container := resolved["container"]["default_nginx-5c689d88bb-x2tqp_nginx"]
+	assert.Equal(t, "Deployment", container[WorkloadKindKey])
+	assert.Equal(t, "nginx", container[WorkloadNameKey])
+	assert.Equal(t, "nginx", container[DeploymentNameKey])
+	assert.Equal(t, groups["node"], resolved["node"])
+
+	// The given groups are left untouched.
+	assert.Equal(t, "guessed", groups["pod"]["default_canary-7d4b9c8f6-8mfzc"]["deploymentName"])
+	assert.NotContains(t, groups["container"]["default_nginx-5c689d88bb-x2tqp_nginx"], WorkloadKindKey)
+}
+
+func TestResolver_ResolveGroupsKSM(t *testing.T) {
+	groups := definition.RawGroups{
+		"pod": {
+			"default_canary-7d4b9c8f6-8mfzc": {
+				"kube_pod_info": prometheus.Metric{Labels: prometheus.Labels{
+					"namespace": "default", "pod": "canary-7d4b9c8f6-8mfzc",
+					"created_by_kind": "ReplicaSet", "created_by_name": "canary-7d4b9c8f6",
+				}},
+				"kube_pod_owner": prometheus.Metric{Labels: prometheus.Labels{
+					"namespace": "default", "pod": "canary-7d4b9c8f6-8mfzc",
+					"owner_kind": "ReplicaSet", "owner_name": "canary-7d4b9c8f6",
+				}},
+			},
+			"default_orphan": {
+				"kube_pod_owner": prometheus.Metric{Labels: prometheus.Labels{
+					"namespace": "default", "pod": "orphan",
+					"owner_kind": "<none>", "owner_name": "<none>",
+				}},
+			},
+		},
+		"replicaset": {
+			"default_canary-7d4b9c8f6": {
+				"kube_replicaset_owner": prometheus.Metric{Labels: prometheus.Labels{
+					"namespace": "default", "replicaset": "canary-7d4b9c8f6",
+					"owner_kind": "Rollout", "owner_name": "canary",
+				}},
+			},
+		},
+	}
+
+	// Nothing is looked up in the API server, which knows no owner.
+	resolved := NewResolver(apiserver.TestAPIServer{}, logrus.New()).ResolveGroups(groups)
+
+	canary := resolved["pod"]["default_canary-7d4b9c8f6-8mfzc"]
+	assert.Equal(t, "Rollout", canary[WorkloadKindKey])
+	assert.Equal(t, "canary", canary[WorkloadNameKey])
+	assert.NotContains(t, canary, DeploymentNameKey)
+	assert.NotContains(t, resolved["pod"]["default_orphan"], WorkloadKindKey)
+}
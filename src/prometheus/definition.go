@@ -23,12 +23,37 @@ var ControlPlaneComponentTypeGenerator = func(
 	return fmt.Sprintf("k8s:%s:controlplane:%s", clusterName, groupLabel), nil
 }
 
+// ControlPlaneComponentTypeGeneratorFor generates the entity type of the
+// given control plane component, regardless of the group label. It is used
+// by groups holding additional metric sets of a component.
+func ControlPlaneComponentTypeGeneratorFor(component string) definition.EntityTypeGeneratorFunc {
+	return func(_ string, _ string, _ definition.RawGroups, clusterName string) (string, error) {
+		return fmt.Sprintf("k8s:%s:controlplane:%s", clusterName, component), nil
+	}
+}
+
 // FromRawEntityIDGenerator generates the entity type of a
 // control plane component.
 var FromRawEntityIDGenerator = func(_, rawEntityID string, _ definition.RawGroups) (string, error) {
 	return rawEntityID, nil
 }
 
+// FromParentEntityIDGenerator generates the entity ID of the raw entities
+// created by GroupEntityMetricsByLabel, which is the ID of the entity their
+// metrics were split from.
+var FromParentEntityIDGenerator = func(groupLabel, rawEntityID string, g definition.RawGroups) (string, error) {
+	v, err := definition.FromRaw(ParentEntityIDKey)(groupLabel, rawEntityID, g)
+	if err != nil {
+		return "", err
+	}
+
+	id, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("incompatible type for %s. Expected: string. Got: %T", ParentEntityIDKey, v)
+	}
+	return id, nil
+}
+
 // FromLabelValueEntityTypeGenerator generates the entity type using the cluster name and group label.
 // If group label is different than "namespace" or "node", then entity type is also composed of namespace.
 // If group label is "container" then pod name is also included.
@@ -193,6 +218,56 @@ func GroupEntityMetricsBySpec(
 	return g, errs
 }
 
+// ParentEntityIDKey is the raw metric holding the ID of the entity the
+// metrics were split from by GroupEntityMetricsByLabel.
+const ParentEntityIDKey = "parentEntityID"
+
+// GroupEntityMetricsByLabel groups the metrics coming from Prometheus for the
+// given rawEntityID into one raw entity per value of the given label.
+//
+// It is useful for metrics reported by the same entity for several
+// subsystems, like the workqueues of the controller manager, which are
+// labelled with the name of the controller. Time-series without the label
+// are ignored. Every raw entity holds the ID of the entity the metrics were
+// split from under ParentEntityIDKey, and the label value under the label
+// name.
+//
+// The resulting raw entities are of the form:
+// {
+//   rawEntityID_labelValue: {
+//     parentEntityID: rawEntityID,
+//     label: labelValue,
+//     metric_name: [ Metric1, Metric2, ..., Metricn ]
+//   }
+// }
+func GroupEntityMetricsByLabel(families []MetricFamily, rawEntityID, label string) map[string]definition.RawMetrics {
+	entities := make(map[string]definition.RawMetrics)
+	for _, f := range families {
+		for _, m := range f.Metrics {
+			value, ok := m.Labels[label]
+			if !ok {
+				continue
+			}
+
+			id := fmt.Sprintf("%s_%s", rawEntityID, value)
+			if _, ok := entities[id]; !ok {
+				entities[id] = definition.RawMetrics{
+					ParentEntityIDKey: rawEntityID,
+					label:             value,
+				}
+			}
+
+			groupedMetrics, ok := entities[id][f.Name]
+			if !ok {
+				groupedMetrics = make([]Metric, 0)
+			}
+			entities[id][f.Name] = append(groupedMetrics.([]Metric), m)
+		}
+	}
+
+	return entities
+}
+
 // GroupMetricsBySpec groups metrics coming from Prometheus by a given metric spec.
 // Example: grouping by K8s pod, container, etc.
 func GroupMetricsBySpec(specs definition.SpecGroups, families []MetricFamily) (g definition.RawGroups, errs []error) {
@@ -283,15 +358,25 @@ func IncludeOnlyLabelsFilter(labelsToInclude ...string) func(Labels) Labels {
 // attributeName genereates the attribute name by suffixing the time-series
 // labels to the given metricName in order.
 func attributeName(metricName, nameOverride string, labels Labels, labelsFilter ...LabelsFilter) string {
-	for _, filter := range labelsFilter {
-		labels = filter(labels)
-	}
+	return suffixLabelsInOrder(overriddenName(metricName, nameOverride), filterLabels(labels, labelsFilter...))
+}
 
+// overriddenName returns the nameOverride, if any, or the metricName.
+func overriddenName(metricName, nameOverride string) string {
 	if nameOverride != "" {
-		return suffixLabelsInOrder(nameOverride, labels)
+		return nameOverride
 	}
 
-	return suffixLabelsInOrder(metricName, labels)
+	return metricName
+}
+
+// filterLabels applies the labelsFilter to the labels in order.
+func filterLabels(labels Labels, labelsFilter ...LabelsFilter) Labels {
+	for _, filter := range labelsFilter {
+		labels = filter(labels)
+	}
+
+	return labels
 }
 
 // fetchedValuesFromRawMetrics generates a mapping of metrics to `FetchedValue`.
@@ -337,20 +422,25 @@ func fetchedValuesFromRawMetrics(
 	nameOverride string,
 	metrics []Metric,
 	labelsFilter ...LabelsFilter,
-) (definition.FetchedValues, error) {
-	val := make(definition.FetchedValues)
+) (definition.FetchedDimensionalValues, error) {
+	val := make(definition.FetchedDimensionalValues)
 	for _, metric := range metrics {
-		attrName := attributeName(metricName, nameOverride, metric.Labels, labelsFilter...)
-		aggregatedValue, ok := val[attrName]
+		labels := filterLabels(metric.Labels, labelsFilter...)
+		attrName := suffixLabelsInOrder(overriddenName(metricName, nameOverride), labels)
+		aggregated, ok := val[attrName]
 
 		if !ok {
-			val[attrName] = metric.Value
+			val[attrName] = definition.DimensionalValue{
+				Name:       overriddenName(metricName, nameOverride),
+				Dimensions: labels,
+				Value:      metric.Value,
+			}
 			continue
 		}
 
 		switch metric.Value.(type) {
 		case CounterValue:
-			aggregatedCounter, ok := aggregatedValue.(CounterValue)
+			aggregatedCounter, ok := aggregated.Value.(CounterValue)
 			if !ok {
 				return nil, fmt.Errorf(
 					"incompatible metric type for %s aggregation. Expected: CounterValue. Got: %T",
@@ -358,9 +448,9 @@ func fetchedValuesFromRawMetrics(
 					metric.Value,
 				)
 			}
-			val[attrName] = aggregatedCounter + metric.Value.(CounterValue)
+			aggregated.Value = aggregatedCounter + metric.Value.(CounterValue)
 		case GaugeValue:
-			aggregatedCounter, ok := aggregatedValue.(GaugeValue)
+			aggregatedCounter, ok := aggregated.Value.(GaugeValue)
 			if !ok {
 				return nil, fmt.Errorf(
 					"incompatible metric type for %s aggregation. Expected: GaugeValue. Got: %T",
@@ -368,8 +458,9 @@ func fetchedValuesFromRawMetrics(
 					metric.Value,
 				)
 			}
-			val[attrName] = aggregatedCounter + metric.Value.(GaugeValue)
+			aggregated.Value = aggregatedCounter + metric.Value.(GaugeValue)
 		}
+		val[attrName] = aggregated
 	}
 	return val, nil
 }
@@ -473,7 +564,7 @@ func FromSummary(key string) definition.FetchFunc {
 			)
 		}
 
-		val := make(definition.FetchedValues)
+		val := make(definition.FetchedDimensionalValues)
 		for _, metric := range metrics {
 			summary, ok := metric.Value.(*model.Summary)
 			if !ok {
@@ -484,22 +575,98 @@ func FromSummary(key string) definition.FetchFunc {
 				)
 			}
 			name := suffixLabelsInOrder(key, metric.Labels)
-			val[fmt.Sprintf("%s_count", name)] = summary.GetSampleCount()
+			setDistributionValue(val, key, name, "count", metric.Labels, summary.GetSampleCount())
 
 			sumVal := summary.GetSampleSum()
 			if validNRValue(sumVal) {
-				val[fmt.Sprintf("%s_sum", name)] = sumVal
+				setDistributionValue(val, key, name, "sum", metric.Labels, sumVal)
 			}
 
 			for _, q := range summary.GetQuantile() {
 				quantileVal := q.GetValue()
 				if validNRValue(quantileVal) {
-					nameWithQuantileSuffix := fmt.Sprintf(
-						"%s_quantile_%s",
-						name,
-						strconv.FormatFloat(q.GetQuantile(), 'f', -1, 64),
-					)
-					val[nameWithQuantileSuffix] = quantileVal
+					suffix := fmt.Sprintf("quantile_%s", strconv.FormatFloat(q.GetQuantile(), 'f', -1, 64))
+					setDistributionValue(val, key, name, suffix, metric.Labels, quantileVal)
+				}
+			}
+		}
+		return val, nil
+	}
+}
+
+// histogramQuantiles are the quantiles estimated by FromHistogram.
+var histogramQuantiles = []float64{0.5, 0.95, 0.99}
+
+// FromHistogram creates a FetchFunc that fetches values from prometheus
+// histograms.
+//
+// It will create one attribute for the count, one for the sum and one per
+// estimated quantile (0.5, 0.95 and 0.99), following the same naming as
+// FromSummary, so histogram and summary based versions of a metric can be
+// used interchangeably. If nameOverride is not empty, it is used as the
+// prefix instead of the key.
+//
+// The labels used in the attribute names can be filtered by using
+// `LabelsFilter`, which keeps the number of attributes reasonable for
+// high-cardinality histograms. Time-series that end up with the same labels
+// are merged by adding their buckets, count and sum.
+//
+// Since it expects the RawValue to be of type []Metric it should be
+// used when grouping with GroupEntityMetricsBySpec.
+func FromHistogram(key, nameOverride string, labelsFilter ...LabelsFilter) definition.FetchFunc {
+	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		value, err := definition.FromRaw(key)(groupLabel, entityID, groups)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics, ok := value.([]Metric)
+		if !ok {
+			return nil, fmt.Errorf(
+				"incompatible metric type for %s. Expected: []Metric. Got: %T",
+				key,
+				value,
+			)
+		}
+
+		merged := make(map[string]*mergedHistogram)
+		var names []string
+		for _, metric := range metrics {
+			histogram, ok := metric.Value.(*model.Histogram)
+			if !ok {
+				return nil, fmt.Errorf(
+					"incompatible metric type for %s. Expected: Histogram. Got: %T",
+					key,
+					metric.Value,
+				)
+			}
+
+			labels := filterLabels(metric.Labels, labelsFilter...)
+			name := suffixLabelsInOrder(overriddenName(key, nameOverride), labels)
+			h, ok := merged[name]
+			if !ok {
+				h = &mergedHistogram{labels: labels, buckets: make(map[float64]uint64)}
+				merged[name] = h
+				names = append(names, name)
+			}
+			h.add(histogram)
+		}
+
+		baseName := overriddenName(key, nameOverride)
+		val := make(definition.FetchedDimensionalValues)
+		for _, name := range names {
+			h := merged[name]
+			setDistributionValue(val, baseName, name, "count", h.labels, h.count)
+
+			if validNRValue(h.sum) {
+				setDistributionValue(val, baseName, name, "sum", h.labels, h.sum)
+			}
+
+			for _, q := range histogramQuantiles {
+				quantileVal := h.quantile(q)
+				if validNRValue(quantileVal) {
+					suffix := fmt.Sprintf("quantile_%s", strconv.FormatFloat(q, 'f', -1, 64))
+					setDistributionValue(val, baseName, name, suffix, h.labels, quantileVal)
 				}
 			}
 		}
@@ -507,6 +674,76 @@ func FromSummary(key string) definition.FetchFunc {
 	}
 }
 
+// setDistributionValue sets one of the values describing a summary or
+// histogram, like the count or a quantile, identified by the suffix.
+func setDistributionValue(
+	val definition.FetchedDimensionalValues,
+	baseName string,
+	name string,
+	suffix string,
+	labels Labels,
+	value definition.FetchedValue,
+) {
+	val[fmt.Sprintf("%s_%s", name, suffix)] = definition.DimensionalValue{
+		Name:       fmt.Sprintf("%s_%s", baseName, suffix),
+		Dimensions: labels,
+		Value:      value,
+	}
+}
+
+// mergedHistogram is the addition of one or more prometheus histograms.
+type mergedHistogram struct {
+	labels  Labels
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+func (h *mergedHistogram) add(histogram *model.Histogram) {
+	h.count += histogram.GetSampleCount()
+	h.sum += histogram.GetSampleSum()
+	for _, b := range histogram.GetBucket() {
+		h.buckets[b.GetUpperBound()] += b.GetCumulativeCount()
+	}
+}
+
+// quantile estimates the given quantile by linear interpolation between the
+// bounds of the bucket the quantile falls into, the same way Prometheus'
+// histogram_quantile does. It returns NaN when there are no observations.
+func (h *mergedHistogram) quantile(q float64) float64 {
+	if h.count == 0 {
+		return math.NaN()
+	}
+
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	rank := q * float64(h.count)
+	var lowerBound float64
+	var lowerCount uint64
+	for _, bound := range bounds {
+		count := h.buckets[bound]
+		if float64(count) >= rank {
+			if math.IsInf(bound, 1) {
+				// The quantile falls in the +Inf bucket, the best estimation is the highest finite bound.
+				return lowerBound
+			}
+			if count == lowerCount {
+				return bound
+			}
+			return lowerBound + (bound-lowerBound)*(rank-float64(lowerCount))/float64(count-lowerCount)
+		}
+		lowerBound = bound
+		lowerCount = count
+	}
+
+	// The +Inf bucket is implicit when not exposed.
+	return lowerBound
+}
+
 // validNRValue returns if v is a New Relic metric supported float64.
 func validNRValue(v float64) bool {
 	return !math.IsInf(v, 0) && !math.IsNaN(v)
@@ -660,6 +897,33 @@ func InheritAllSelectorsFrom(parentGroupLabel, relatedMetricKey string) definiti
 	}
 }
 
+// InheritAllAnnotationsFrom gets the annotations from a related metric, like
+// kube_deployment_annotations, and changes their prefix "annotation_" for
+// "annotation.". Unlike InheritAllLabelsFrom, the rest of the labels of the
+// metric are not inherited.
+func InheritAllAnnotationsFrom(parentGroupLabel, relatedMetricKey string) definition.FetchFunc {
+	return func(groupLabel, entityID string, groups definition.RawGroups) (definition.FetchedValue, error) {
+		rawEntityID, err := getRawEntityID(parentGroupLabel, groupLabel, entityID, groups)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve the entity ID of metrics to inherit annotations from, got error: %v", err)
+		}
+
+		parent, err := fetchMetric(relatedMetricKey)(parentGroupLabel, rawEntityID, groups)
+		if err != nil {
+			return nil, fmt.Errorf("related metric not found. Metric: %s %s:%s", relatedMetricKey, parentGroupLabel, rawEntityID)
+		}
+
+		multiple := make(definition.FetchedValues)
+		for k, v := range parent.(Metric).Labels {
+			if strings.HasPrefix(k, "annotation_") {
+				multiple["annotation."+strings.TrimPrefix(k, "annotation_")] = v
+			}
+		}
+
+		return multiple, nil
+	}
+}
+
 func getRawEntityID(parentGroupLabel, groupLabel, entityID string, groups definition.RawGroups) (string, error) {
 	group, ok := groups[groupLabel][entityID]
 	if !ok {
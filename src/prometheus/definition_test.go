@@ -520,7 +520,7 @@ func TestFetchFuncs_CorrectValue(t *testing.T) {
 	testCases := []struct {
 		name                 string
 		rawGroups            definition.RawGroups
-		expectedFetchedValue definition.FetchedValues
+		expectedFetchedValue definition.FetchedValue
 		fetchFunc            definition.FetchFunc
 	}{
 		{
@@ -680,6 +680,146 @@ func TestFetchFuncs_CorrectValue(t *testing.T) {
 				"http_request_duration_microseconds_handler_other_l1_v1_l2_v2_sum":                float64(45),
 			},
 		},
+		{
+			name: "FromHistogram correct value with filtered labels merged",
+			rawGroups: definition.RawGroups{
+				"scheduler": {
+					"kube-scheduler-minikube": {
+						"apiserver_request_duration_seconds": []Metric{
+							{
+								Labels: Labels{"verb": "GET", "resource": "pods", "scope": "namespace"},
+								Value: &model.Histogram{
+									SampleCount: uint64Ptr(10),
+									SampleSum:   float64Ptr(3),
+									Bucket: []*model.Bucket{
+										{UpperBound: float64Ptr(0.25), CumulativeCount: uint64Ptr(4)},
+										{UpperBound: float64Ptr(0.5), CumulativeCount: uint64Ptr(8)},
+										{UpperBound: float64Ptr(1), CumulativeCount: uint64Ptr(10)},
+										{UpperBound: float64Ptr(math.Inf(1)), CumulativeCount: uint64Ptr(10)},
+									},
+								},
+							},
+							{
+								Labels: Labels{"verb": "GET", "resource": "pods", "scope": "cluster"},
+								Value: &model.Histogram{
+									SampleCount: uint64Ptr(10),
+									SampleSum:   float64Ptr(5),
+									Bucket: []*model.Bucket{
+										{UpperBound: float64Ptr(0.25), CumulativeCount: uint64Ptr(0)},
+										{UpperBound: float64Ptr(0.5), CumulativeCount: uint64Ptr(4)},
+										{UpperBound: float64Ptr(1), CumulativeCount: uint64Ptr(9)},
+										{UpperBound: float64Ptr(math.Inf(1)), CumulativeCount: uint64Ptr(10)},
+									},
+								},
+							},
+							{
+								Labels: Labels{"verb": "LIST", "resource": "pods"},
+								Value: &model.Histogram{
+									SampleCount: uint64Ptr(0),
+									SampleSum:   float64Ptr(0),
+									Bucket: []*model.Bucket{
+										{UpperBound: float64Ptr(0.25), CumulativeCount: uint64Ptr(0)},
+										{UpperBound: float64Ptr(math.Inf(1)), CumulativeCount: uint64Ptr(0)},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			fetchFunc: FromHistogram(
+				"apiserver_request_duration_seconds",
+				"apiserverRequestDurationSeconds",
+				IncludeOnlyLabelsFilter("verb", "resource"),
+			),
+			expectedFetchedValue: definition.FetchedValues{
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_count":         uint64(20),
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_sum":           float64(8),
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_quantile_0.5":  0.4375,
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_quantile_0.95": float64(1),
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_quantile_0.99": float64(1),
+				"apiserverRequestDurationSeconds_resource_pods_verb_LIST_count":        uint64(0),
+				"apiserverRequestDurationSeconds_resource_pods_verb_LIST_sum":          float64(0),
+			},
+		},
+		{
+			name: "FromValueOverriddenName sets the dimensions",
+			rawGroups: definition.RawGroups{
+				"scheduler": {
+					"kube-scheduler-minikube": {
+						"apiserver_request_total": []Metric{
+							{Labels: Labels{"verb": "GET", "code": "200", "resource": "pods"}, Value: CounterValue(1)},
+							{Labels: Labels{"verb": "GET", "code": "200", "resource": "nodes"}, Value: CounterValue(2)},
+						},
+					},
+				},
+			},
+			fetchFunc: FromValueWithOverriddenName(
+				"apiserver_request_total",
+				"apiserverRequestsDelta",
+				IncludeOnlyLabelsFilter("verb", "code"),
+			),
+			expectedFetchedValue: definition.FetchedDimensionalValues{
+				"apiserverRequestsDelta_code_200_verb_GET": {
+					Name:       "apiserverRequestsDelta",
+					Dimensions: map[string]string{"verb": "GET", "code": "200"},
+					Value:      CounterValue(3),
+				},
+			},
+		},
+		{
+			name: "FromHistogram sets the dimensions",
+			rawGroups: definition.RawGroups{
+				"scheduler": {
+					"kube-scheduler-minikube": {
+						"apiserver_request_duration_seconds": []Metric{
+							{
+								Labels: Labels{"verb": "GET", "resource": "pods"},
+								Value: &model.Histogram{
+									SampleCount: uint64Ptr(1),
+									SampleSum:   float64Ptr(0.5),
+									Bucket: []*model.Bucket{
+										{UpperBound: float64Ptr(1), CumulativeCount: uint64Ptr(1)},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			fetchFunc: FromHistogram(
+				"apiserver_request_duration_seconds",
+				"apiserverRequestDurationSeconds",
+				IncludeOnlyLabelsFilter("verb", "resource"),
+			),
+			expectedFetchedValue: definition.FetchedDimensionalValues{
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_count": {
+					Name:       "apiserverRequestDurationSeconds_count",
+					Dimensions: map[string]string{"verb": "GET", "resource": "pods"},
+					Value:      uint64(1),
+				},
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_sum": {
+					Name:       "apiserverRequestDurationSeconds_sum",
+					Dimensions: map[string]string{"verb": "GET", "resource": "pods"},
+					Value:      float64(0.5),
+				},
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_quantile_0.5": {
+					Name:       "apiserverRequestDurationSeconds_quantile_0.5",
+					Dimensions: map[string]string{"verb": "GET", "resource": "pods"},
+					Value:      float64(0.5),
+				},
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_quantile_0.95": {
+					Name:       "apiserverRequestDurationSeconds_quantile_0.95",
+					Dimensions: map[string]string{"verb": "GET", "resource": "pods"},
+					Value:      float64(0.95),
+				},
+				"apiserverRequestDurationSeconds_resource_pods_verb_GET_quantile_0.99": {
+					Name:       "apiserverRequestDurationSeconds_quantile_0.99",
+					Dimensions: map[string]string{"verb": "GET", "resource": "pods"},
+					Value:      float64(0.99),
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -689,6 +829,12 @@ func TestFetchFuncs_CorrectValue(t *testing.T) {
 				"kube-scheduler-minikube",
 				testCase.rawGroups,
 			)
+			// The cases expecting FetchedValues check the values as populated
+			// in the entity metric sets, with the dimensions in their names.
+			if _, ok := testCase.expectedFetchedValue.(definition.FetchedValues); ok {
+				require.IsType(t, definition.FetchedDimensionalValues{}, fetchedValue)
+				fetchedValue = fetchedValue.(definition.FetchedDimensionalValues).FetchedValues()
+			}
 			assert.Equal(t, testCase.expectedFetchedValue, fetchedValue)
 			assert.NoError(t, err)
 		})
@@ -798,6 +944,25 @@ func TestFetchFunc_IncompatibleType(t *testing.T) {
 			actualType:   "prometheus.GaugeValue",
 			key:          "http_request_duration_microseconds",
 		},
+		{
+			name: "FromHistogramNoHistogram",
+			rawGroups: definition.RawGroups{
+				"scheduler": {
+					"kube-scheduler-minikube": {
+						"http_request_duration_microseconds": []Metric{
+							{
+								Labels: Labels{"handler": "prometheus"},
+								Value:  &model.Summary{},
+							},
+						},
+					},
+				},
+			},
+			fetchFunc:    FromHistogram("http_request_duration_microseconds", ""),
+			expectedType: "Histogram",
+			actualType:   "*io_prometheus_client.Summary",
+			key:          "http_request_duration_microseconds",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -1349,6 +1514,31 @@ func TestInheritAllLabelsFrom_FromTheSameLabelGroup(t *testing.T) {
 	expectedValue := definition.FetchedValues{"label.deployment": "newrelic-infra-monitoring", "label.namespace": "kube-public", "label.app": "newrelic-infra-monitoring"}
 	assert.Equal(t, expectedValue, fetchedValue)
 }
+
+func TestInheritAllAnnotationsFrom(t *testing.T) {
+	deploymentRawEntityID := "kube-public_newrelic-infra-monitoring"
+	raw := definition.RawGroups{
+		"deployment": {
+			deploymentRawEntityID: definition.RawMetrics{
+				"kube_deployment_annotations": Metric{
+					Value: GaugeValue(1),
+					Labels: map[string]string{
+						"deployment":                  "newrelic-infra-monitoring",
+						"namespace":                   "kube-public",
+						"annotation_example_com_team": "platform",
+					},
+				},
+			},
+		},
+	}
+
+	fetchedValue, err := InheritAllAnnotationsFrom("deployment", "kube_deployment_annotations")("deployment", deploymentRawEntityID, raw)
+	assert.NoError(t, err)
+
+	expectedValue := definition.FetchedValues{"annotation.example_com_team": "platform"}
+	assert.Equal(t, expectedValue, fetchedValue)
+}
+
 func TestInheritAllLabelsFrom_LabelNotFound(t *testing.T) {
 	podRawEntityID := "kube-system_kube-addon-manager-minikube"
 	raw := definition.RawGroups{
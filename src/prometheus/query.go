@@ -114,8 +114,7 @@ func valueFromPrometheus(metricType model.MetricType, metric *model.Metric) Valu
 	case model.MetricType_GAUGE:
 		return GaugeValue(metric.Gauge.GetValue())
 	case model.MetricType_HISTOGRAM:
-		// Not supported yet
-		fallthrough
+		return metric.Histogram
 	case model.MetricType_SUMMARY:
 		return metric.Summary
 	case model.MetricType_UNTYPED:
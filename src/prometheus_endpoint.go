@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/export"
+	"github.com/newrelic/nri-kubernetes/src/metric"
+	"github.com/sirupsen/logrus"
+)
+
+// servePrometheus runs the integration as a long-lived process, which
+// populates the metrics every PrometheusInterval and serves the last ones in
+// the Prometheus format at /metrics, instead of publishing them.
+func servePrometheus(integration *sdk.IntegrationProtocol2, logger *logrus.Logger, nodeName string) {
+	interval, err := time.ParseDuration(args.PrometheusInterval)
+	if err != nil || interval <= 0 {
+		logger.WithError(err).Errorf("while parsing the Prometheus interval value. Defaulting to %s", defaultPrometheusInterval)
+		interval = defaultPrometheusInterval
+	}
+
+	handler := export.NewPrometheusHandler(
+		logger,
+		[]definition.GuessFunc{metric.K8sMetricSetTypeGuesser, metric.K8sDimensionalMetricSetTypeGuesser},
+		metric.KSMSpecs,
+		metric.KubeletSpecs,
+		metric.KubeletRollupSpecs,
+		metric.ClusterCapacitySpecs,
+		metric.APIServerSpecs,
+		metric.ControllerManagerSpecs,
+		metric.SchedulerSpecs,
+		metric.EtcdSpecs,
+	)
+	update := func() {
+		defer integration.Clear()
+		// Errors which would end a single run of the integration are logged,
+		// and the previous metrics are served until the next population.
+		defer func() {
+			if r := recover(); r != nil {
+				recErr, ok := r.(*logrus.Entry)
+				if !ok {
+					panic(r)
+				}
+				logger.Errorf("populating the metrics: %s", recErr.Message)
+			}
+		}()
+
+		if populate(integration, logger, nodeName) == 0 {
+			logger.Error("No data was populated")
+			return
+		}
+		handler.Update(integration.Data)
+		exportOTLP(integration, logger)
+		writeNDJSON(integration, logger)
+	}
+
+	update()
+	go func() {
+		for range time.Tick(interval) {
+			update()
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	logger.Infof("Serving the metrics in the Prometheus format at %s/metrics", args.PrometheusListenAddress)
+	logger.Panic(http.ListenAndServe(args.PrometheusListenAddress, mux))
+}
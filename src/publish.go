@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/newrelic/infra-integrations-sdk/cache"
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/export"
+	"github.com/sirupsen/logrus"
+)
+
+// publish publishes the populated metrics to the agent. When PublishMaxEntities
+// or PublishMaxSizeKB are set, the entities are published in several protocol
+// v2 payloads, one per line unless Pretty is set, instead of a single one. w
+// must be the writer of the integration.
+func publish(integration *sdk.IntegrationProtocol2, logger *logrus.Logger, w io.Writer) error {
+	if args.PublishMaxEntities <= 0 && args.PublishMaxSizeKB <= 0 {
+		return integration.Publish()
+	}
+
+	chunker := &export.Chunker{
+		MaxEntities: args.PublishMaxEntities,
+		MaxBytes:    args.PublishMaxSizeKB * 1024,
+	}
+	chunks, err := chunker.Split(integration)
+	if err != nil {
+		return err
+	}
+
+	// Publish would also store the values of the SDK DELTA and RATE metrics.
+	if err := cache.Save(); err != nil {
+		return err
+	}
+
+	for n, c := range chunks {
+		logger.Debugf("Publishing chunk %d/%d with %d entities and %d bytes", n+1, len(chunks), c.Entities, len(c.Payload))
+		if chunker.MaxBytes > 0 && len(c.Payload) > chunker.MaxBytes {
+			logger.Warnf("Chunk %d/%d has %d bytes, more than PUBLISH_MAX_SIZE_KB, as a single entity doesn't fit in it", n+1, len(chunks), len(c.Payload))
+		}
+		payload := c.Payload
+		if args.Pretty {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, c.Payload, "", "\t"); err != nil {
+				return err
+			}
+			payload = indented.Bytes()
+		}
+		if _, err := fmt.Fprintln(w, string(payload)); err != nil {
+			return err
+		}
+	}
+
+	integration.Clear()
+	return nil
+}
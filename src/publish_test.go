@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publishedIntegration(t *testing.T, w *bytes.Buffer) *sdk.IntegrationProtocol2 {
+	i, err := sdk.NewIntegrationProtocol2WithWriter("com.newrelic.kubernetes", "test", new(struct{}), w)
+	require.NoError(t, err)
+	for _, name := range []string{"pod-a", "pod-b", "pod-c"} {
+		_, err := i.Entity(name, "k8s:playground:pod")
+		require.NoError(t, err)
+	}
+	return i
+}
+
+func TestPublish_Chunks(t *testing.T) {
+	defer func(previous argumentList) { args = previous }(args)
+	args.PublishMaxEntities = 2
+
+	var w bytes.Buffer
+	require.NoError(t, publish(publishedIntegration(t, &w), logger, &w))
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
+
+func TestPublish_PrettyChunks(t *testing.T) {
+	defer func(previous argumentList) { args = previous }(args)
+	args.PublishMaxEntities = 2
+	args.Pretty = true
+
+	var w bytes.Buffer
+	require.NoError(t, publish(publishedIntegration(t, &w), logger, &w))
+
+	assert.Contains(t, w.String(), "\n\t\"name\": \"com.newrelic.kubernetes\"")
+	decoder := json.NewDecoder(&w)
+	chunks := 0
+	for decoder.More() {
+		var payload map[string]interface{}
+		require.NoError(t, decoder.Decode(&payload))
+		chunks++
+	}
+	assert.Equal(t, 2, chunks)
+}
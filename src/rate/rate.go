@@ -0,0 +1,124 @@
+package rate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	sdkMetric "github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const storageKey = "counters"
+
+var now = time.Now
+
+// counter holds the previous sample of a counter.
+// Its fields must be public to make them visible for the JSON Marshaller.
+type counter struct {
+	Value     float64
+	Time      time.Time
+	StartTime float64 `json:",omitempty"`
+}
+
+// Calculator is a definition.CounterSampler which keeps the previous values
+// of the counters in a storage.Storage, so the DELTA and RATE metrics can be
+// computed by short-lived processes, or reported anywhere other than the
+// agent. A counter decreasing, or the start time of the process exposing it
+// changing, is considered a reset, and the counter is assumed to start from
+// zero. Counters which are not sampled for longer than the TTL expire.
+type Calculator struct {
+	storage storage.Storage
+	ttl     time.Duration
+	logger  *logrus.Logger
+
+	lock     sync.Mutex
+	counters map[string]counter
+}
+
+// NewCalculator returns a Calculator storing the counters in the given
+// storage, which expire when they are not sampled for longer than ttl.
+func NewCalculator(storage storage.Storage, ttl time.Duration, logger *logrus.Logger) *Calculator {
+	return &Calculator{
+		storage: storage,
+		ttl:     ttl,
+		logger:  logger,
+	}
+}
+
+// Sample implements definition.CounterSampler. It returns
+// definition.ErrNoPreviousSample when the counter is sampled for the first
+// time, or its previous sample expired.
+func (c *Calculator) Sample(key string, value float64, sourceType sdkMetric.SourceType, startTime float64) (float64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.load()
+
+	current := counter{Value: value, Time: now(), StartTime: startTime}
+	previous, ok := c.counters[key]
+	c.counters[key] = current
+	if !ok || c.expired(previous, current.Time) {
+		return 0, definition.ErrNoPreviousSample
+	}
+
+	elapsed := current.Time.Sub(previous.Time).Seconds()
+	if elapsed <= 0 {
+		return 0, fmt.Errorf("samples for %s are too close in time, skipping sampling", key)
+	}
+
+	delta := value - previous.Value
+	if delta < 0 || restarted(previous, current) {
+		c.logger.Debugf("counter %s was reset, assuming it started from zero", key)
+		delta = value
+	}
+
+	if sourceType == sdkMetric.RATE {
+		return delta / elapsed, nil
+	}
+	return delta, nil
+}
+
+// Flush removes the expired counters and writes the rest to the storage, so
+// they are available for the next run.
+func (c *Calculator) Flush() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.load()
+
+	t := now()
+	for key, sample := range c.counters {
+		if c.expired(sample, t) {
+			delete(c.counters, key)
+		}
+	}
+
+	return c.storage.Write(storageKey, c.counters)
+}
+
+// load reads the counters stored by the previous run, the first time it is
+// called.
+func (c *Calculator) load() {
+	if c.counters != nil {
+		return
+	}
+
+	c.counters = make(map[string]counter)
+	if _, err := c.storage.Read(storageKey, &c.counters); err != nil {
+		c.logger.WithError(err).Debug("no previous counter values could be read")
+		c.counters = make(map[string]counter)
+	}
+}
+
+func (c *Calculator) expired(sample counter, t time.Time) bool {
+	return c.ttl > 0 && t.Sub(sample.Time) > c.ttl
+}
+
+// restarted returns whether the process exposing the counter restarted
+// between both samples, when the start time of the process is known.
+func restarted(previous, current counter) bool {
+	return previous.StartTime != 0 && current.StartTime != 0 && previous.StartTime != current.StartTime
+}
@@ -0,0 +1,135 @@
+package rate
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	sdkMetric "github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const key = "k8s:cluster:etcd:etcd-minikube_K8sEtcdSample_processCpuSecondsDelta"
+
+func newTestCalculator(t *testing.T, ttl time.Duration) (*Calculator, storage.Storage, func()) {
+	tmpDir, err := ioutil.TempDir("", "test_rate")
+	require.NoError(t, err)
+
+	store := storage.NewJSONDiskStorage(tmpDir)
+	return NewCalculator(store, ttl, logrus.New()), store, func() { _ = os.RemoveAll(tmpDir) }
+}
+
+// setNow sets the time returned by now, and restores it when the test ends.
+func setNow(t *testing.T, current *time.Time) {
+	previous := now
+	now = func() time.Time { return *current }
+	t.Cleanup(func() { now = previous })
+}
+
+func TestCalculator_Sample(t *testing.T) {
+	current := time.Unix(1600000000, 0)
+	setNow(t, &current)
+
+	c, _, cleanup := newTestCalculator(t, time.Hour)
+	defer cleanup()
+
+	_, err := c.Sample(key, 10, sdkMetric.DELTA, 0)
+	assert.Equal(t, definition.ErrNoPreviousSample, err)
+
+	current = current.Add(10 * time.Second)
+	delta, err := c.Sample(key, 30, sdkMetric.DELTA, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(20), delta)
+
+	current = current.Add(10 * time.Second)
+	rate, err := c.Sample(key, 80, sdkMetric.RATE, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(5), rate)
+
+	_, err = c.Sample(key, 90, sdkMetric.RATE, 0)
+	assert.Error(t, err, "samples with the same time")
+}
+
+func TestCalculator_Sample_CounterReset(t *testing.T) {
+	current := time.Unix(1600000000, 0)
+	setNow(t, &current)
+
+	c, _, cleanup := newTestCalculator(t, time.Hour)
+	defer cleanup()
+
+	_, _ = c.Sample(key, 100, sdkMetric.DELTA, 0)
+
+	// The counter is assumed to start from zero after decreasing.
+	current = current.Add(10 * time.Second)
+	delta, err := c.Sample(key, 15, sdkMetric.DELTA, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(15), delta)
+}
+
+func TestCalculator_Sample_ProcessRestart(t *testing.T) {
+	current := time.Unix(1600000000, 0)
+	setNow(t, &current)
+
+	c, _, cleanup := newTestCalculator(t, time.Hour)
+	defer cleanup()
+
+	_, _ = c.Sample(key, 100, sdkMetric.DELTA, 1500000000)
+
+	current = current.Add(10 * time.Second)
+	delta, err := c.Sample(key, 110, sdkMetric.DELTA, 1500000000)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), delta)
+
+	// The counter grew past its previous value after the process restarted.
+	current = current.Add(10 * time.Second)
+	delta, err = c.Sample(key, 120, sdkMetric.DELTA, 1600000015)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(120), delta)
+}
+
+func TestCalculator_Sample_Expired(t *testing.T) {
+	current := time.Unix(1600000000, 0)
+	setNow(t, &current)
+
+	c, _, cleanup := newTestCalculator(t, time.Minute)
+	defer cleanup()
+
+	_, _ = c.Sample(key, 100, sdkMetric.DELTA, 0)
+
+	current = current.Add(2 * time.Minute)
+	_, err := c.Sample(key, 110, sdkMetric.DELTA, 0)
+	assert.Equal(t, definition.ErrNoPreviousSample, err)
+}
+
+func TestCalculator_Flush(t *testing.T) {
+	current := time.Unix(1600000000, 0)
+	setNow(t, &current)
+
+	c, store, cleanup := newTestCalculator(t, time.Minute)
+	defer cleanup()
+
+	staleKey := "k8s:cluster:etcd:etcd-old_K8sEtcdSample_processCpuSecondsDelta"
+	_, _ = c.Sample(staleKey, 5, sdkMetric.DELTA, 0)
+
+	current = current.Add(2 * time.Minute)
+	_, _ = c.Sample(key, 100, sdkMetric.DELTA, 1500000000)
+	require.NoError(t, c.Flush())
+
+	var stored map[string]counter
+	_, err := store.Read(storageKey, &stored)
+	require.NoError(t, err)
+	assert.Contains(t, stored, key)
+	assert.NotContains(t, stored, staleKey)
+
+	// The next run of the integration reads the previous values.
+	next := NewCalculator(store, time.Minute, logrus.New())
+	current = current.Add(30 * time.Second)
+	rate, err := next.Sample(key, 115, sdkMetric.RATE, 1500000000)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, rate)
+}
@@ -0,0 +1,223 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/newrelic/infra-integrations-sdk/metric"
+)
+
+// Mask replaces the parts of the values matching the mask rules.
+const Mask = "[REDACTED]"
+
+// protected are the attributes identifying the metric sets, which are never
+// redacted.
+var protected = map[string]bool{
+	"event_type": true,
+	"entityName": true,
+}
+
+// Rules are the redaction rules, as loaded from the rules file:
+//
+//	# Attributes whose name matches any of these regexes are removed, whatever
+//	# the type of their value.
+//	drop: ['^label\.owner-email$']
+//	# String attributes whose name matches any of these regexes have their value
+//	# replaced by its SHA-256 hash.
+//	hash: ['^label\.customer-id$']
+//	# Parts of the string values matching any of these regexes are masked.
+//	mask: ['[[:alnum:]._%+-]+@[[:alnum:].-]+']
+//	# Rules replacing the ones above for the entities of some types, which
+//	# is the last part of their entity type, e.g. pod or container.
+//	entityTypes:
+//	  namespace:
+//	    mask: []
+type Rules struct {
+	Drop        []string         `json:"drop"`
+	Hash        []string         `json:"hash"`
+	Mask        []string         `json:"mask"`
+	EntityTypes map[string]Rules `json:"entityTypes"`
+}
+
+// LoadRules reads the rules from the given YAML file.
+func LoadRules(path string) (Rules, error) {
+	var rules Rules
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+
+	if err := yaml.Unmarshal(content, &rules); err != nil {
+		return rules, fmt.Errorf("invalid redaction rules in %s: %s", path, err)
+	}
+	return rules, nil
+}
+
+type compiledRules struct {
+	drop []*regexp.Regexp
+	hash []*regexp.Regexp
+	mask []*regexp.Regexp
+}
+
+// Counts are the number of redactions applied.
+type Counts struct {
+	Dropped int
+	Hashed  int
+	Masked  int
+}
+
+// Total returns the number of redactions applied.
+func (c Counts) Total() int {
+	return c.Dropped + c.Hashed + c.Masked
+}
+
+func (c Counts) String() string {
+	return fmt.Sprintf("%d attributes dropped, %d hashed and %d masked", c.Dropped, c.Hashed, c.Masked)
+}
+
+// Redactor removes or obfuscates the sensitive attributes of the metric sets,
+// like labels holding emails or customer identifiers.
+type Redactor struct {
+	defaults    compiledRules
+	entityTypes map[string]compiledRules
+	counts      Counts
+}
+
+// NewRedactor returns a Redactor applying the given rules. The rules of an
+// entity type replace the default ones which they set, and inherit the rest.
+func NewRedactor(rules Rules) (*Redactor, error) {
+	defaults, err := compileRules(rules, compiledRules{})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{
+		defaults:    defaults,
+		entityTypes: make(map[string]compiledRules, len(rules.EntityTypes)),
+	}
+	for entityType, overrides := range rules.EntityTypes {
+		if r.entityTypes[entityType], err = compileRules(overrides, defaults); err != nil {
+			return nil, fmt.Errorf("entity type %s: %s", entityType, err)
+		}
+	}
+	return r, nil
+}
+
+func compileRules(rules Rules, inherited compiledRules) (compiledRules, error) {
+	c := inherited
+	var err error
+	if rules.Drop != nil {
+		if c.drop, err = compileAll(rules.Drop); err != nil {
+			return c, err
+		}
+	}
+	if rules.Hash != nil {
+		if c.hash, err = compileAll(rules.Hash); err != nil {
+			return c, err
+		}
+	}
+	if rules.Mask != nil {
+		if c.mask, err = compileAll(rules.Mask); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction regex %q: %s", expr, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Redact applies the rules of the given entity type, e.g.
+// k8s:playground:default:pod, to the metric set. The drop rules apply to the
+// attributes of any type, the hash and mask rules to the string ones.
+func (r *Redactor) Redact(ms metric.MetricSet, entityType string) {
+	rules := r.rulesFor(entityType)
+	for name, value := range ms {
+		if protected[name] {
+			continue
+		}
+
+		if matchAny(rules.drop, name) {
+			delete(ms, name)
+			r.counts.Dropped++
+			continue
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if matchAny(rules.hash, name) {
+			sum := sha256.Sum256([]byte(s))
+			ms[name] = hex.EncodeToString(sum[:])
+			r.counts.Hashed++
+			continue
+		}
+
+		if masked := mask(rules, s); masked != s {
+			ms[name] = masked
+			r.counts.Masked++
+		}
+	}
+}
+
+// RedactString applies the mask rules of the given entity type to a string
+// which is not an attribute, like the summary of an event.
+func (r *Redactor) RedactString(s string, entityType string) string {
+	masked := mask(r.rulesFor(entityType), s)
+	if masked != s {
+		r.counts.Masked++
+	}
+	return masked
+}
+
+// rulesFor returns the rules of the given entity type, e.g.
+// k8s:playground:default:pod, which are the default ones unless overridden.
+func (r *Redactor) rulesFor(entityType string) compiledRules {
+	if i := strings.LastIndex(entityType, ":"); i >= 0 {
+		entityType = entityType[i+1:]
+	}
+	if overrides, ok := r.entityTypes[entityType]; ok {
+		return overrides
+	}
+	return r.defaults
+}
+
+func mask(rules compiledRules, s string) string {
+	for _, re := range rules.mask {
+		s = re.ReplaceAllLiteralString(s, Mask)
+	}
+	return s
+}
+
+func matchAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush returns the redactions applied since the last call.
+func (r *Redactor) Flush() Counts {
+	counts := r.counts
+	r.counts = Counts{}
+	return counts
+}
@@ -0,0 +1,148 @@
+package redact
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRules = Rules{
+	Drop: []string{`^label\.owner-email$`},
+	Hash: []string{`^label\.customer-id$`},
+	Mask: []string{`[[:alnum:]._%+-]+@[[:alnum:].-]+`},
+	EntityTypes: map[string]Rules{
+		"namespace": {Mask: []string{}},
+	},
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	r, err := NewRedactor(testRules)
+	require.NoError(t, err)
+
+	ms := metric.MetricSet{
+		"event_type":        "K8sPodSample",
+		"entityName":        "k8s:playground:default:pod:nginx",
+		"podName":           "nginx",
+		"label.owner-email": "jane@example.com",
+		"label.customer-id": "acme",
+		"label.contact":     "ask jane@example.com or ops@example.com",
+		"restartCount":      3,
+	}
+	r.Redact(ms, "k8s:playground:default:pod")
+
+	assert.Equal(t, metric.MetricSet{
+		"event_type":        "K8sPodSample",
+		"entityName":        "k8s:playground:default:pod:nginx",
+		"podName":           "nginx",
+		"label.customer-id": "822b33ad87c148a0a20a5ba7cd5ebcaa68d36a18e7aad165554903f52ca82757",
+		"label.contact":     "ask [REDACTED] or [REDACTED]",
+		"restartCount":      3,
+	}, ms)
+	assert.Equal(t, Counts{Dropped: 1, Hashed: 1, Masked: 1}, r.Flush())
+	assert.Equal(t, Counts{}, r.Flush())
+}
+
+func TestRedactor_RedactEntityTypeOverrides(t *testing.T) {
+	r, err := NewRedactor(testRules)
+	require.NoError(t, err)
+
+	ms := metric.MetricSet{
+		"event_type":        "K8sNamespaceSample",
+		"label.owner-email": "jane@example.com",
+		"label.contact":     "jane@example.com",
+	}
+	r.Redact(ms, "k8s:playground:namespace")
+
+	// The namespace rules disable masking and inherit the drop rules.
+	assert.Equal(t, metric.MetricSet{
+		"event_type":    "K8sNamespaceSample",
+		"label.contact": "jane@example.com",
+	}, ms)
+	assert.Equal(t, Counts{Dropped: 1}, r.Flush())
+}
+
+func TestRedactor_DropNonStringAttributes(t *testing.T) {
+	r, err := NewRedactor(Rules{Drop: []string{`^hostNetwork$`, `^cpuLimitCores$`}, Hash: []string{`^restartCount$`}})
+	require.NoError(t, err)
+
+	ms := metric.MetricSet{
+		"event_type":    "K8sPodSample",
+		"hostNetwork":   false,
+		"cpuLimitCores": 0.5,
+		"restartCount":  3,
+	}
+	r.Redact(ms, "k8s:playground:default:pod")
+
+	// Only the string values are hashed.
+	assert.Equal(t, metric.MetricSet{
+		"event_type":   "K8sPodSample",
+		"restartCount": 3,
+	}, ms)
+	assert.Equal(t, Counts{Dropped: 2}, r.Flush())
+}
+
+func TestRedactor_RedactString(t *testing.T) {
+	r, err := NewRedactor(testRules)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ask [REDACTED]", r.RedactString("ask jane@example.com", "k8s:playground:default:pod"))
+	assert.Equal(t, "ask jane@example.com", r.RedactString("ask jane@example.com", "k8s:playground:namespace"))
+	assert.Equal(t, Counts{Masked: 1}, r.Flush())
+}
+
+func TestRedactor_ProtectedAttributes(t *testing.T) {
+	r, err := NewRedactor(Rules{Drop: []string{".*"}, Mask: []string{"nginx"}})
+	require.NoError(t, err)
+
+	ms := metric.MetricSet{
+		"event_type": "K8sPodSample",
+		"entityName": "k8s:playground:default:pod:nginx",
+		"podName":    "nginx",
+	}
+	r.Redact(ms, "k8s:playground:default:pod")
+
+	assert.Equal(t, metric.MetricSet{
+		"event_type": "K8sPodSample",
+		"entityName": "k8s:playground:default:pod:nginx",
+	}, ms)
+}
+
+func TestNewRedactor_InvalidRegex(t *testing.T) {
+	_, err := NewRedactor(Rules{Drop: []string{"label.("}})
+	assert.Error(t, err)
+
+	_, err = NewRedactor(Rules{EntityTypes: map[string]Rules{"pod": {Mask: []string{"("}}}})
+	assert.Error(t, err)
+}
+
+func TestLoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "redact")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+drop: ['^label\.owner-email$']
+hash: ['^label\.customer-id$']
+mask: ['[[:alnum:]._%+-]+@[[:alnum:].-]+']
+entityTypes:
+  namespace:
+    mask: []
+`), 0644))
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	assert.Equal(t, testRules, rules)
+
+	_, err = LoadRules(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("drop: {"), 0644))
+	_, err = LoadRules(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package rollup
+
+import (
+	"fmt"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+	"github.com/newrelic/nri-kubernetes/src/owner"
+)
+
+// Group labels of the raw groups added by AddGroups, reported as
+// K8sDeploymentNodeUsageSample, K8sStatefulsetNodeUsageSample,
+// K8sDaemonsetNodeUsageSample and K8sNamespaceNodeUsageSample, as each
+// node reports the share of the workloads running on it.
+const (
+	DeploymentGroup  = "deployment-node-usage"
+	StatefulSetGroup = "statefulset-node-usage"
+	DaemonSetGroup   = "daemonset-node-usage"
+	NamespaceGroup   = "namespace-node-usage"
+)
+
+// workloadGroups are the group labels of the rollups of each kind of workload
+// besides deployments, which are taken from the deployment name.
+var workloadGroups = map[string]string{
+	"StatefulSet": StatefulSetGroup,
+	"DaemonSet":   DaemonSetGroup,
+}
+
+// entityTypes are the last part of the types of the entities the rollups are
+// reported for, which are the same as the ones of the KSM samples.
+var entityTypes = map[string]string{
+	DeploymentGroup:  "deployment",
+	StatefulSetGroup: "statefulset",
+	DaemonSetGroup:   "daemonset",
+	NamespaceGroup:   "namespace",
+}
+
+// Raw metrics summed from the kubelet container groups, in their units.
+var (
+	uintSums = []string{"usageNanoCores", "workingSetBytes"}
+	intSums  = []string{"cpuRequestedCores", "cpuLimitCores", "memoryRequestedBytes", "memoryLimitBytes"}
+)
+
+// AddGroups returns the given kubelet groups with the resource usage,
+// requests and limits of their regular containers summed per deployment,
+// statefulset, daemonset and namespace, in the raw groups of DeploymentGroup,
+// StatefulSetGroup, DaemonSetGroup and NamespaceGroup. The workloads of the
+// containers are the ones resolved by an owner.Resolver, or the deployment
+// guessed from the name of their ReplicaSet.
+//
+// The kubelet groups only have the containers of a node, so the rollups are
+// the share of the workloads running on it, identified by nodeName. Their
+// cluster-wide value is the sum of the ones reported by every node. The given
+// groups are not modified, as they may be shared with other jobs.
+func AddGroups(groups definition.RawGroups) definition.RawGroups {
+	withRollups := make(definition.RawGroups, len(groups)+len(entityTypes))
+	for groupLabel, entities := range groups {
+		withRollups[groupLabel] = entities
+	}
+	for groupLabel := range entityTypes {
+		withRollups[groupLabel] = make(map[string]definition.RawMetrics)
+	}
+
+	pods := make(map[string]map[string]bool)
+	for _, container := range groups["container"] {
+		// Init containers run before the regular ones, so their requests don't add up to them, and ephemeral
+		// containers have no resources.
+		if containerType, ok := container["containerType"].(string); ok && containerType != "regular" {
+			continue
+		}
+
+		ns, ok := container["namespace"].(string)
+		if !ok || ns == "" {
+			continue
+		}
+
+		add(withRollups, NamespaceGroup, ns, container, pods, definition.RawMetrics{
+			"namespace": ns,
+		})
+
+		// The deployment name is also set when the owner of the ReplicaSet of the pod can't be resolved.
+		groupLabel, name := DeploymentGroup, ""
+		if deployment, ok := container[owner.DeploymentNameKey].(string); ok && deployment != "" {
+			name = deployment
+		} else {
+			kind, _ := container[owner.WorkloadKindKey].(string)
+			groupLabel = workloadGroups[kind]
+			name, _ = container[owner.WorkloadNameKey].(string)
+		}
+		if groupLabel == "" || name == "" {
+			continue
+		}
+		add(withRollups, groupLabel, fmt.Sprintf("%s_%s", ns, name), container, pods, definition.RawMetrics{
+			"namespace":    ns,
+			"workloadName": name,
+		})
+	}
+
+	return withRollups
+}
+
+// add sums the metrics of the container to the rollup of the given group and
+// raw entity ID, creating it with the given metrics if it doesn't exist. The
+// pods already counted are tracked in pods, by rollup.
+func add(
+	groups definition.RawGroups,
+	groupLabel string,
+	rawEntityID string,
+	container definition.RawMetrics,
+	pods map[string]map[string]bool,
+	metrics definition.RawMetrics,
+) {
+	rollup, ok := groups[groupLabel][rawEntityID]
+	if !ok {
+		rollup = metrics
+		rollup["containerCount"] = 0
+		rollup["podCount"] = 0
+		if nodeName, ok := container["nodeName"].(string); ok {
+			rollup["nodeName"] = nodeName
+		}
+		groups[groupLabel][rawEntityID] = rollup
+	}
+
+	rollup["containerCount"] = rollup["containerCount"].(int) + 1
+
+	key := fmt.Sprintf("%s/%s", groupLabel, rawEntityID)
+	if pods[key] == nil {
+		pods[key] = make(map[string]bool)
+	}
+	if podName, ok := container["podName"].(string); ok && !pods[key][podName] {
+		pods[key][podName] = true
+		rollup["podCount"] = rollup["podCount"].(int) + 1
+	}
+
+	for _, name := range uintSums {
+		if v, ok := container[name].(uint64); ok {
+			sum, _ := rollup[name].(uint64)
+			rollup[name] = sum + v
+		}
+	}
+	for _, name := range intSums {
+		if v, ok := container[name].(int64); ok {
+			sum, _ := rollup[name].(int64)
+			rollup[name] = sum + v
+		}
+	}
+}
+
+// EntityTypeGenerator generates the type of the entity of a rollup, which is
+// the one of the KSM samples of the same object, e.g.
+// k8s:<clusterName>:<namespace>:deployment.
+func EntityTypeGenerator(groupLabel string, rawEntityID string, groups definition.RawGroups, clusterName string) (string, error) {
+	entityType, ok := entityTypes[groupLabel]
+	if !ok {
+		return "", fmt.Errorf("unknown rollup group %q", groupLabel)
+	}
+	if groupLabel == NamespaceGroup {
+		return fmt.Sprintf("k8s:%s:%s", clusterName, entityType), nil
+	}
+
+	ns, ok := groups[groupLabel][rawEntityID]["namespace"].(string)
+	if !ok || ns == "" {
+		return "", fmt.Errorf("empty namespace for generated entity type for %q", groupLabel)
+	}
+	return fmt.Sprintf("k8s:%s:%s:%s", clusterName, ns, entityType), nil
+}
@@ -0,0 +1,148 @@
+package rollup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/nri-kubernetes/src/definition"
+)
+
+func container(ns, pod string, workload map[string]string, usage uint64, requests int64) definition.RawMetrics {
+	c := definition.RawMetrics{
+		"namespace":            ns,
+		"podName":              pod,
+		"nodeName":             "worker-1",
+		"usageNanoCores":       usage,
+		"workingSetBytes":      usage * 1000,
+		"cpuRequestedCores":    requests,
+		"memoryRequestedBytes": requests * 1000,
+	}
+	for k, v := range workload {
+		c[k] = v
+	}
+	return c
+}
+
+func TestAddGroups(t *testing.T) {
+	nginx := map[string]string{"deploymentName": "nginx", "workloadKind": "Deployment", "workloadName": "nginx"}
+	redis := map[string]string{"workloadKind": "StatefulSet", "workloadName": "redis"}
+	agent := map[string]string{"workloadKind": "DaemonSet", "workloadName": "agent"}
+	backup := map[string]string{"workloadKind": "CronJob", "workloadName": "backup"}
+
+	groups := definition.RawGroups{
+		"container": {
+			"default_nginx-a_nginx":   container("default", "nginx-a", nginx, 100, 250),
+			"default_nginx-a_sidecar": container("default", "nginx-a", nginx, 10, 50),
+			"default_nginx-b_nginx":   container("default", "nginx-b", nginx, 200, 250),
+			"default_redis-0_redis":   container("default", "redis-0", redis, 300, 500),
+			"system_agent-x_agent":    container("system", "agent-x", agent, 5, 0),
+			"default_backup-1_backup": container("default", "backup-1", backup, 1, 1),
+			"default_static_static":   container("default", "static", nil, 2, 2),
+		},
+		"pod": {
+			"default_nginx-a": {"namespace": "default", "podName": "nginx-a"},
+		},
+	}
+
+	rollups := AddGroups(groups)
+
+	assert.Equal(t, definition.RawMetrics{
+		"namespace":            "default",
+		"workloadName":         "nginx",
+		"nodeName":             "worker-1",
+		"containerCount":       3,
+		"podCount":             2,
+		"usageNanoCores":       uint64(310),
+		"workingSetBytes":      uint64(310000),
+		"cpuRequestedCores":    int64(550),
+		"memoryRequestedBytes": int64(550000),
+	}, rollups[DeploymentGroup]["default_nginx"])
+	assert.Len(t, rollups[DeploymentGroup], 1)
+
+	assert.Equal(t, uint64(300), rollups[StatefulSetGroup]["default_redis"]["usageNanoCores"])
+	assert.Equal(t, 1, rollups[StatefulSetGroup]["default_redis"]["podCount"])
+	assert.Equal(t, uint64(5), rollups[DaemonSetGroup]["system_agent"]["usageNanoCores"])
+
+	assert.Equal(t, 6, rollups[NamespaceGroup]["default"]["containerCount"])
+	assert.Equal(t, 5, rollups[NamespaceGroup]["default"]["podCount"])
+	assert.Equal(t, uint64(613), rollups[NamespaceGroup]["default"]["usageNanoCores"])
+	assert.Equal(t, int64(1053), rollups[NamespaceGroup]["default"]["cpuRequestedCores"])
+	assert.NotContains(t, rollups[NamespaceGroup]["default"], "cpuLimitCores")
+	assert.Equal(t, 1, rollups[NamespaceGroup]["system"]["containerCount"])
+
+	// The given groups are kept and left untouched.
+	assert.Equal(t, groups["pod"], rollups["pod"])
+	assert.Equal(t, groups["container"], rollups["container"])
+	assert.NotContains(t, groups, DeploymentGroup)
+}
+
+func TestAddGroups_SkipsInitAndEphemeralContainers(t *testing.T) {
+	nginx := map[string]string{"deploymentName": "nginx", "workloadKind": "Deployment", "workloadName": "nginx"}
+
+	regular := container("default", "nginx-a", nginx, 100, 250)
+	regular["containerType"] = "regular"
+	// Completed init containers have no usage.
+	migrate := container("default", "nginx-a", nginx, 0, 500)
+	migrate["containerType"] = "init"
+	delete(migrate, "usageNanoCores")
+	delete(migrate, "workingSetBytes")
+	debug := container("default", "nginx-a", nginx, 1, 0)
+	debug["containerType"] = "ephemeral"
+
+	rollups := AddGroups(definition.RawGroups{
+		"container": {
+			"default_nginx-a_nginx":   regular,
+			"default_nginx-a_migrate": migrate,
+			"default_nginx-a_debug":   debug,
+		},
+	})
+
+	expected := definition.RawMetrics{
+		"namespace":            "default",
+		"workloadName":         "nginx",
+		"nodeName":             "worker-1",
+		"containerCount":       1,
+		"podCount":             1,
+		"usageNanoCores":       uint64(100),
+		"workingSetBytes":      uint64(100000),
+		"cpuRequestedCores":    int64(250),
+		"memoryRequestedBytes": int64(250000),
+	}
+	assert.Equal(t, expected, rollups[DeploymentGroup]["default_nginx"])
+	assert.Equal(t, 1, rollups[NamespaceGroup]["default"]["containerCount"])
+	assert.Equal(t, int64(250), rollups[NamespaceGroup]["default"]["cpuRequestedCores"])
+}
+
+func TestAddGroups_GuessedDeployment(t *testing.T) {
+	// The owner of the ReplicaSet couldn't be resolved, so only the deployment name is guessed.
+	rs := map[string]string{"deploymentName": "nginx", "workloadKind": "ReplicaSet", "workloadName": "nginx-5c689d88bb"}
+	groups := definition.RawGroups{
+		"container": {
+			"default_nginx-5c689d88bb-x2tqp_nginx": container("default", "nginx-5c689d88bb-x2tqp", rs, 100, 250),
+		},
+	}
+
+	rollups := AddGroups(groups)
+
+	assert.Contains(t, rollups[DeploymentGroup], "default_nginx")
+	assert.Equal(t, "nginx", rollups[DeploymentGroup]["default_nginx"]["workloadName"])
+}
+
+func TestEntityTypeGenerator(t *testing.T) {
+	groups := definition.RawGroups{
+		DeploymentGroup: {"default_nginx": {"namespace": "default", "workloadName": "nginx"}},
+		NamespaceGroup:  {"default": {"namespace": "default"}},
+	}
+
+	entityType, err := EntityTypeGenerator(DeploymentGroup, "default_nginx", groups, "cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, "k8s:cluster:default:deployment", entityType)
+
+	entityType, err = EntityTypeGenerator(NamespaceGroup, "default", groups, "cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, "k8s:cluster:namespace", entityType)
+
+	_, err = EntityTypeGenerator(StatefulSetGroup, "default_redis", groups, "cluster")
+	assert.Error(t, err)
+}
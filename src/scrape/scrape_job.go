@@ -2,27 +2,115 @@ package scrape
 
 import (
 	"github.com/newrelic/infra-integrations-sdk/sdk"
+	"github.com/newrelic/nri-kubernetes/src/annotation"
 	"github.com/newrelic/nri-kubernetes/src/data"
 	"github.com/newrelic/nri-kubernetes/src/definition"
 	"github.com/newrelic/nri-kubernetes/src/metric"
+	"github.com/newrelic/nri-kubernetes/src/namespace"
+	"github.com/newrelic/nri-kubernetes/src/owner"
+	"github.com/newrelic/nri-kubernetes/src/redact"
+	"github.com/newrelic/nri-kubernetes/src/rollup"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/version"
 )
 
+// JobOption configures a Scrape Job
+type JobOption func(*Job)
+
+// WithDimensionalMetrics configures the Scrape Job to also report labelled
+// metrics in one metric set per combination of labels.
+func WithDimensionalMetrics(enabled bool) JobOption {
+	return func(job *Job) {
+		job.DimensionalMetrics = enabled
+	}
+}
+
+// WithCounterSampler configures the Scrape Job to compute the DELTA and RATE
+// metrics with the given sampler, instead of the SDK.
+func WithCounterSampler(sampler definition.CounterSampler) JobOption {
+	return func(job *Job) {
+		job.CounterSampler = sampler
+	}
+}
+
+// WithNamespaceFilter configures the Scrape Job to drop the raw entities of
+// the namespaces that the given filter doesn't match before populating them.
+func WithNamespaceFilter(filter *namespace.Filter) JobOption {
+	return func(job *Job) {
+		job.NamespaceFilter = filter
+	}
+}
+
+// WithAnnotationAllowlist configures the Scrape Job to report the annotations
+// allowed by the given allowlist. By default no annotation is reported.
+func WithAnnotationAllowlist(allowlist annotation.Allowlist) JobOption {
+	return func(job *Job) {
+		job.Annotations = allowlist
+	}
+}
+
+// WithRedactor configures the Scrape Job to redact the attributes of the
+// metric sets it populates with the given redactor.
+func WithRedactor(redactor *redact.Redactor) JobOption {
+	return func(job *Job) {
+		job.Redactor = redactor
+	}
+}
+
+// WithAttributeLimits configures the Scrape Job to bound the metrics populated
+// from the FetchedValues returned by the specs, like label.*, with the given
+// limits.
+func WithAttributeLimits(limits *definition.AttributeLimits) JobOption {
+	return func(job *Job) {
+		job.AttributeLimits = limits
+	}
+}
+
+// WithOwnerResolver configures the Scrape Job to resolve the workloads of the
+// pods and containers with the given resolver.
+func WithOwnerResolver(resolver *owner.Resolver) JobOption {
+	return func(job *Job) {
+		job.Owners = resolver
+	}
+}
+
+// WithRollups configures the Scrape Job to sum the resources of the
+// containers per workload and namespace, and to populate them with the given
+// specs.
+func WithRollups(specs definition.SpecGroups) JobOption {
+	return func(job *Job) {
+		job.RollupSpecs = specs
+	}
+}
+
 // NewScrapeJob creates a new Scrape Job with the given attributes
-func NewScrapeJob(name string, grouper data.Grouper, specs definition.SpecGroups) *Job {
-	return &Job{
+func NewScrapeJob(name string, grouper data.Grouper, specs definition.SpecGroups, options ...JobOption) *Job {
+	job := &Job{
 		Name:    name,
 		Grouper: grouper,
 		Specs:   specs,
 	}
+
+	for _, option := range options {
+		option(job)
+	}
+
+	return job
 }
 
 // Job hold all information specific to a certain Scrape Job, e.g.: where do I get the data from, and what data
 type Job struct {
-	Name    string
-	Grouper data.Grouper
-	Specs   definition.SpecGroups
+	Name               string
+	Grouper            data.Grouper
+	Specs              definition.SpecGroups
+	DimensionalMetrics bool
+	CounterSampler     definition.CounterSampler
+	NamespaceFilter    *namespace.Filter
+	Annotations        annotation.Allowlist
+	Redactor           *redact.Redactor
+	AttributeLimits    *definition.AttributeLimits
+	Owners             *owner.Resolver
+	RollupSpecs        definition.SpecGroups
 }
 
 // Populate will get the data using the given Group, transform it, and push it to the given Integration
@@ -44,5 +132,58 @@ func (s *Job) Populate(
 		logger.Warnf("%s", errs)
 	}
 
-	return metric.NewK8sPopulator().Populate(groups, s.Specs, integration, clusterName, k8sVersion)
+	if s.NamespaceFilter != nil {
+		var removed int
+		groups, removed = s.NamespaceFilter.FilterGroups(groups)
+		logger.Debugf("Job %s: filtered out %d entities of excluded namespaces", s.Name, removed)
+	}
+
+	groups = s.Annotations.FilterGroups(groups)
+
+	if s.Owners != nil {
+		groups = s.Owners.ResolveGroups(groups)
+	}
+
+	specs := s.Specs
+	if len(s.RollupSpecs) > 0 {
+		groups = rollup.AddGroups(groups)
+
+		specs = make(definition.SpecGroups, len(s.Specs)+len(s.RollupSpecs))
+		for groupLabel, group := range s.Specs {
+			specs[groupLabel] = group
+		}
+		for groupLabel, group := range s.RollupSpecs {
+			specs[groupLabel] = group
+		}
+	}
+
+	var options []metric.K8sPopulatorOption
+	if s.DimensionalMetrics {
+		options = append(options, metric.WithDimensionalMetricSets())
+	}
+	if s.CounterSampler != nil {
+		options = append(options, metric.WithCounterSampler(s.CounterSampler))
+	}
+	if s.Redactor != nil {
+		options = append(options, metric.WithRedactor(s.Redactor))
+	}
+	if s.AttributeLimits != nil {
+		options = append(options, metric.WithAttributeLimits(s.AttributeLimits))
+	}
+
+	result := metric.NewK8sPopulator(options...).Populate(groups, specs, integration, clusterName, k8sVersion)
+
+	if s.Redactor != nil {
+		if counts := s.Redactor.Flush(); counts.Total() > 0 {
+			logger.Debugf("Job %s: redacted %s", s.Name, counts)
+		}
+	}
+
+	if s.AttributeLimits != nil {
+		for _, hits := range s.AttributeLimits.Flush() {
+			logger.Warnf("Job %s: entity %s hit the attribute limits: %s", s.Name, hits.Entity, hits)
+		}
+	}
+
+	return result
 }